@@ -0,0 +1,471 @@
+// Command goschema-vet reports problems in `schema` struct tags without
+// running any code: malformed quoting, unknown or misapplied keywords,
+// invalid regular expressions, unknown formats and broken dependentRequired
+// rules. It applies the same rules as schema.Lint, to field types rebuilt
+// from source with go/types.
+//
+// Usage:
+//
+//	goschema-vet [-tests] [packages]
+//
+// Packages are directories, optionally ending in "/..." to include their
+// subdirectories; the default is the current directory. Findings are printed
+// as file:line:column: message. The exit status is 1 when there are
+// findings and 2 when packages cannot be loaded.
+//
+// The schema of types that customise it at run time cannot be known
+// statically: fields of types with a MarshalJSON, JSONType, GoSchema or
+// SchemaTag method only get the checks that do not depend on the field type,
+// and types registered with RegisterJSONType are seen by their Go structure.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/twoojoo/goschema/internal/taglint"
+	"github.com/twoojoo/goschema/schema"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is main without the process exit, for tests.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("goschema-vet", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	tests := flags.Bool("tests", false, "also check _test.go files")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: goschema-vet [-tests] [packages]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	dirs, err := expand(patterns)
+	if err != nil {
+		fmt.Fprintln(stderr, "goschema-vet:", err)
+		return 2
+	}
+
+	v := newVetter()
+	for _, dir := range dirs {
+		if err := v.vetDir(dir, *tests); err != nil {
+			fmt.Fprintln(stderr, "goschema-vet:", err)
+			return 2
+		}
+	}
+
+	slices.SortFunc(v.findings, func(a, b finding) int {
+		if c := strings.Compare(a.pos.Filename, b.pos.Filename); c != 0 {
+			return c
+		}
+		if a.pos.Line != b.pos.Line {
+			return a.pos.Line - b.pos.Line
+		}
+		return strings.Compare(a.msg, b.msg)
+	})
+	for _, f := range v.findings {
+		fmt.Fprintf(stdout, "%s: %s\n", f.pos, f.msg)
+	}
+	if len(v.findings) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// expand resolves package patterns to directories.
+func expand(patterns []string) ([]string, error) {
+	var dirs []string
+	for _, p := range patterns {
+		root, recursive := strings.CutSuffix(p, "/...")
+		if !recursive {
+			dirs = append(dirs, p)
+			continue
+		}
+		if root == "" {
+			root = "/"
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			// Skip the directories the go command ignores.
+			name := d.Name()
+			if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, path)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
+
+type finding struct {
+	pos token.Position
+	msg string
+}
+
+type vetter struct {
+	fset     *token.FileSet
+	importer types.Importer
+	findings []finding
+}
+
+func newVetter() *vetter {
+	fset := token.NewFileSet()
+	return &vetter{fset: fset, importer: importer.ForCompiler(fset, "source", nil)}
+}
+
+// vetDir checks the package in dir, and its external test package with
+// tests.
+func (v *vetter) vetDir(dir string, tests bool) error {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		var noGo *build.NoGoError
+		if errors.As(err, &noGo) {
+			return nil
+		}
+		return err
+	}
+
+	files := slices.Concat(pkg.GoFiles, pkg.CgoFiles)
+	if tests {
+		files = append(files, pkg.TestGoFiles...)
+	}
+	if err := v.vetFiles(pkg.ImportPath, dir, files); err != nil {
+		return err
+	}
+	if tests && len(pkg.XTestGoFiles) > 0 {
+		return v.vetFiles(pkg.ImportPath+"_test", dir, pkg.XTestGoFiles)
+	}
+	return nil
+}
+
+// vetFiles type-checks the files of one package and checks their tags.
+func (v *vetter) vetFiles(path, dir string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	var files []*ast.File
+	for _, name := range names {
+		f, err := parser.ParseFile(v.fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+
+	// Type errors are tolerated: whatever types can be resolved are used.
+	conf := types.Config{Importer: v.importer, Error: func(error) {}}
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	_, _ = conf.Check(path, v.fset, files, info)
+
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			// Anonymous structs nested in the type are reported under its name.
+			owner := f.Name.Name + "." + spec.Name.Name
+			ast.Inspect(spec.Type, func(n ast.Node) bool {
+				if st, ok := n.(*ast.StructType); ok {
+					v.vetStruct(owner, st, info)
+				}
+				return true
+			})
+			return false
+		})
+	}
+	return nil
+}
+
+// vetStruct checks the tags of the fields of the struct type named owner.
+func (v *vetter) vetStruct(owner string, st *ast.StructType, info *types.Info) {
+	type field struct {
+		name string
+		f    *ast.Field
+	}
+	var fields []field
+	var jsonNames []string
+	for _, f := range st.Fields.List {
+		names := f.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{embeddedName(f.Type)}
+		}
+		for _, name := range names {
+			if name == nil || (name.Name != "_" && !name.IsExported()) {
+				continue
+			}
+			fields = append(fields, field{name.Name, f})
+			json := tagOf(f).Get("json")
+			if jsonName := jsonFieldName(name.Name, json); name.Name != "_" && jsonName != "-" {
+				jsonNames = append(jsonNames, jsonName)
+			}
+			if len(f.Names) == 0 && !hasJSONName(json) {
+				jsonNames = append(jsonNames, promotedNames(info.TypeOf(f.Type), make(map[types.Type]bool))...)
+			}
+		}
+	}
+
+	for _, fld := range fields {
+		tag, ok := tagOf(fld.f).Lookup("schema")
+		if !ok {
+			continue
+		}
+		var err error
+		if fld.name == "_" {
+			err = taglint.StructTag(tag, jsonNames)
+		} else {
+			if jsonFieldName(fld.name, tagOf(fld.f).Get("json")) == "-" {
+				continue
+			}
+			err = taglint.Tag(reflectType(info.TypeOf(fld.f.Type)), tag)
+		}
+		var errs schema.LintErrors
+		errors.As(err, &errs)
+		pos := v.fset.Position(fld.f.Tag.Pos())
+		for _, e := range errs {
+			e.Struct = owner
+			if fld.name != "_" {
+				e.Field = fld.name
+			}
+			v.findings = append(v.findings, finding{pos, e.Error()})
+		}
+	}
+}
+
+// tagOf returns the struct tag of f.
+func tagOf(f *ast.Field) reflect.StructTag {
+	if f.Tag == nil {
+		return ""
+	}
+	s, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(s)
+}
+
+// jsonFieldName mirrors the runtime: the name in the `json` tag, or else the
+// Go field name.
+func jsonFieldName(goName, tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return goName
+	}
+	return name
+}
+
+// hasJSONName reports whether a `json` tag names its field.
+func hasJSONName(tag string) bool {
+	name, _, _ := strings.Cut(tag, ",")
+	return name != ""
+}
+
+// promotedNames returns the JSON names of the fields that encoding/json
+// promotes from an embedded field of type t, at any depth, like the
+// runtime. seen holds the types being walked.
+func promotedNames(t types.Type, seen map[types.Type]bool) []string {
+	if t == nil {
+		return nil
+	}
+	t = types.Unalias(t)
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = types.Unalias(p.Elem())
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || seen[t] || hasMethod(t, "MarshalJSON") || hasMethod(t, "MarshalText") {
+		return nil
+	}
+	seen[t] = true
+	var names []string
+	for i := range st.NumFields() {
+		f := st.Field(i)
+		json := reflect.StructTag(st.Tag(i)).Get("json")
+		name := jsonFieldName(f.Name(), json)
+		if !f.Exported() || name == "-" {
+			continue
+		}
+		names = append(names, name)
+		if f.Embedded() && !hasJSONName(json) {
+			names = append(names, promotedNames(f.Type(), seen)...)
+		}
+	}
+	return names
+}
+
+// embeddedName returns the field name of an embedded type expression.
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel
+	case *ast.IndexExpr:
+		return embeddedName(e.X)
+	case *ast.IndexListExpr:
+		return embeddedName(e.X)
+	}
+	return nil
+}
+
+var (
+	anyType    = reflect.TypeFor[any]()
+	stringType = reflect.TypeFor[string]()
+
+	basicTypes = map[types.BasicKind]reflect.Type{
+		types.Bool:    reflect.TypeFor[bool](),
+		types.Int:     reflect.TypeFor[int](),
+		types.Int8:    reflect.TypeFor[int8](),
+		types.Int16:   reflect.TypeFor[int16](),
+		types.Int32:   reflect.TypeFor[int32](),
+		types.Int64:   reflect.TypeFor[int64](),
+		types.Uint:    reflect.TypeFor[uint](),
+		types.Uint8:   reflect.TypeFor[uint8](),
+		types.Uint16:  reflect.TypeFor[uint16](),
+		types.Uint32:  reflect.TypeFor[uint32](),
+		types.Uint64:  reflect.TypeFor[uint64](),
+		types.Uintptr: reflect.TypeFor[uintptr](),
+		types.Float32: reflect.TypeFor[float32](),
+		types.Float64: reflect.TypeFor[float64](),
+		types.String:  stringType,
+	}
+)
+
+// reflectType rebuilds a reflect.Type with the same JSON schema as t, or
+// returns nil when the schema depends on code that only runs at run time.
+// Structs are rebuilt with their exported fields and `json` tags, so that
+// defaults can be decoded, but without their `schema` tags, which are
+// checked on their own.
+func reflectType(t types.Type) reflect.Type {
+	return rebuildType(t, make(map[*types.Named]bool))
+}
+
+// rebuildType implements reflectType; building holds the named types being
+// rebuilt, whose recursive uses are described as `any`.
+func rebuildType(t types.Type, building map[*types.Named]bool) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	t = types.Unalias(t)
+	if named, ok := t.(*types.Named); ok {
+		if obj := named.Obj(); obj.Pkg() != nil && obj.Pkg().Path() == "time" {
+			switch obj.Name() {
+			case "Time":
+				return reflect.TypeFor[time.Time]()
+			case "Duration":
+				return reflect.TypeFor[time.Duration]()
+			}
+		}
+		switch {
+		case hasMethod(t, "JSONType"), hasMethod(t, "GoSchema"), hasMethod(t, "SchemaTag"), hasMethod(t, "MarshalJSON"):
+			return nil
+		case hasMethod(t, "MarshalText"):
+			return stringType
+		}
+		if building[named] {
+			return anyType
+		}
+		building[named] = true
+		defer delete(building, named)
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		if rt, ok := basicTypes[u.Kind()]; ok {
+			return rt
+		}
+	case *types.Pointer:
+		if elem := rebuildType(u.Elem(), building); elem != nil {
+			return reflect.PointerTo(elem)
+		}
+	case *types.Slice:
+		if elem := rebuildType(u.Elem(), building); elem != nil {
+			return reflect.SliceOf(elem)
+		}
+	case *types.Array:
+		if elem := rebuildType(u.Elem(), building); elem != nil {
+			return reflect.ArrayOf(int(u.Len()), elem)
+		}
+	case *types.Map:
+		key, elem := rebuildType(u.Key(), building), rebuildType(u.Elem(), building)
+		if key == nil || !key.Comparable() {
+			key = stringType
+		}
+		if elem != nil {
+			return reflect.MapOf(key, elem)
+		}
+	case *types.Struct:
+		var fields []reflect.StructField
+		for i := range u.NumFields() {
+			f := u.Field(i)
+			// reflect.StructOf can't embed unexported types.
+			if !f.Exported() {
+				continue
+			}
+			ft := rebuildType(f.Type(), building)
+			if ft == nil {
+				ft = anyType
+			}
+			var tag reflect.StructTag
+			if json, ok := reflect.StructTag(u.Tag(i)).Lookup("json"); ok {
+				tag = reflect.StructTag("json:" + strconv.Quote(json))
+			}
+			// Embedded structs are kept embedded, so that encoding/json
+			// promotes their fields as in the original type.
+			embedded := f.Embedded() && embeddable(ft)
+			fields = append(fields, reflect.StructField{Name: f.Name(), Type: ft, Tag: tag, Anonymous: embedded})
+		}
+		return reflect.StructOf(fields)
+	case *types.Interface:
+		return anyType
+	}
+	return nil
+}
+
+// embeddable reports whether reflect.StructOf can embed t: a struct, or a
+// pointer to one, without methods.
+func embeddable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && reflect.PointerTo(t).NumMethod() == 0
+}
+
+// hasMethod reports whether t or *t has the named method.
+func hasMethod(t types.Type, name string) bool {
+	return types.NewMethodSet(types.NewPointer(t)).Lookup(nil, name) != nil
+}
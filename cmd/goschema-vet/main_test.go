@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVet(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"./testdata/bad"}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("expected exit status 1, got %d (stderr: %s)", code, stderr.String())
+	}
+
+	want := []string{
+		`testdata/bad/bad.go:10:24: bad.Order: dependentRequired:coupon: unknown target field "discount"`,
+		`testdata/bad/bad.go:11:24: bad.Order.ID: minLenght: unknown keyword (did you mean "minLength"?)`,
+		`testdata/bad/bad.go:12:24: bad.Order.Qty: minLength: does not apply to integer fields (Go type int)`,
+		`testdata/bad/bad.go:13:24: bad.Order.Code: malformed tag "pattern=^[a-z]{2,5}$": invalid key "5}$"`,
+		`testdata/bad/bad.go:22:15: bad.Item.SKU: format: unknown format "sku"`,
+		`testdata/bad/bad.go:23:15: bad.Item.Price: default abc does not fit type int`,
+		`testdata/bad/bad.go:25:15: bad.Item.Note: maxLength must be an integer`,
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d findings, got %d:\n%s", len(want), len(lines), stdout.String())
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(lines[i], w) {
+			t.Errorf("finding %d:\n got %s\nwant %s", i, lines[i], w)
+		}
+	}
+}
+
+func TestVet_Clean(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"../../examples"}, &stdout, &stderr); code != 0 {
+		t.Errorf("expected exit status 0, got %d:\n%s%s", code, stdout.String(), stderr.String())
+	}
+}
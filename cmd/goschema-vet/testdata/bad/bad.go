@@ -0,0 +1,39 @@
+package bad
+
+import "time"
+
+type Level string
+
+func (Level) JSONType() string { return "integer" }
+
+type Order struct {
+	_       any           `schema:"dependentRequired:coupon=discount"`
+	ID      string        `json:"id"      schema:"minLenght=3"`
+	Qty     int           `json:"qty"     schema:"minLength=1"`
+	Code    string        `json:"code"    schema:"pattern=^[a-z]{2,5}$"`
+	Quoted  string        `json:"quoted"  schema:"pattern='^[a-z]{2,5}$'"`
+	Timeout time.Duration `json:"timeout" schema:"minimum=1s,maximum=1m"`
+	Level   Level         `json:"level"   schema:"maximum=5"`
+	Items   []Item        `json:"items"   schema:"minItems=1"`
+	Coupon  string        `json:"coupon"`
+}
+
+type Item struct {
+	SKU   string `json:"sku"   schema:"format=sku"`
+	Price int    `json:"price" schema:"default=abc"`
+	Meta  struct {
+		Note string `json:"note" schema:"maxLength=x"`
+	} `json:"meta"`
+}
+
+type Audit struct {
+	CreatedBy string `json:"createdBy"`
+	Reviewer  string `json:"reviewer"`
+}
+
+// Change is clean: the fields of Audit are promoted into it.
+type Change struct {
+	_ any `schema:"dependentRequired:reviewer=createdBy"`
+	Audit
+	Prev *Change `json:"prev" schema:"default={\"reviewer\":\"ann\"}"`
+}
@@ -0,0 +1,1154 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/format"
+	"go/types"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+// generator writes the validators and default appliers of a set of struct
+// types of one package. The code mirrors validateField and
+// applyFieldDefaults of the schema package branch by branch, with the
+// constraints of the resolved schemas folded into constants.
+type generator struct {
+	pkg      *types.Package
+	prefix   string            // prefix of package-level names
+	imports  map[string]string // import path -> package name
+	patterns []string          // regular expressions, compiled once
+	structs  []*structType     // in order of discovery
+	byObj    map[*types.TypeName]*structType
+	errs     []string
+	tmp      int
+}
+
+// structType is a struct type that gets generated methods.
+type structType struct {
+	obj      *types.TypeName
+	st       *types.Struct
+	schema   *schema.ObjectSchema
+	defaults *string // memoised body of ApplySchemaDefaults
+	busy     bool    // the defaults body is being computed
+}
+
+func newGenerator(pkg *types.Package, prefix string) *generator {
+	return &generator{
+		pkg:     pkg,
+		prefix:  prefix,
+		imports: map[string]string{"github.com/twoojoo/goschema/schema": "schema"},
+		byObj:   make(map[*types.TypeName]*structType),
+	}
+}
+
+func (g *generator) failf(where, format string, args ...any) {
+	g.errs = append(g.errs, where+": "+fmt.Sprintf(format, args...))
+}
+
+// use records an import of a standard package and returns its name.
+func (g *generator) use(path string) string {
+	name := path[strings.LastIndex(path, "/")+1:]
+	g.imports[path] = name
+	return name
+}
+
+// typeString returns the Go expression of t in the generated file.
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p == g.pkg {
+			return ""
+		}
+		g.imports[p.Path()] = p.Name()
+		return p.Name()
+	})
+}
+
+// name returns a fresh name for a temporary variable.
+func (g *generator) name(base string) string {
+	g.tmp++
+	return base + strconv.Itoa(g.tmp)
+}
+
+// addRoot adds a type named with -type.
+func (g *generator) addRoot(obj *types.TypeName, fs schema.FieldSchema) {
+	where := obj.Name()
+	if fs.Nested == nil {
+		g.failf(where, "not a struct type")
+		return
+	}
+	if !g.supported(fs, where) {
+		return
+	}
+	if fs.Default != nil {
+		g.failf(where, "defaults on the type itself are not supported")
+		return
+	}
+	g.structOf(obj.Type(), fs.Nested, where)
+}
+
+// structOf returns the generated struct type t, adding it on first use.
+func (g *generator) structOf(t types.Type, obj *schema.ObjectSchema, where string) *structType {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		g.failf(where, "anonymous struct types are not supported")
+		return nil
+	}
+	if s, ok := g.byObj[named.Obj()]; ok {
+		return s
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	switch {
+	case !ok:
+		g.failf(where, "%s is not a struct type", named)
+		return nil
+	case named.Obj().Pkg() != g.pkg:
+		g.failf(where, "struct type %s is declared in another package", named)
+		return nil
+	case named.TypeParams().Len() > 0 || named.TypeArgs().Len() > 0:
+		g.failf(where, "generic type %s is not supported", named)
+		return nil
+	}
+	s := &structType{obj: named.Obj(), st: st, schema: obj}
+	g.byObj[named.Obj()] = s
+	g.structs = append(g.structs, s)
+	return s
+}
+
+// supported reports whether the keywords of fs can be checked by generated
+// code.
+func (g *generator) supported(fs schema.FieldSchema, where string) bool {
+	switch {
+	case fs.Not != nil || len(fs.AnyOf) > 0 || len(fs.OneOf) > 0 || len(fs.AllOf) > 0:
+		g.failf(where, "composition keywords (not, anyOf, oneOf, allOf) are not supported")
+	case fs.Enum != nil || fs.Const != nil:
+		g.failf(where, "JSON-literal enum and const are not supported")
+	case fs.Time != nil && (fs.Time.After != nil || fs.Time.Before != nil || fs.Time.MinAge != nil || fs.Time.MaxAge != nil):
+		g.failf(where, "temporal constraints (after, before, minAge, maxAge) are not supported")
+	default:
+		return true
+	}
+	return false
+}
+
+// source returns the formatted generated file.
+func (g *generator) source(command string) ([]byte, error) {
+	var body strings.Builder
+	for i := 0; i < len(g.structs); i++ {
+		g.writeMethods(&body, g.structs[i])
+	}
+	if len(g.errs) > 0 {
+		return nil, errors.New(strings.Join(g.errs, "\n"))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by %q; DO NOT EDIT.\n\n", command)
+	fmt.Fprintf(&b, "//go:build !%s\n\n", genTag)
+	fmt.Fprintf(&b, "package %s\n\n", g.pkg.Name())
+	// Standard packages first, as goimports does.
+	b.WriteString("import (\n")
+	paths := sortedKeys(g.imports)
+	slices.SortStableFunc(paths, func(a, b string) int {
+		return cmpBool(isStd(a), isStd(b))
+	})
+	for i, path := range paths {
+		if i > 0 && isStd(path) != isStd(paths[i-1]) {
+			b.WriteString("\n")
+		}
+		if name := g.imports[path]; name != path[strings.LastIndex(path, "/")+1:] {
+			fmt.Fprintf(&b, "%s %q\n", name, path)
+		} else {
+			fmt.Fprintf(&b, "%q\n", path)
+		}
+	}
+	b.WriteString(")\n\n")
+	if len(g.patterns) > 0 {
+		fmt.Fprintf(&b, "var %sSchemaPatterns = [...]*regexp.Regexp{\n", g.prefix)
+		for _, p := range g.patterns {
+			fmt.Fprintf(&b, "regexp.MustCompile(%s),\n", quote(p))
+		}
+		b.WriteString("}\n\n")
+	}
+	b.WriteString(body.String())
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v", err)
+	}
+	return src, nil
+}
+
+// writeMethods writes the methods of s.
+func (g *generator) writeMethods(w *strings.Builder, s *structType) {
+	g.tmp = 0
+	name := s.obj.Name()
+	recv := strings.ToLower(name[:1])
+
+	var fields strings.Builder
+	for i := range s.st.NumFields() {
+		f := s.st.Field(i)
+		fs, ok := g.schemaField(s, i)
+		if !ok {
+			continue
+		}
+		p := "prefix + " + strconv.Quote(fs.JSONName)
+		g.validate(&fields, recv+"."+f.Name(), f.Type(), fs, p, name+"."+f.Name())
+	}
+
+	fmt.Fprintf(w, "// ValidateSchema validates %s against its `schema` tags, like\n", recv)
+	fmt.Fprintf(w, "// schema.Validate but without reflection.\n")
+	fmt.Fprintf(w, "func (%s *%s) ValidateSchema() error {\n", recv, name)
+	fmt.Fprintf(w, "if errs := %s.validateSchema(\"\", nil); len(errs) > 0 {\nreturn errs\n}\nreturn nil\n}\n\n", recv)
+
+	fmt.Fprintf(w, "func (%s *%s) validateSchema(path string, errs schema.ValidationErrors) schema.ValidationErrors {\n", recv, name)
+	if fields.Len() > 0 {
+		w.WriteString("prefix := \"\"\nif path != \"\" {\nprefix = path + \".\"\n}\n")
+	}
+	g.dependentRequired(w, s, recv)
+	w.WriteString(fields.String())
+	w.WriteString("return errs\n}\n\n")
+
+	fmt.Fprintf(w, "// ApplySchemaDefaults sets the zero-valued fields of %s that have a\n", recv)
+	fmt.Fprintf(w, "// `default=` to their default, like schema.ParseJSON.\n")
+	fmt.Fprintf(w, "func (%s *%s) ApplySchemaDefaults() {\n%s}\n\n", recv, name, g.defaultsBody(s))
+}
+
+// schemaField returns the schema of the i-th field of s, if the reflective
+// engine validates it.
+func (g *generator) schemaField(s *structType, i int) (schema.FieldSchema, bool) {
+	f := s.st.Field(i)
+	if !f.Exported() {
+		return schema.FieldSchema{}, false
+	}
+	name := jsonFieldName(f.Name(), reflect.StructTag(s.st.Tag(i)).Get("json"))
+	if name == "-" {
+		return schema.FieldSchema{}, false
+	}
+	fs, ok := s.schema.Fields[name]
+	fs.JSONName = name
+	return fs, ok
+}
+
+// jsonFieldName mirrors the JSON name rules of the schema package.
+func jsonFieldName(name, tag string) string {
+	if n, _, _ := strings.Cut(tag, ","); n != "" {
+		return n
+	}
+	return name
+}
+
+// dependentRequired writes the dependentRequired checks of s, with the
+// presence rules of isPresent.
+func (g *generator) dependentRequired(w *strings.Builder, s *structType, recv string) {
+	present := func(jsonName string) string {
+		return g.present(recv, s.st, jsonName, s.obj.Name(), map[types.Type]bool{})
+	}
+	for _, source := range sortedKeys(s.schema.DependentRequired) {
+		var deps strings.Builder
+		for _, dep := range s.schema.DependentRequired[source] {
+			msg := fmt.Sprintf("field %q is required because %q is present", dep, source)
+			var fail strings.Builder
+			g.appendErr(&fail, "path", "dependentRequired", strconv.Quote(msg), "nil")
+			writeIf(&deps, negate(present(dep)), fail.String())
+		}
+		writeIf(w, present(source), deps.String())
+	}
+}
+
+// present returns the condition under which isPresent finds the field
+// named jsonName in x, a struct st: one of its own, or else one promoted
+// from the structs it embeds. seen holds the embedded types being walked.
+func (g *generator) present(x string, st *types.Struct, jsonName, where string, seen map[types.Type]bool) string {
+	for i := range st.NumFields() {
+		f := st.Field(i)
+		if jsonFieldName(f.Name(), reflect.StructTag(st.Tag(i)).Get("json")) != jsonName {
+			continue
+		}
+		if f.Name() == "_" {
+			return "false"
+		}
+		return g.nonZero(x+"."+f.Name(), f.Type(), where+"."+f.Name())
+	}
+	var conds []string
+	for i := range st.NumFields() {
+		f := st.Field(i)
+		t, ptr := f.Type(), false
+		if p, ok := t.Underlying().(*types.Pointer); ok {
+			t, ptr = p.Elem(), true
+		}
+		inner, ok := t.Underlying().(*types.Struct)
+		name, _, _ := strings.Cut(reflect.StructTag(st.Tag(i)).Get("json"), ",")
+		if !ok || !f.Embedded() || !f.Exported() || name != "" || seen[t] ||
+			hasMethod(t, "MarshalJSON") || hasMethod(t, "MarshalText") {
+			continue
+		}
+		seen[t] = true
+		cond := g.present(x+"."+f.Name(), inner, jsonName, where+"."+f.Name(), seen)
+		delete(seen, t)
+		switch {
+		case cond == "false":
+			continue
+		case ptr:
+			cond = fmt.Sprintf("(%s.%s != nil && %s)", x, f.Name(), cond)
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 0 {
+		return "false"
+	}
+	return strings.Join(conds, " || ")
+}
+
+// ---- validation ----
+
+// validate writes the checks of x, a value of type t, against fs, like
+// validateField. p is the Go expression of the error path.
+func (g *generator) validate(w *strings.Builder, x string, t types.Type, fs schema.FieldSchema, p, where string) {
+	if !g.supported(fs, where) {
+		return
+	}
+	ptr, ok := t.Underlying().(*types.Pointer)
+	if !ok {
+		g.value(w, x, t, fs, p, where)
+		return
+	}
+	if _, ok := ptr.Elem().Underlying().(*types.Pointer); ok {
+		g.failf(where, "pointers to pointers are not supported")
+		return
+	}
+	var onNil, inner strings.Builder
+	if fs.Required && !fs.Nullable {
+		g.appendErr(&onNil, p, "required", `"field is required"`, "nil")
+	}
+	g.value(&inner, "(*"+x+")", ptr.Elem(), fs, p, where)
+	switch {
+	case onNil.Len() > 0 && inner.Len() > 0:
+		fmt.Fprintf(w, "if %s == nil {\n%s} else {\n%s}\n", x, onNil.String(), inner.String())
+	case onNil.Len() > 0:
+		fmt.Fprintf(w, "if %s == nil {\n%s}\n", x, onNil.String())
+	case inner.Len() > 0:
+		fmt.Fprintf(w, "if %s != nil {\n%s}\n", x, inner.String())
+	}
+}
+
+// value writes the checks of the non-pointer value x.
+func (g *generator) value(w *strings.Builder, x string, t types.Type, fs schema.FieldSchema, p, where string) {
+	if isTime(t) {
+		if fs.Type != "string" {
+			g.failf(where, "time values in sub-schemas are not supported")
+			return
+		}
+		g.timeValue(w, x, t, fs, p)
+		return
+	}
+	if hasMethod(t, "MarshalJSON") || hasMethod(t, "MarshalText") {
+		g.failf(where, "type %s has a custom marshaller", t)
+		return
+	}
+
+	mismatch := func() {
+		g.failf(where, "schema type %q does not match Go type %s", fs.Type, t)
+	}
+	// Sub-schemas of `items:` rules have the type "any" and are applied by
+	// the kind of the value.
+	untyped := fs.Type == "any"
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		info := u.Info()
+		switch {
+		case info&types.IsString != 0 && (fs.Type == "string" || untyped):
+			s := x
+			if !types.Identical(t, types.Typ[types.String]) {
+				s = call("string", x)
+			}
+			g.stringChecks(w, s, fs.String, p)
+		case info&types.IsNumeric != 0 && info&types.IsComplex == 0 && (fs.Type == "integer" || fs.Type == "number" || untyped):
+			g.numberChecks(w, x, u, fs.Number, p, where)
+		case info&types.IsBoolean != 0 && (fs.Type == "boolean" || untyped):
+			g.boolChecks(w, x, t, fs.Bool, p)
+		default:
+			mismatch()
+		}
+	case *types.Slice:
+		switch {
+		case untyped:
+		case fs.Type == "array":
+			g.arrayChecks(w, x, u.Elem(), fs.Array, p, where)
+		default:
+			mismatch()
+		}
+	case *types.Array:
+		switch {
+		case untyped:
+		case fs.Type == "array":
+			g.arrayChecks(w, x, u.Elem(), fs.Array, p, where)
+		default:
+			mismatch()
+		}
+	case *types.Map:
+		switch {
+		case untyped:
+		case fs.Type == "object" && fs.Map != nil:
+			g.mapChecks(w, x, u, fs.Map, p, where)
+		default:
+			mismatch()
+		}
+	case *types.Struct:
+		switch {
+		case untyped:
+		case fs.Type == "object" && fs.Nested != nil:
+			if s := g.structOf(t, fs.Nested, where); s != nil {
+				fmt.Fprintf(w, "errs = %s.validateSchema(%s, errs)\n", receiver(x), p)
+			}
+		default:
+			mismatch()
+		}
+	case *types.Interface:
+		switch {
+		case !untyped:
+			mismatch()
+		case fs.String != nil || fs.Number != nil || fs.Bool != nil || fs.Array != nil || fs.Map != nil:
+			g.failf(where, "constraints on interface values are not supported")
+		}
+	default:
+		g.failf(where, "Go type %s is not supported", t)
+	}
+}
+
+// timeValue writes the checks of a time.Time value, which is validated on
+// its JSON form like any type with a custom marshaller.
+func (g *generator) timeValue(w *strings.Builder, x string, t types.Type, fs schema.FieldSchema, p string) {
+	var checks strings.Builder
+	s := g.name("s")
+	g.stringChecks(&checks, s, fs.String, p)
+	if checks.Len() == 0 {
+		return
+	}
+	b := g.name("b")
+	fmt.Fprintf(w, "var %s string\n", s)
+	fmt.Fprintf(w, "if %s != (%s{}) {\n", x, g.typeString(t))
+	fmt.Fprintf(w, "if %s, err := %s.MarshalJSON(); err == nil {\n%s = string(%s[1 : len(%s)-1])\n} else {\n%s = \"<time.Time Value>\"\n}\n}\n", b, x, s, b, b, s)
+	w.WriteString(checks.String())
+}
+
+// stringChecks writes the checks of validateString on the string
+// expression s.
+func (g *generator) stringChecks(w *strings.Builder, s string, c *schema.StringConstraints, p string) {
+	if c == nil {
+		return
+	}
+	v := s
+	if !isSimple(s) {
+		v = g.name("s")
+	}
+
+	var checks strings.Builder
+	if c.MinLength != nil || c.MaxLength != nil {
+		n := g.name("n")
+		fmt.Fprintf(&checks, "%s := %s.RuneCountInString(%s)\n", n, g.use("unicode/utf8"), v)
+		got := " + " + g.use("strconv") + ".Itoa(" + n + ") + \")\""
+		if c.MinLength != nil {
+			var fail strings.Builder
+			g.appendErr(&fail, p, "minLength", strconv.Quote(fmt.Sprintf("must be at least %d characters long (got ", *c.MinLength))+got, v)
+			writeIf(&checks, fmt.Sprintf("%s < %d", n, *c.MinLength), fail.String())
+		}
+		if c.MaxLength != nil {
+			var fail strings.Builder
+			g.appendErr(&fail, p, "maxLength", strconv.Quote(fmt.Sprintf("must be at most %d characters long (got ", *c.MaxLength))+got, v)
+			writeIf(&checks, fmt.Sprintf("%s > %d", n, *c.MaxLength), fail.String())
+		}
+	}
+	if c.Pattern != nil {
+		// Patterns were compiled when the schema was built.
+		var fail strings.Builder
+		g.use("regexp")
+		g.appendErr(&fail, p, "pattern", strconv.Quote(fmt.Sprintf("must match pattern %q", *c.Pattern)), v)
+		writeIf(&checks, fmt.Sprintf("!%sSchemaPatterns[%d].MatchString(%s)", g.prefix, g.pattern(*c.Pattern), v), fail.String())
+	}
+	if c.Format != nil {
+		var fail strings.Builder
+		g.appendErr(&fail, p, "format", strconv.Quote(fmt.Sprintf("must be a valid %s", *c.Format)), v)
+		writeIf(&checks, fmt.Sprintf("!schema.MatchFormat(%q, %s)", *c.Format, v), fail.String())
+	}
+	if len(c.Enum) > 0 {
+		var fail strings.Builder
+		g.appendErr(&fail, p, "enum", strconv.Quote(fmt.Sprintf("must be one of %v", c.Enum)), v)
+		cases := make([]string, len(c.Enum))
+		for i, e := range c.Enum {
+			cases[i] = strconv.Quote(e)
+		}
+		fmt.Fprintf(&checks, "switch %s {\ncase %s:\ndefault:\n%s}\n", v, strings.Join(cases, ", "), fail.String())
+	}
+	if c.Const != nil {
+		var fail strings.Builder
+		g.appendErr(&fail, p, "const", strconv.Quote(fmt.Sprintf("must equal %q", *c.Const)), v)
+		writeIf(&checks, fmt.Sprintf("%s != %q", v, *c.Const), fail.String())
+	}
+
+	var required strings.Builder
+	if c.Required {
+		g.appendErr(&required, p, "required", `"field is required"`, v)
+	}
+	if required.Len() == 0 && checks.Len() == 0 {
+		return
+	}
+	if v != s {
+		fmt.Fprintf(w, "%s := %s\n", v, s)
+	}
+	switch {
+	case required.Len() > 0 && checks.Len() > 0:
+		fmt.Fprintf(w, "if %s == \"\" {\n%s} else {\n%s}\n", v, required.String(), checks.String())
+	case required.Len() > 0:
+		writeIf(w, v+` == ""`, required.String())
+	default:
+		writeIf(w, v+` != ""`, checks.String())
+	}
+}
+
+// pattern returns the index of the compiled form of re.
+func (g *generator) pattern(re string) int {
+	if i := slices.Index(g.patterns, re); i >= 0 {
+		return i
+	}
+	g.patterns = append(g.patterns, re)
+	return len(g.patterns) - 1
+}
+
+// numberChecks writes the checks of validateNumber on x, whose underlying
+// type is u. Comparisons are exact, like numericValue.cmp.
+func (g *generator) numberChecks(w *strings.Builder, x string, u *types.Basic, c *schema.NumberConstraints, p, where string) {
+	if c == nil {
+		return
+	}
+	for _, b := range slices.Concat([]*float64{c.Minimum, c.Maximum, c.ExclusiveMin, c.ExclusiveMax, c.MultipleOf, c.Const}) {
+		if b != nil && (math.IsInf(*b, 0) || math.IsNaN(*b)) {
+			g.failf(where, "non-finite bounds are not supported")
+			return
+		}
+	}
+
+	n := g.name("n")
+	var conv, got string
+	var cond func(op string, f float64) string
+	switch {
+	case u.Info()&types.IsFloat != 0:
+		conv, got = "float64", g.use("strconv")+".FormatFloat("+n+", 'g', -1, 64)"
+		cond = func(op string, f float64) string { return floatCond(n, op, f) }
+	case u.Info()&types.IsUnsigned != 0:
+		conv, got = "uint64", g.use("strconv")+".FormatUint("+n+", 10)"
+		cond = func(op string, f float64) string { return intCond(n, true, op, f) }
+	default:
+		conv, got = "int64", g.use("strconv")+".FormatInt("+n+", 10)"
+		cond = func(op string, f float64) string { return intCond(n, false, op, f) }
+	}
+	got = " + " + got + " + \")\""
+	// Integers are reported as float64 when they fit, like validation does.
+	value := n
+	if conv != "float64" {
+		value = "schema.NumberValue(" + n + ")"
+	}
+
+	var checks strings.Builder
+	check := func(condition, keyword, msg string) {
+		var fail strings.Builder
+		g.appendErr(&fail, p, keyword, msg, value)
+		writeIf(&checks, condition, fail.String())
+	}
+	bound := func(op, keyword string, b *float64, format string) {
+		if b != nil {
+			check(cond(op, *b), keyword, strconv.Quote(fmt.Sprintf(format, *b))+got)
+		}
+	}
+	bound("<", "minimum", c.Minimum, "must be >= %g (got ")
+	bound(">", "maximum", c.Maximum, "must be <= %g (got ")
+	bound("<=", "exclusiveMinimum", c.ExclusiveMin, "must be > %g (got ")
+	bound(">=", "exclusiveMaximum", c.ExclusiveMax, "must be < %g (got ")
+	if m := c.MultipleOf; m != nil && *m != 0 {
+		check(g.multipleCond(n, u, *m), "multipleOf", strconv.Quote(fmt.Sprintf("must be a multiple of %g (got ", *m))+got)
+	}
+	if len(c.Enum) > 0 {
+		var alts []string
+		for _, e := range c.Enum {
+			alts = append(alts, cond("==", e))
+		}
+		check(negate(or(alts)), "enum", strconv.Quote(fmt.Sprintf("must be one of %v", c.Enum)))
+	}
+	if c.Const != nil {
+		check(cond("!=", *c.Const), "const", strconv.Quote(fmt.Sprintf("must equal %g", *c.Const)))
+	}
+	if checks.Len() == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s := %s\n%s", n, call(conv, x), checks.String())
+}
+
+// multipleCond returns the condition of isMultipleOf failing.
+func (g *generator) multipleCond(n string, u *types.Basic, m float64) string {
+	if u.Info()&types.IsFloat == 0 && m == math.Trunc(m) && math.Abs(m) < 1<<63 {
+		d := uint64(math.Abs(m))
+		if d == 1 {
+			return "false"
+		}
+		return fmt.Sprintf("%s%%%d != 0", n, d)
+	}
+	q := g.name("q")
+	math := g.use("math")
+	// Negated rather than > so that NaN fails, as in isMultipleOf.
+	return fmt.Sprintf("%s := float64(%s) / %s; !(%s.Abs(%s-%s.Round(%s)) <= 1e-9)", q, n, floatLit(m), math, q, math, q)
+}
+
+// boolChecks writes the checks of validateBool on x.
+func (g *generator) boolChecks(w *strings.Builder, x string, t types.Type, c *schema.BoolConstraints, p string) {
+	if c == nil {
+		return
+	}
+	b := x
+	if !types.Identical(t, types.Typ[types.Bool]) {
+		b = call("bool", x)
+	}
+	is := func(v bool) string {
+		if v {
+			return b
+		}
+		return "!" + b
+	}
+	if len(c.Enum) > 0 && !(slices.Contains(c.Enum, true) && slices.Contains(c.Enum, false)) {
+		var fail strings.Builder
+		g.appendErr(&fail, p, "enum", strconv.Quote(fmt.Sprintf("must be one of %v", c.Enum)), b)
+		writeIf(w, is(!c.Enum[0]), fail.String())
+	}
+	if c.Const != nil {
+		var fail strings.Builder
+		g.appendErr(&fail, p, "const", strconv.Quote(fmt.Sprintf("must equal %v", *c.Const)), b)
+		writeIf(w, is(!*c.Const), fail.String())
+	}
+}
+
+// arrayChecks writes the checks of validateArray on the slice or array x.
+func (g *generator) arrayChecks(w *strings.Builder, x string, elem types.Type, c *schema.ArrayConstraints, p, where string) {
+	if c == nil {
+		return
+	}
+	var checks strings.Builder
+	length := call("len", x)
+	got := " + " + g.use("strconv") + ".Itoa(" + length + ") + \")\""
+	if c.MinItems != nil {
+		var fail strings.Builder
+		g.appendErr(&fail, p, "minItems", strconv.Quote(fmt.Sprintf("must have at least %d items (got ", *c.MinItems))+got, length)
+		writeIf(&checks, fmt.Sprintf("%s < %d", length, *c.MinItems), fail.String())
+	}
+	if c.MaxItems != nil {
+		var fail strings.Builder
+		g.appendErr(&fail, p, "maxItems", strconv.Quote(fmt.Sprintf("must have at most %d items (got ", *c.MaxItems))+got, length)
+		writeIf(&checks, fmt.Sprintf("%s > %d", length, *c.MaxItems), fail.String())
+	}
+	if c.UniqueItems {
+		if !types.Comparable(elem) {
+			g.failf(where, "uniqueItems on items of non-comparable type %s is not supported", elem)
+			return
+		}
+		seen, item := g.name("seen"), g.name("item")
+		var fail strings.Builder
+		g.appendErr(&fail, p, "uniqueItems", fmt.Sprintf("%s.Sprintf(\"items must be unique (duplicate: %%v)\", %s)", g.use("fmt"), item), item)
+		fmt.Fprintf(&checks, "%s := make(map[%s]struct{}, %s)\n", seen, g.typeString(elem), length)
+		fmt.Fprintf(&checks, "for _, %s := range %s {\nif _, dup := %s[%s]; dup {\n%sbreak\n}\n%s[%s] = struct{}{}\n}\n", item, x, seen, item, fail.String(), seen, item)
+	}
+	if c.Items != nil {
+		i := g.name("i")
+		var items strings.Builder
+		g.validate(&items, x+"["+i+"]", elem, *c.Items, concat(p, `"["`)+" + "+g.use("strconv")+".Itoa("+i+") + \"]\"", where+"[]")
+		if items.Len() > 0 {
+			fmt.Fprintf(&checks, "for %s := range %s {\n%s}\n", i, x, items.String())
+		}
+	}
+
+	if !c.Required {
+		w.WriteString(checks.String())
+		return
+	}
+	var fail strings.Builder
+	g.appendErr(&fail, p, "required", `"field is required (empty slice)"`, "0")
+	if checks.Len() > 0 {
+		fmt.Fprintf(w, "if %s == 0 {\n%s} else {\n%s}\n", length, fail.String(), checks.String())
+	} else {
+		writeIf(w, length+" == 0", fail.String())
+	}
+}
+
+// mapChecks writes the checks of validateMap on the map x.
+func (g *generator) mapChecks(w *strings.Builder, x string, m *types.Map, c *schema.MapConstraints, p, where string) {
+	if c == nil {
+		return
+	}
+	if k, ok := m.Key().Underlying().(*types.Basic); !ok || k.Info()&types.IsString == 0 {
+		g.failf(where, "maps with %s keys are not supported", m.Key())
+		return
+	}
+	var checks strings.Builder
+	length := call("len", x)
+	got := " + " + g.use("strconv") + ".Itoa(" + length + ") + \")\""
+	if c.MinProperties != nil {
+		var fail strings.Builder
+		g.appendErr(&fail, p, "minProperties", strconv.Quote(fmt.Sprintf("must have at least %d properties (got ", *c.MinProperties))+got, length)
+		writeIf(&checks, fmt.Sprintf("%s < %d", length, *c.MinProperties), fail.String())
+	}
+	if c.MaxProperties != nil {
+		var fail strings.Builder
+		g.appendErr(&fail, p, "maxProperties", strconv.Quote(fmt.Sprintf("must have at most %d properties (got ", *c.MaxProperties))+got, length)
+		writeIf(&checks, fmt.Sprintf("%s > %d", length, *c.MaxProperties), fail.String())
+	}
+	if c.Values != nil {
+		k, v := g.name("k"), g.name("v")
+		key := k
+		if !types.Identical(m.Key(), types.Typ[types.String]) {
+			key = "string(" + k + ")"
+		}
+		var values strings.Builder
+		g.validate(&values, v, m.Elem(), *c.Values, concat(p, `"."`)+" + "+key, where+".*")
+		if values.Len() > 0 {
+			fmt.Fprintf(&checks, "for %s, %s := range %s {\n%s}\n", k, v, x, values.String())
+		}
+	}
+
+	if !c.Required {
+		w.WriteString(checks.String())
+		return
+	}
+	var fail strings.Builder
+	g.appendErr(&fail, p, "required", `"field is required (empty map)"`, "0")
+	if checks.Len() > 0 {
+		fmt.Fprintf(w, "if %s == 0 {\n%s} else {\n%s}\n", length, fail.String(), checks.String())
+	} else {
+		writeIf(w, length+" == 0", fail.String())
+	}
+}
+
+// appendErr writes the statement appending a ValidationError for the
+// failed keyword.
+func (g *generator) appendErr(w *strings.Builder, p, keyword, msg, value string) {
+	if value == "nil" {
+		fmt.Fprintf(w, "errs = append(errs, schema.ValidationError{Field: %s, Message: %s, Keyword: %q})\n", p, msg, keyword)
+		return
+	}
+	fmt.Fprintf(w, "errs = append(errs, schema.ValidationError{Field: %s, Message: %s, Value: %s, Keyword: %q})\n", p, msg, value, keyword)
+}
+
+// ---- defaults ----
+
+// defaultsBody returns the body of the ApplySchemaDefaults method of s,
+// like applyObjectDefaults.
+func (g *generator) defaultsBody(s *structType) string {
+	if s.defaults != nil {
+		return *s.defaults
+	}
+	if s.busy {
+		// A recursive type: the inner call is decided by the outer one.
+		return ""
+	}
+	s.busy = true
+	tmp := g.tmp
+	g.tmp = 0
+	defer func() { g.tmp = tmp }()
+	recv := strings.ToLower(s.obj.Name()[:1])
+	var body strings.Builder
+	for i := range s.st.NumFields() {
+		f := s.st.Field(i)
+		if fs, ok := g.schemaField(s, i); ok {
+			g.defaults(&body, recv+"."+f.Name(), f.Type(), fs, true, s.obj.Name()+"."+f.Name())
+		}
+	}
+	s.busy = false
+	str := body.String()
+	s.defaults = &str
+	return str
+}
+
+// defaults writes the default filling of x, a value of type t, like
+// applyFieldDefaults. Values that could not be set by reflection, such as
+// the values of maps, are only descended into.
+func (g *generator) defaults(w *strings.Builder, x string, t types.Type, fs schema.FieldSchema, settable bool, where string) {
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		// Nil pointers keep their nil: only the target gets the default.
+		var inner strings.Builder
+		g.defaults(&inner, "(*"+x+")", ptr.Elem(), fs, true, where)
+		writeIf(w, x+" != nil", inner.String())
+		return
+	}
+	if fs.Default != nil && settable {
+		if zero, ok := g.zero(x, t, where); ok {
+			if set := g.setDefault(x, t, *fs.Default, where); set != "" {
+				writeIf(w, zero, set)
+			}
+		}
+	}
+	g.defaultsInside(w, x, t, fs, settable, where)
+}
+
+// defaultsInside writes the descent of applyFieldDefaults into x.
+func (g *generator) defaultsInside(w *strings.Builder, x string, t types.Type, fs schema.FieldSchema, settable bool, where string) {
+	switch fs.Type {
+	case "array":
+		if fs.Array == nil || fs.Array.Items == nil {
+			return
+		}
+		var elem types.Type
+		switch u := t.Underlying().(type) {
+		case *types.Slice:
+			elem, settable = u.Elem(), true
+		case *types.Array:
+			elem = u.Elem()
+		default:
+			return
+		}
+		i := g.name("i")
+		var items strings.Builder
+		g.defaults(&items, x+"["+i+"]", elem, *fs.Array.Items, settable, where+"[]")
+		if items.Len() > 0 {
+			fmt.Fprintf(w, "for %s := range %s {\n%s}\n", i, x, items.String())
+		}
+	case "object":
+		switch {
+		case fs.Map != nil && fs.Map.Values != nil:
+			m, ok := t.Underlying().(*types.Map)
+			if !ok {
+				return
+			}
+			v := g.name("v")
+			var values strings.Builder
+			g.defaults(&values, v, m.Elem(), *fs.Map.Values, false, where+".*")
+			if values.Len() > 0 {
+				fmt.Fprintf(w, "for _, %s := range %s {\n%s}\n", v, x, values.String())
+			}
+		case fs.Nested != nil && settable:
+			if _, ok := t.Underlying().(*types.Struct); !ok || isTime(t) {
+				return
+			}
+			if s := g.structOf(t, fs.Nested, where); s != nil && g.defaultsBody(s) != "" {
+				fmt.Fprintf(w, "%s.ApplySchemaDefaults()\n", receiver(x))
+			}
+		}
+	}
+}
+
+// setDefault returns the statements setting x to the raw default, like
+// decodeDefault, or "" if the default never applies.
+func (g *generator) setDefault(x string, t types.Type, raw, where string) string {
+	if hasMethod(types.NewPointer(t), "UnmarshalText") {
+		d := g.name("d")
+		return fmt.Sprintf("var %s %s\nif %s.UnmarshalText([]byte(%s)) == nil {\n%s = %s\n}\n", d, g.typeString(t), d, quote(raw), x, d)
+	}
+	if isBasic(t) {
+		lit, ok := g.basicDefault(t, raw, where)
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf("%s = %s\n", x, lit)
+	}
+	return g.decodeJSON(x, g.name("d"), t, raw)
+}
+
+// decodeJSON returns the statements decoding a JSON default into a new
+// value of type t and assigning it to x.
+func (g *generator) decodeJSON(x, d string, t types.Type, raw string) string {
+	dec := g.name("dec")
+	var b strings.Builder
+	fmt.Fprintf(&b, "var %s %s\n", d, g.typeString(t))
+	fmt.Fprintf(&b, "%s := %s.NewDecoder(%s.NewReader(%s))\n", dec, g.use("encoding/json"), g.use("strings"), quote(raw))
+	fmt.Fprintf(&b, "%s.DisallowUnknownFields()\n", dec)
+	fmt.Fprintf(&b, "if %s.Decode(&%s) == nil {\n%s = %s\n}\n", dec, d, x, d)
+	return b.String()
+}
+
+// basicDefault parses a default of a string, number or boolean type at
+// generation time, as decodeDefault does at run time.
+func (g *generator) basicDefault(t types.Type, raw, where string) (string, bool) {
+	u := t.Underlying().(*types.Basic)
+	bits := 64
+	switch u.Kind() {
+	case types.Int8, types.Uint8:
+		bits = 8
+	case types.Int16, types.Uint16:
+		bits = 16
+	case types.Int32, types.Uint32, types.Float32:
+		bits = 32
+	}
+	info := u.Info()
+	switch {
+	case info&types.IsString != 0:
+		return strconv.Quote(raw), true
+	case info&types.IsBoolean != 0:
+		b, err := strconv.ParseBool(raw)
+		return strconv.FormatBool(b), err == nil
+	case info&types.IsFloat != 0:
+		f, err := strconv.ParseFloat(raw, bits)
+		if err == nil && (math.IsInf(f, 0) || math.IsNaN(f)) {
+			g.failf(where, "non-finite defaults are not supported")
+			return "", false
+		}
+		return floatLit(f), err == nil
+	case info&types.IsUnsigned != 0:
+		n, err := strconv.ParseUint(raw, 10, bits)
+		return strconv.FormatUint(n, 10), err == nil
+	case info&types.IsInteger != 0:
+		n, err := strconv.ParseInt(raw, 10, bits)
+		return strconv.FormatInt(n, 10), err == nil
+	}
+	g.failf(where, "defaults of type %s are not supported", t)
+	return "", false
+}
+
+// ---- expressions ----
+
+// zero returns the condition of x being the zero value of t, as
+// reflect.Value.IsZero.
+func (g *generator) zero(x string, t types.Type, where string) (string, bool) {
+	nz := g.nonZero(x, t, where)
+	return negate(nz), nz != ""
+}
+
+// nonZero returns the condition of x not being the zero value of t, or ""
+// if it cannot be expressed.
+func (g *generator) nonZero(x string, t types.Type, where string) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsString != 0:
+			return x + ` != ""`
+		case u.Info()&types.IsBoolean != 0:
+			return x
+		case u.Info()&types.IsNumeric != 0:
+			return x + " != 0"
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Interface, *types.Chan, *types.Signature:
+		return x + " != nil"
+	case *types.Struct, *types.Array:
+		if types.Comparable(t) {
+			return fmt.Sprintf("%s != (%s{})", x, g.typeString(t))
+		}
+	}
+	g.failf(where, "cannot test a value of type %s for zero", t)
+	return ""
+}
+
+// intCond returns the condition of numericValue.cmp(f) op 0 for the integer
+// variable n, of type int64 or uint64. Bounds outside its range fold to
+// constants.
+func intCond(n string, unsigned bool, op string, f float64) string {
+	lo, hi := big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)
+	if unsigned {
+		lo, hi = new(big.Int), new(big.Int).SetUint64(math.MaxUint64)
+	}
+	floor, _ := big.NewFloat(math.Floor(f)).Int(nil)
+	ceil, _ := big.NewFloat(math.Ceil(f)).Int(nil)
+	cmp := func(k *big.Int, op string) string { return fmt.Sprintf("%s %s %s", n, op, k) }
+	switch op {
+	case "<":
+		switch {
+		case ceil.Cmp(hi) > 0:
+			return "true"
+		case ceil.Cmp(lo) <= 0:
+			return "false"
+		}
+		return cmp(ceil, "<")
+	case ">":
+		switch {
+		case floor.Cmp(lo) < 0:
+			return "true"
+		case floor.Cmp(hi) >= 0:
+			return "false"
+		}
+		return cmp(floor, ">")
+	case "<=":
+		switch {
+		case floor.Cmp(hi) >= 0:
+			return "true"
+		case floor.Cmp(lo) < 0:
+			return "false"
+		}
+		return cmp(floor, "<=")
+	case ">=":
+		switch {
+		case ceil.Cmp(lo) <= 0:
+			return "true"
+		case ceil.Cmp(hi) > 0:
+			return "false"
+		}
+		return cmp(ceil, ">=")
+	case "==":
+		if f != math.Trunc(f) || floor.Cmp(lo) < 0 || floor.Cmp(hi) > 0 {
+			return "false"
+		}
+		return cmp(floor, "==")
+	default: // "!="
+		return negate(intCond(n, unsigned, "==", f))
+	}
+}
+
+// floatCond returns the condition of numericValue.cmp(f) op 0 for the
+// float64 variable n, where NaN compares equal to everything.
+func floatCond(n, op string, f float64) string {
+	lit := floatLit(f)
+	switch op {
+	case "<", ">":
+		return fmt.Sprintf("%s %s %s", n, op, lit)
+	case "<=":
+		return fmt.Sprintf("!(%s > %s)", n, lit)
+	case ">=":
+		return fmt.Sprintf("!(%s < %s)", n, lit)
+	case "==":
+		return fmt.Sprintf("!(%s < %s || %s > %s)", n, lit, n, lit)
+	default: // "!="
+		return fmt.Sprintf("%s < %s || %s > %s", n, lit, n, lit)
+	}
+}
+
+func floatLit(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// or returns the disjunction of conditions, folding constants.
+func or(conds []string) string {
+	var terms []string
+	for _, c := range conds {
+		switch c {
+		case "true":
+			return "true"
+		case "false":
+			continue
+		}
+		terms = append(terms, c)
+	}
+	if len(terms) == 0 {
+		return "false"
+	}
+	return strings.Join(terms, " || ")
+}
+
+// negate returns the negation of a condition.
+func negate(cond string) string {
+	switch {
+	case cond == "true":
+		return "false"
+	case cond == "false" || cond == "":
+		return "true"
+	case isSimple(cond):
+		return "!" + cond
+	case !strings.ContainsAny(cond, "|&!") && strings.Count(cond, " == ") == 1:
+		return strings.Replace(cond, " == ", " != ", 1)
+	case !strings.ContainsAny(cond, "|&") && strings.Count(cond, " != ") == 1:
+		return strings.Replace(cond, " != ", " == ", 1)
+	}
+	return "!(" + cond + ")"
+}
+
+// writeIf writes body under cond, folding constant conditions.
+func writeIf(w *strings.Builder, cond, body string) {
+	switch {
+	case body == "" || cond == "false" || cond == "":
+	case cond == "true":
+		w.WriteString(body)
+	default:
+		fmt.Fprintf(w, "if %s {\n%s}\n", cond, body)
+	}
+}
+
+// concat returns the string expression a + lit, merging lit into a
+// trailing literal of a.
+func concat(a, lit string) string {
+	head, last := "", a
+	if i := strings.LastIndex(a, " + "); i >= 0 {
+		head, last = a[:i+3], a[i+3:]
+	}
+	l, err1 := strconv.Unquote(last)
+	r, err2 := strconv.Unquote(lit)
+	if strings.HasPrefix(last, `"`) && err1 == nil && err2 == nil {
+		return head + strconv.Quote(l+r)
+	}
+	return a + " + " + lit
+}
+
+// call returns the call of fn with the argument x, reusing the
+// parentheses of a dereference.
+func call(fn, x string) string {
+	if strings.HasPrefix(x, "(*") {
+		return fn + x
+	}
+	return fn + "(" + x + ")"
+}
+
+// receiver returns the expression to call a pointer method on x.
+func receiver(x string) string {
+	if strings.HasPrefix(x, "(*") && strings.HasSuffix(x, ")") {
+		return x[2 : len(x)-1]
+	}
+	return x
+}
+
+var simpleExpr = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// isSimple reports whether x is an identifier or a selector chain, which
+// can be repeated without cost.
+func isSimple(x string) bool {
+	return simpleExpr.MatchString(x)
+}
+
+// quote returns a Go string literal for s, preferring raw strings.
+func quote(s string) string {
+	if strconv.CanBackquote(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// ---- types ----
+
+func isTime(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" && named.Obj().Name() == "Time"
+}
+
+func isBasic(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Basic)
+	return ok
+}
+
+// hasMethod reports whether t or *t has the named method.
+func hasMethod(t types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// isStd reports whether path is the import path of a standard package.
+func isStd(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// cmpBool orders true before false.
+func cmpBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return -1
+	}
+	return 1
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
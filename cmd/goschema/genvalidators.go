@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+// genTag is the build tag that excludes generated files from the bootstrap
+// build.
+const genTag = "goschema_gen"
+
+// genValidatorsHelp follows the flags in the usage of gen-validators.
+const genValidatorsHelp = `
+Schemas are resolved by building and running a program that imports the
+package with go run, so the go command must be on PATH and the package must
+build without its generated files (they carry the goschema_gen build tag).
+Its init functions run. While the package doesn't build, gen-validators
+fails with the compiler errors and writes nothing.
+`
+
+func genValidators(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("goschema gen-validators", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	typeNames := flags.String("type", "", "comma-separated list of struct type names; required")
+	output := flags.String("output", "", "output file name; default <dir>/<type>_schema.go")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: goschema gen-validators -type T[,T...] [-output file] [dir]")
+		flags.PrintDefaults()
+		fmt.Fprint(stderr, genValidatorsHelp)
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *typeNames == "" || flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+	dir := "."
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+	names := strings.Split(*typeNames, ",")
+	if *output == "" {
+		*output = filepath.Join(dir, strings.ToLower(names[0])+"_schema.go")
+	}
+
+	src, err := generate(dir, names, "goschema gen-validators "+strings.Join(args, " "))
+	if err != nil {
+		fmt.Fprintln(stderr, "goschema:", err)
+		return 1
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		fmt.Fprintln(stderr, "goschema:", err)
+		return 1
+	}
+	return 0
+}
+
+// generate returns the source of the validators of the named types of the
+// package in dir.
+func generate(dir string, names []string, command string) ([]byte, error) {
+	pkg, err := loadPackage(dir)
+	if err != nil {
+		return nil, err
+	}
+	schemas, err := resolveSchemas(dir, pkg, names)
+	if err != nil {
+		return nil, err
+	}
+
+	g := newGenerator(pkg, strings.ToLower(names[0][:1])+names[0][1:])
+	for _, name := range names {
+		obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("type %s not found in package %s", name, pkg.Name())
+		}
+		g.addRoot(obj, schemas[name])
+	}
+	return g.source(command)
+}
+
+// loadPackage parses and type-checks the package in dir, without the
+// generated files. Type errors are tolerated.
+func loadPackage(dir string) (*types.Package, error) {
+	ctxt := build.Default
+	ctxt.BuildTags = append(ctxt.BuildTags, genTag)
+	bp, err := ctxt.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	if bp.Name == "main" {
+		return nil, fmt.Errorf("cannot generate validators for package main")
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil), Error: func(error) {}}
+	pkg, _ := conf.Check(bp.Name, fset, files, nil)
+	return pkg, nil
+}
+
+// bootstrap resolves the schemas of the requested types with the schema
+// package itself. It is run with go run, as if it were next to the package.
+const bootstrap = `//go:build ignore
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/twoojoo/goschema/schema"
+
+	pkg %q
+)
+
+func resolve[T any](name string, out map[string]schema.FieldSchema) {
+	s, err := schema.Compile[T]()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%%s: %%v\n", name, err)
+		os.Exit(1)
+	}
+	out[name] = s.FieldSchema()
+}
+
+func main() {
+	out := make(map[string]schema.FieldSchema)
+%s	if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`
+
+// resolveSchemas returns the schemas of the named types, as resolved at run
+// time.
+func resolveSchemas(dir string, pkg *types.Package, names []string) (map[string]schema.FieldSchema, error) {
+	importPath, err := goList(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var calls strings.Builder
+	for _, name := range names {
+		if !token.IsExported(name) {
+			return nil, fmt.Errorf("type %s: only exported types can be generated", name)
+		}
+		if _, ok := pkg.Scope().Lookup(name).(*types.TypeName); !ok {
+			return nil, fmt.Errorf("type %s not found in package %s", name, pkg.Name())
+		}
+		fmt.Fprintf(&calls, "\tresolve[pkg.%s](%q, out)\n", name, name)
+	}
+
+	// The program is written to a temporary directory, and the go command
+	// sees it in dir through an overlay, so nothing is written to the
+	// package, even if the run is interrupted.
+	tmp, err := os.MkdirTemp("", "goschema-bootstrap-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	const name = "goschema_bootstrap.go"
+	program := filepath.Join(tmp, name)
+	if err := os.WriteFile(program, fmt.Appendf(nil, bootstrap, importPath, calls.String()), 0o644); err != nil {
+		return nil, err
+	}
+	overlay, err := json.Marshal(map[string]any{
+		"Replace": map[string]string{filepath.Join(absDir, name): program},
+	})
+	if err != nil {
+		return nil, err
+	}
+	overlayFile := filepath.Join(tmp, "overlay.json")
+	if err := os.WriteFile(overlayFile, overlay, 0o644); err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "run", "-tags", genTag, "-overlay", overlayFile, name)
+	cmd.Dir = dir
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return nil, fmt.Errorf("resolving schemas needs the go command: %v", err)
+		}
+		return nil, fmt.Errorf("resolving schemas: the package must build without its generated files:\n%s", stderr.Bytes())
+	}
+	schemas := make(map[string]schema.FieldSchema)
+	if err := json.Unmarshal(stdout.Bytes(), &schemas); err != nil {
+		return nil, fmt.Errorf("resolving schemas: %v", err)
+	}
+	return schemas, nil
+}
+
+// goList returns the import path of the package in dir.
+func goList(dir string) (string, error) {
+	cmd := exec.Command("go", "list", "-tags", genTag, "-f", "{{.ImportPath}}", ".")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return "", fmt.Errorf("resolving schemas needs the go command: %v", err)
+		}
+		return "", fmt.Errorf("go list: %v\n%s", err, stderr.Bytes())
+	}
+	return strings.TrimSpace(string(out)), nil
+}
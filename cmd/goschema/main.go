@@ -0,0 +1,71 @@
+// Command goschema is the code generator of goschema.
+//
+// Usage:
+//
+//	goschema gen-validators -type T[,T...] [-output file] [dir]
+//
+// gen-validators writes a ValidateSchema and an ApplySchemaDefaults method
+// for the named struct types of the package in dir (the current directory by
+// default) and for the struct types of that package they contain. The
+// methods check the same constraints and report the same errors as the
+// reflective engine, with straight-line code; schema.Validate and
+// schema.ParseJSON call them when present. It is meant to be run by go
+// generate:
+//
+//	//go:generate go run github.com/twoojoo/goschema/cmd/goschema gen-validators -type User
+//
+// Schemas are resolved by compiling and running a small program that imports
+// the package, so that enums, providers and registered types are seen
+// exactly as at run time. The program lives in a temporary directory and is
+// shown to the go command through an overlay, so nothing but the output
+// file is written to the package directory. Generated files are excluded from that build by
+// the goschema_gen build tag, so that a stale file never prevents
+// regeneration. This has costs the tags alone wouldn't have:
+//
+//   - the go command must be on PATH, and every run compiles the package
+//     and its dependencies, which takes a few seconds on a cold cache;
+//   - the package must build without its generated files. While it doesn't,
+//     gen-validators fails with the compiler errors and writes nothing;
+//   - the init functions of the package and its dependencies run.
+//
+// Fields whose validation cannot be expressed without reflection are
+// reported as errors: composition keywords (not, anyOf, oneOf, allOf),
+// JSON-literal enum and const, temporal bounds (after, before, minAge,
+// maxAge), custom marshallers other than time.Time, and structs declared in
+// other packages. Leave such types to the reflective engine.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is main without the process exit, for tests.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+	switch args[0] {
+	case "gen-validators":
+		return genValidators(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return 0
+	}
+	fmt.Fprintf(stderr, "goschema: unknown command %q\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: goschema <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	fmt.Fprintln(w, "  gen-validators  generate reflection-free validators and default appliers")
+}
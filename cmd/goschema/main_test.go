@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate_UpToDate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	dir := filepath.Join("..", "..", "internal", "gentest")
+	got, err := generate(dir, []string{"Order", "Account"}, "goschema gen-validators -type Order,Account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join(dir, "order_schema.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("internal/gentest/order_schema.go is stale; run go generate ./internal/gentest")
+	}
+}
+
+func TestGenerate_Unsupported(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	cases := map[string]struct {
+		src  string
+		want string
+	}{
+		"composition": {
+			src:  "type T struct {\n\tA string `json:\"a\" schema:\"anyOf=minLength=1;maxLength=0\"`\n}\n",
+			want: "anyOf",
+		},
+		"temporal bound": {
+			src:  "type T struct {\n\tA time.Time `json:\"a\" schema:\"after=2020-01-01T00:00:00Z\"`\n}\n",
+			want: "temporal",
+		},
+		"not a struct": {
+			src:  "type T string\n",
+			want: "not a struct",
+		},
+		"unexported": {
+			src:  "type t struct{}\n",
+			want: "only exported types",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := "package p\n\nimport \"time\"\n\nvar _ time.Time\n\n" + tc.src
+			writeFile(t, filepath.Join(dir, "go.mod"), goMod(t))
+			writeFile(t, filepath.Join(dir, "p.go"), src)
+			typ := "T"
+			if name == "unexported" {
+				typ = "t"
+			}
+			_, err := generate(dir, []string{typ}, "goschema gen-validators -type "+typ)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got error %v, want one containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestGenerate_DoesNotBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), goMod(t))
+	writeFile(t, filepath.Join(dir, "p.go"), "package p\n\ntype T struct {\n\tA string `json:\"a\" schema:\"minLength=1\"`\n}\n\nvar broken int = \"x\"\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"gen-validators", "-type", "T", dir}, &stdout, &stderr); code != 1 {
+		t.Errorf("got exit code %d, want 1", code)
+	}
+	if msg := stderr.String(); !strings.Contains(msg, "must build") || !strings.Contains(msg, "p.go:7") {
+		t.Errorf("expected the compiler errors, got %q", msg)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected nothing to be written, got %v", entries)
+	}
+}
+
+func TestGenerate_LeavesPackageAlone(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	// The package fails to initialise if anything but its own files is
+	// in its directory while the schemas are resolved.
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), goMod(t))
+	writeFile(t, filepath.Join(dir, "p.go"), "package p\n\nimport \"os\"\n\n"+
+		"func init() {\n\tif entries, _ := os.ReadDir(\".\"); len(entries) != 2 {\n\t\tpanic(entries)\n\t}\n}\n\n"+
+		"type T struct {\n\tA string `json:\"a\" schema:\"minLength=1\"`\n}\n")
+
+	if _, err := generate(dir, []string{"T"}, "goschema gen-validators -type T"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(nil, &stdout, &stderr); code != 2 {
+		t.Errorf("got exit code %d, want 2", code)
+	}
+	if !strings.Contains(stderr.String(), "gen-validators") {
+		t.Errorf("usage does not list gen-validators: %q", stderr.String())
+	}
+	stderr.Reset()
+	if code := run([]string{"gen-validators"}, &stdout, &stderr); code != 2 {
+		t.Errorf("got exit code %d without -type, want 2", code)
+	}
+	if !strings.Contains(stderr.String(), "go run") {
+		t.Errorf("usage does not explain how schemas are resolved: %q", stderr.String())
+	}
+	if code := run([]string{"frobnicate"}, &stdout, &stderr); code != 2 {
+		t.Errorf("got exit code %d for an unknown command, want 2", code)
+	}
+}
+
+// goMod returns a go.mod that resolves the schema package to this module.
+func goMod(t *testing.T) string {
+	t.Helper()
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "module example.com/p\n\ngo 1.25\n\nrequire github.com/twoojoo/goschema v0.0.0\n\nreplace github.com/twoojoo/goschema => " + root + "\n"
+}
+
+func writeFile(t *testing.T, name, data string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,106 @@
+// Package gentest holds the types used to check the validators generated by
+// `goschema gen-validators` against the reflective engine.
+package gentest
+
+import (
+	"time"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+//go:generate go run ../../cmd/goschema gen-validators -type Order,Account
+
+// Status is an enum declared with schema.Enumer.
+type Status string
+
+const (
+	StatusOpen   Status = "open"
+	StatusClosed Status = "closed"
+)
+
+func (Status) EnumValues() []any { return []any{StatusOpen, StatusClosed} }
+
+// Priority is an enum registered with schema.RegisterEnum.
+type Priority int8
+
+func init() {
+	schema.RegisterEnum[Priority](1, 2, 3)
+}
+
+// Currency declares its constraints with schema.SchemaTagger.
+type Currency string
+
+func (Currency) SchemaTag() string { return "pattern=^[A-Z]{3}$" }
+
+// Order exercises every keyword the generator supports.
+type Order struct {
+	_ struct{} `schema:"dependentRequired:coupon=discount|note"`
+
+	ID       string            `json:"id"       schema:"required,format=uuid"`
+	Name     string            `json:"name"     schema:"minLength=2,maxLength=5,pattern=^[a-z]+$"`
+	Code     string            `json:"code"     schema:"pattern='^[a-z]{2,5}$'"`
+	Kind     string            `json:"kind"     schema:"enum=a|b|c,default=a"`
+	Fixed    string            `json:"fixed"    schema:"const=x"`
+	Status   Status            `json:"status"   schema:"default=open"`
+	Currency Currency          `json:"currency" schema:"default=EUR"`
+	Blob     []byte            `json:"blob"     schema:"maxItems=8"`
+	Note     *string           `json:"note"     schema:"minLength=3"`
+	Coupon   string            `json:"coupon"`
+	Discount float64           `json:"discount" schema:"exclusiveMinimum=0,maximum=0.5,multipleOf=0.05"`
+	Qty      int               `json:"qty"      schema:"minimum=1.5,maximum=100,multipleOf=2,default=2"`
+	Small    int8              `json:"small"    schema:"minimum=-1000,maximum=1e9"`
+	Big      uint64            `json:"big"      schema:"maximum=18446744073709551615,enum=0|1|18446744073709551615"`
+	Ratio    float32           `json:"ratio"    schema:"exclusiveMaximum=1,const=0.5"`
+	Priority Priority          `json:"priority"`
+	Timeout  time.Duration     `json:"timeout"  schema:"maximum=1m,default=30s"`
+	Rush     bool              `json:"rush"     schema:"const=false"`
+	Gift     *bool             `json:"gift"     schema:"required,enum=true"`
+	Tags     []string          `json:"tags"     schema:"required,maxItems=3,uniqueItems,items:minLength=2"`
+	Scores   [2]int            `json:"scores"   schema:"items:maximum=10"`
+	Lines    []Line            `json:"lines"    schema:"minItems=1"`
+	Shipping *Address          `json:"shipping" schema:"required"`
+	Billing  Address           `json:"billing"`
+	Extras   map[string]*Line  `json:"extras"   schema:"maxProperties=2"`
+	Labels   map[string]string `json:"labels"   schema:"minProperties=1,default={\"env\":\"prod\"}"`
+	Matrix   [][]int           `json:"matrix"   schema:"items:minItems=1"`
+	Created  time.Time         `json:"created"  schema:"required"`
+	Shipped  *time.Time        `json:"shipped"  schema:"nullable"`
+	Due      time.Time         `json:"due"      schema:"default=2030-01-01T00:00:00Z"`
+	Meta     any               `json:"meta"`
+	Hidden   string            `json:"-"        schema:"required"`
+	internal string
+}
+
+// Line is a nested type, reached through a slice and a map.
+type Line struct {
+	SKU   string  `json:"sku"   schema:"required,minLength=3"`
+	Price float64 `json:"price" schema:"minimum=0,default=1"`
+	Qty   *int    `json:"qty"   schema:"minimum=1,default=1"`
+}
+
+// Address is a nested type, reached directly and through a pointer.
+type Address struct {
+	Street string `json:"street" schema:"required"`
+	Zip    string `json:"zip"    schema:"pattern=^[0-9]{5}$"`
+}
+
+// Account has no defaults, a struct-level rule on a pointer and one on
+// the fields it embeds.
+type Account struct {
+	_ struct{} `schema:"dependentRequired:email=name,dependentRequired:reviewer=createdBy"`
+
+	*Audit
+	Email *string `json:"email" schema:"format=email"`
+	Name  string  `json:"name"`
+	Age   uint8   `json:"age"   schema:"minimum=18"`
+	Roles []Role  `json:"roles"`
+}
+
+// Audit is embedded in Account, which sees its fields as its own.
+type Audit struct {
+	CreatedBy string `json:"createdBy"`
+	Reviewer  string `json:"reviewer"`
+}
+
+// Role is an element type without constraints.
+type Role string
@@ -0,0 +1,258 @@
+package gentest_test
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/twoojoo/goschema/internal/gentest"
+	"github.com/twoojoo/goschema/schema"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func validOrder() gentest.Order {
+	return gentest.Order{
+		ID:       "123e4567-e89b-12d3-a456-426614174000",
+		Name:     "abc",
+		Code:     "ab",
+		Kind:     "a",
+		Fixed:    "x",
+		Status:   gentest.StatusOpen,
+		Currency: "EUR",
+		Discount: 0.1,
+		Qty:      2,
+		Ratio:    0.5,
+		Priority: 1,
+		Gift:     ptr(true),
+		Tags:     []string{"aa", "bb"},
+		Lines:    []gentest.Line{{SKU: "abc", Qty: ptr(1)}},
+		Shipping: &gentest.Address{Street: "Main"},
+		Billing:  gentest.Address{Street: "Main", Zip: "12345"},
+		Labels:   map[string]string{"env": "prod"},
+		Created:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func orderWith(f func(*gentest.Order)) gentest.Order {
+	o := validOrder()
+	f(&o)
+	return o
+}
+
+// sorted returns the errors of err in a stable order: map values are
+// visited in random order by both engines.
+func sorted(t *testing.T, err error) schema.ValidationErrors {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var ve schema.ValidationErrors
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationErrors, got %T: %v", err, err)
+	}
+	ve = slices.Clone(ve)
+	slices.SortStableFunc(ve, func(a, b schema.ValidationError) int {
+		return cmp.Or(cmp.Compare(a.Field, b.Field), cmp.Compare(a.Message, b.Message))
+	})
+	return ve
+}
+
+// sameErrors compares errors by field, message and the type and printed
+// form of the value, so that NaN values compare equal.
+func sameErrors(a, b schema.ValidationErrors) bool {
+	return slices.EqualFunc(a, b, func(x, y schema.ValidationError) bool {
+		return x.Field == y.Field && x.Message == y.Message && x.Keyword == y.Keyword &&
+			fmt.Sprintf("%T %#v", x.Value, x.Value) == fmt.Sprintf("%T %#v", y.Value, y.Value)
+	})
+}
+
+func TestGenerated_MatchesReflective(t *testing.T) {
+	cases := map[string]any{
+		"valid order": validOrder(),
+		"zero order":  gentest.Order{},
+		"strings": orderWith(func(o *gentest.Order) {
+			o.ID = "nope"
+			o.Name = "Ab"
+			o.Code = "abcdef"
+			o.Kind = "d"
+			o.Fixed = "y"
+			o.Status = "pending"
+			o.Currency = "eur"
+			o.Blob = []byte("0123456789")
+			o.Note = ptr("ab")
+		}),
+		"multibyte lengths": orderWith(func(o *gentest.Order) {
+			o.Name = "àèìòù"
+			o.Note = ptr("日本")
+		}),
+		"numbers": orderWith(func(o *gentest.Order) {
+			o.Discount = 0.51
+			o.Qty = 101
+			o.Small = -128
+			o.Big = 2
+			o.Ratio = 1
+			o.Priority = 4
+			o.Timeout = 2 * time.Minute
+		}),
+		"number edges": orderWith(func(o *gentest.Order) {
+			o.Discount = 0.15
+			o.Qty = 1
+			o.Big = math.MaxUint64
+			o.Ratio = 0.25
+		}),
+		"not a multiple": orderWith(func(o *gentest.Order) {
+			o.Discount = 0.17
+			o.Qty = 3
+		}),
+		"nan": orderWith(func(o *gentest.Order) {
+			o.Discount = math.NaN()
+			o.Ratio = float32(math.NaN())
+		}),
+		"bools": orderWith(func(o *gentest.Order) {
+			o.Rush = true
+			o.Gift = ptr(false)
+		}),
+		"nil required pointers": orderWith(func(o *gentest.Order) {
+			o.Gift = nil
+			o.Shipping = nil
+		}),
+		"arrays": orderWith(func(o *gentest.Order) {
+			o.Tags = []string{"a", "bb", "bb", "cc"}
+			o.Scores = [2]int{11, 3}
+			o.Lines = []gentest.Line{}
+			o.Matrix = [][]int{{}, {1}}
+		}),
+		"empty required slice": orderWith(func(o *gentest.Order) {
+			o.Tags = []string{}
+		}),
+		"nested": orderWith(func(o *gentest.Order) {
+			o.Lines = []gentest.Line{{SKU: "ab", Price: -1, Qty: ptr(0)}, {}}
+			o.Shipping = &gentest.Address{Zip: "1"}
+			o.Billing = gentest.Address{Zip: "abcde"}
+		}),
+		"maps": orderWith(func(o *gentest.Order) {
+			o.Extras = map[string]*gentest.Line{"a": {SKU: "x"}, "b": nil, "c": {SKU: "abc"}}
+			o.Labels = map[string]string{}
+		}),
+		"times": orderWith(func(o *gentest.Order) {
+			o.Created = time.Time{}
+			o.Shipped = nil
+		}),
+		"unmarshalable time": orderWith(func(o *gentest.Order) {
+			o.Created = time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)
+		}),
+		"dependent required": orderWith(func(o *gentest.Order) {
+			o.Coupon = "SAVE"
+		}),
+		"dependent required satisfied": orderWith(func(o *gentest.Order) {
+			o.Coupon = "SAVE"
+			o.Discount = 0.1
+			o.Note = ptr("note")
+		}),
+		"pointer to order": ptr(orderWith(func(o *gentest.Order) { o.Name = "" })),
+		"valid account":    gentest.Account{Email: ptr("a@b.co"), Name: "A", Age: 18},
+		"account": gentest.Account{
+			Email: ptr("nope"),
+			Age:   17,
+			Roles: []gentest.Role{"admin"},
+		},
+		"zero account": gentest.Account{},
+		"promoted dependent required": gentest.Account{
+			Audit: &gentest.Audit{Reviewer: "ann"},
+			Name:  "A",
+			Age:   18,
+		},
+		"promoted dependent required satisfied": gentest.Account{
+			Audit: &gentest.Audit{Reviewer: "ann", CreatedBy: "bob"},
+			Name:  "A",
+			Age:   18,
+		},
+	}
+
+	for name, v := range cases {
+		t.Run(name, func(t *testing.T) {
+			want := sorted(t, schema.Validate(v, schema.Reflective()))
+			got := sorted(t, schema.Validate(v))
+			if !sameErrors(got, want) {
+				t.Errorf("generated errors differ from reflective ones\ngot:  %#v\nwant: %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestGenerated_IsUsed(t *testing.T) {
+	var _ schema.Validator = (*gentest.Order)(nil)
+	var _ schema.DefaultApplier = (*gentest.Order)(nil)
+	var _ schema.Validator = (*gentest.Account)(nil)
+
+	o := validOrder()
+	if err := o.ValidateSchema(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o.ID = ""
+	if err := sorted(t, o.ValidateSchema()); !err.Has("id") {
+		t.Errorf("expected an error for field %q, got %v", "id", err)
+	}
+}
+
+func TestGenerated_DefaultsMatchReflective(t *testing.T) {
+	inputs := map[string]string{
+		"empty":   `{}`,
+		"partial": `{"qty":4,"kind":"b","status":"closed","labels":{"a":"b"},"timeout":1000}`,
+		"nested":  `{"lines":[{"sku":"abc"},{"sku":"def","price":2,"qty":3}],"extras":{"a":{"sku":"ghi"}},"shipping":{"street":"Main"}}`,
+		"invalid": `{"id":"nope","tags":["a"]}`,
+		"account": `{"email":"a@b.co","age":20}`,
+	}
+	for name, in := range inputs {
+		t.Run(name, func(t *testing.T) {
+			want, wantErr := schema.ParseJSON[gentest.Order]([]byte(in), schema.Reflective())
+			got, gotErr := schema.ParseJSON[gentest.Order]([]byte(in))
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("generated defaults differ from reflective ones\ngot:  %+v\nwant: %+v", got, want)
+			}
+			if !sameErrors(sorted(t, gotErr), sorted(t, wantErr)) {
+				t.Errorf("errors differ\ngot:  %v\nwant: %v", gotErr, wantErr)
+			}
+
+			wantAcc, _ := schema.ParseJSON[gentest.Account]([]byte(in), schema.Reflective())
+			gotAcc, _ := schema.ParseJSON[gentest.Account]([]byte(in))
+			if !reflect.DeepEqual(gotAcc, wantAcc) {
+				t.Errorf("account differs\ngot:  %+v\nwant: %+v", gotAcc, wantAcc)
+			}
+		})
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	o := validOrder()
+	b.Run("reflective", func(b *testing.B) {
+		for b.Loop() {
+			_ = schema.Validate(&o, schema.Reflective())
+		}
+	})
+	b.Run("generated", func(b *testing.B) {
+		for b.Loop() {
+			_ = o.ValidateSchema()
+		}
+	})
+}
+
+func BenchmarkApplyDefaults(b *testing.B) {
+	data := []byte(`{"lines":[{"sku":"abc"}],"shipping":{"street":"Main"}}`)
+	b.Run("reflective", func(b *testing.B) {
+		for b.Loop() {
+			_, _ = schema.ParseJSON[gentest.Order](data, schema.Reflective())
+		}
+	})
+	b.Run("generated", func(b *testing.B) {
+		for b.Loop() {
+			_, _ = schema.ParseJSON[gentest.Order](data)
+		}
+	})
+}
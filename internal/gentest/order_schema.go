@@ -0,0 +1,449 @@
+// Code generated by "goschema gen-validators -type Order,Account"; DO NOT EDIT.
+
+//go:build !goschema_gen
+
+package gentest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+var orderSchemaPatterns = [...]*regexp.Regexp{
+	regexp.MustCompile(`^[a-z]+$`),
+	regexp.MustCompile(`^[a-z]{2,5}$`),
+	regexp.MustCompile(`^[A-Z]{3}$`),
+	regexp.MustCompile(`^[0-9]{5}$`),
+}
+
+// ValidateSchema validates o against its `schema` tags, like
+// schema.Validate but without reflection.
+func (o *Order) ValidateSchema() error {
+	if errs := o.validateSchema("", nil); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (o *Order) validateSchema(path string, errs schema.ValidationErrors) schema.ValidationErrors {
+	prefix := ""
+	if path != "" {
+		prefix = path + "."
+	}
+	if o.Coupon != "" {
+		if o.Discount == 0 {
+			errs = append(errs, schema.ValidationError{Field: path, Message: "field \"discount\" is required because \"coupon\" is present", Keyword: "dependentRequired"})
+		}
+		if o.Note == nil {
+			errs = append(errs, schema.ValidationError{Field: path, Message: "field \"note\" is required because \"coupon\" is present", Keyword: "dependentRequired"})
+		}
+	}
+	if o.ID == "" {
+		errs = append(errs, schema.ValidationError{Field: prefix + "id", Message: "field is required", Value: o.ID, Keyword: "required"})
+	} else {
+		if !schema.MatchFormat("uuid", o.ID) {
+			errs = append(errs, schema.ValidationError{Field: prefix + "id", Message: "must be a valid uuid", Value: o.ID, Keyword: "format"})
+		}
+	}
+	if o.Name != "" {
+		n1 := utf8.RuneCountInString(o.Name)
+		if n1 < 2 {
+			errs = append(errs, schema.ValidationError{Field: prefix + "name", Message: "must be at least 2 characters long (got " + strconv.Itoa(n1) + ")", Value: o.Name, Keyword: "minLength"})
+		}
+		if n1 > 5 {
+			errs = append(errs, schema.ValidationError{Field: prefix + "name", Message: "must be at most 5 characters long (got " + strconv.Itoa(n1) + ")", Value: o.Name, Keyword: "maxLength"})
+		}
+		if !orderSchemaPatterns[0].MatchString(o.Name) {
+			errs = append(errs, schema.ValidationError{Field: prefix + "name", Message: "must match pattern \"^[a-z]+$\"", Value: o.Name, Keyword: "pattern"})
+		}
+	}
+	if o.Code != "" {
+		if !orderSchemaPatterns[1].MatchString(o.Code) {
+			errs = append(errs, schema.ValidationError{Field: prefix + "code", Message: "must match pattern \"^[a-z]{2,5}$\"", Value: o.Code, Keyword: "pattern"})
+		}
+	}
+	if o.Kind != "" {
+		switch o.Kind {
+		case "a", "b", "c":
+		default:
+			errs = append(errs, schema.ValidationError{Field: prefix + "kind", Message: "must be one of [a b c]", Value: o.Kind, Keyword: "enum"})
+		}
+	}
+	if o.Fixed != "" {
+		if o.Fixed != "x" {
+			errs = append(errs, schema.ValidationError{Field: prefix + "fixed", Message: "must equal \"x\"", Value: o.Fixed, Keyword: "const"})
+		}
+	}
+	s2 := string(o.Status)
+	if s2 != "" {
+		switch s2 {
+		case "open", "closed":
+		default:
+			errs = append(errs, schema.ValidationError{Field: prefix + "status", Message: "must be one of [open closed]", Value: s2, Keyword: "enum"})
+		}
+	}
+	s3 := string(o.Currency)
+	if s3 != "" {
+		if !orderSchemaPatterns[2].MatchString(s3) {
+			errs = append(errs, schema.ValidationError{Field: prefix + "currency", Message: "must match pattern \"^[A-Z]{3}$\"", Value: s3, Keyword: "pattern"})
+		}
+	}
+	if len(o.Blob) > 8 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "blob", Message: "must have at most 8 items (got " + strconv.Itoa(len(o.Blob)) + ")", Value: len(o.Blob), Keyword: "maxItems"})
+	}
+	if o.Note != nil {
+		s6 := (*o.Note)
+		if s6 != "" {
+			n7 := utf8.RuneCountInString(s6)
+			if n7 < 3 {
+				errs = append(errs, schema.ValidationError{Field: prefix + "note", Message: "must be at least 3 characters long (got " + strconv.Itoa(n7) + ")", Value: s6, Keyword: "minLength"})
+			}
+		}
+	}
+	n8 := float64(o.Discount)
+	if n8 > 0.5 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "discount", Message: "must be <= 0.5 (got " + strconv.FormatFloat(n8, 'g', -1, 64) + ")", Value: n8, Keyword: "maximum"})
+	}
+	if !(n8 > 0) {
+		errs = append(errs, schema.ValidationError{Field: prefix + "discount", Message: "must be > 0 (got " + strconv.FormatFloat(n8, 'g', -1, 64) + ")", Value: n8, Keyword: "exclusiveMinimum"})
+	}
+	if q9 := float64(n8) / 0.05; !(math.Abs(q9-math.Round(q9)) <= 1e-9) {
+		errs = append(errs, schema.ValidationError{Field: prefix + "discount", Message: "must be a multiple of 0.05 (got " + strconv.FormatFloat(n8, 'g', -1, 64) + ")", Value: n8, Keyword: "multipleOf"})
+	}
+	n10 := int64(o.Qty)
+	if n10 < 2 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "qty", Message: "must be >= 1.5 (got " + strconv.FormatInt(n10, 10) + ")", Value: schema.NumberValue(n10), Keyword: "minimum"})
+	}
+	if n10 > 100 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "qty", Message: "must be <= 100 (got " + strconv.FormatInt(n10, 10) + ")", Value: schema.NumberValue(n10), Keyword: "maximum"})
+	}
+	if n10%2 != 0 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "qty", Message: "must be a multiple of 2 (got " + strconv.FormatInt(n10, 10) + ")", Value: schema.NumberValue(n10), Keyword: "multipleOf"})
+	}
+	n11 := int64(o.Small)
+	if n11 < -1000 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "small", Message: "must be >= -1000 (got " + strconv.FormatInt(n11, 10) + ")", Value: schema.NumberValue(n11), Keyword: "minimum"})
+	}
+	if n11 > 1000000000 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "small", Message: "must be <= 1e+09 (got " + strconv.FormatInt(n11, 10) + ")", Value: schema.NumberValue(n11), Keyword: "maximum"})
+	}
+	n12 := uint64(o.Big)
+	if !(n12 == 0 || n12 == 1) {
+		errs = append(errs, schema.ValidationError{Field: prefix + "big", Message: "must be one of [0 1 1.8446744073709552e+19]", Value: schema.NumberValue(n12), Keyword: "enum"})
+	}
+	n13 := float64(o.Ratio)
+	if !(n13 < 1) {
+		errs = append(errs, schema.ValidationError{Field: prefix + "ratio", Message: "must be < 1 (got " + strconv.FormatFloat(n13, 'g', -1, 64) + ")", Value: n13, Keyword: "exclusiveMaximum"})
+	}
+	if n13 < 0.5 || n13 > 0.5 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "ratio", Message: "must equal 0.5", Value: n13, Keyword: "const"})
+	}
+	n14 := int64(o.Priority)
+	if !(n14 == 1 || n14 == 2 || n14 == 3) {
+		errs = append(errs, schema.ValidationError{Field: prefix + "priority", Message: "must be one of [1 2 3]", Value: schema.NumberValue(n14), Keyword: "enum"})
+	}
+	n15 := int64(o.Timeout)
+	if n15 > 60000000000 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "timeout", Message: "must be <= 6e+10 (got " + strconv.FormatInt(n15, 10) + ")", Value: schema.NumberValue(n15), Keyword: "maximum"})
+	}
+	if o.Rush {
+		errs = append(errs, schema.ValidationError{Field: prefix + "rush", Message: "must equal false", Value: o.Rush, Keyword: "const"})
+	}
+	if o.Gift == nil {
+		errs = append(errs, schema.ValidationError{Field: prefix + "gift", Message: "field is required", Keyword: "required"})
+	} else {
+		if !(*o.Gift) {
+			errs = append(errs, schema.ValidationError{Field: prefix + "gift", Message: "must be one of [true]", Value: (*o.Gift), Keyword: "enum"})
+		}
+	}
+	if len(o.Tags) == 0 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "tags", Message: "field is required (empty slice)", Value: 0, Keyword: "required"})
+	} else {
+		if len(o.Tags) > 3 {
+			errs = append(errs, schema.ValidationError{Field: prefix + "tags", Message: "must have at most 3 items (got " + strconv.Itoa(len(o.Tags)) + ")", Value: len(o.Tags), Keyword: "maxItems"})
+		}
+		seen16 := make(map[string]struct{}, len(o.Tags))
+		for _, item17 := range o.Tags {
+			if _, dup := seen16[item17]; dup {
+				errs = append(errs, schema.ValidationError{Field: prefix + "tags", Message: fmt.Sprintf("items must be unique (duplicate: %v)", item17), Value: item17, Keyword: "uniqueItems"})
+				break
+			}
+			seen16[item17] = struct{}{}
+		}
+		for i18 := range o.Tags {
+			s19 := o.Tags[i18]
+			if s19 != "" {
+				n20 := utf8.RuneCountInString(s19)
+				if n20 < 2 {
+					errs = append(errs, schema.ValidationError{Field: prefix + "tags[" + strconv.Itoa(i18) + "]", Message: "must be at least 2 characters long (got " + strconv.Itoa(n20) + ")", Value: s19, Keyword: "minLength"})
+				}
+			}
+		}
+	}
+	for i21 := range o.Scores {
+		n22 := int64(o.Scores[i21])
+		if n22 > 10 {
+			errs = append(errs, schema.ValidationError{Field: prefix + "scores[" + strconv.Itoa(i21) + "]", Message: "must be <= 10 (got " + strconv.FormatInt(n22, 10) + ")", Value: schema.NumberValue(n22), Keyword: "maximum"})
+		}
+	}
+	if len(o.Lines) < 1 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "lines", Message: "must have at least 1 items (got " + strconv.Itoa(len(o.Lines)) + ")", Value: len(o.Lines), Keyword: "minItems"})
+	}
+	for i23 := range o.Lines {
+		errs = o.Lines[i23].validateSchema(prefix+"lines["+strconv.Itoa(i23)+"]", errs)
+	}
+	if o.Shipping == nil {
+		errs = append(errs, schema.ValidationError{Field: prefix + "shipping", Message: "field is required", Keyword: "required"})
+	} else {
+		errs = o.Shipping.validateSchema(prefix+"shipping", errs)
+	}
+	errs = o.Billing.validateSchema(prefix+"billing", errs)
+	if len(o.Extras) > 2 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "extras", Message: "must have at most 2 properties (got " + strconv.Itoa(len(o.Extras)) + ")", Value: len(o.Extras), Keyword: "maxProperties"})
+	}
+	for k24, v25 := range o.Extras {
+		if v25 != nil {
+			errs = v25.validateSchema(prefix+"extras."+k24, errs)
+		}
+	}
+	if len(o.Labels) < 1 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "labels", Message: "must have at least 1 properties (got " + strconv.Itoa(len(o.Labels)) + ")", Value: len(o.Labels), Keyword: "minProperties"})
+	}
+	var s29 string
+	if o.Created != (time.Time{}) {
+		if b30, err := o.Created.MarshalJSON(); err == nil {
+			s29 = string(b30[1 : len(b30)-1])
+		} else {
+			s29 = "<time.Time Value>"
+		}
+	}
+	if s29 == "" {
+		errs = append(errs, schema.ValidationError{Field: prefix + "created", Message: "field is required", Value: s29, Keyword: "required"})
+	} else {
+		if !schema.MatchFormat("date-time", s29) {
+			errs = append(errs, schema.ValidationError{Field: prefix + "created", Message: "must be a valid date-time", Value: s29, Keyword: "format"})
+		}
+	}
+	if o.Shipped != nil {
+		var s31 string
+		if (*o.Shipped) != (time.Time{}) {
+			if b32, err := (*o.Shipped).MarshalJSON(); err == nil {
+				s31 = string(b32[1 : len(b32)-1])
+			} else {
+				s31 = "<time.Time Value>"
+			}
+		}
+		if s31 != "" {
+			if !schema.MatchFormat("date-time", s31) {
+				errs = append(errs, schema.ValidationError{Field: prefix + "shipped", Message: "must be a valid date-time", Value: s31, Keyword: "format"})
+			}
+		}
+	}
+	var s33 string
+	if o.Due != (time.Time{}) {
+		if b34, err := o.Due.MarshalJSON(); err == nil {
+			s33 = string(b34[1 : len(b34)-1])
+		} else {
+			s33 = "<time.Time Value>"
+		}
+	}
+	if s33 != "" {
+		if !schema.MatchFormat("date-time", s33) {
+			errs = append(errs, schema.ValidationError{Field: prefix + "due", Message: "must be a valid date-time", Value: s33, Keyword: "format"})
+		}
+	}
+	return errs
+}
+
+// ApplySchemaDefaults sets the zero-valued fields of o that have a
+// `default=` to their default, like schema.ParseJSON.
+func (o *Order) ApplySchemaDefaults() {
+	if o.Kind == "" {
+		o.Kind = "a"
+	}
+	if o.Status == "" {
+		o.Status = "open"
+	}
+	if o.Currency == "" {
+		o.Currency = "EUR"
+	}
+	if o.Qty == 0 {
+		o.Qty = 2
+	}
+	if o.Timeout == 0 {
+		o.Timeout = 30000000000
+	}
+	for i4 := range o.Lines {
+		o.Lines[i4].ApplySchemaDefaults()
+	}
+	for _, v5 := range o.Extras {
+		if v5 != nil {
+			v5.ApplySchemaDefaults()
+		}
+	}
+	if o.Labels == nil {
+		var d6 map[string]string
+		dec7 := json.NewDecoder(strings.NewReader(`{"env":"prod"}`))
+		dec7.DisallowUnknownFields()
+		if dec7.Decode(&d6) == nil {
+			o.Labels = d6
+		}
+	}
+	if o.Due == (time.Time{}) {
+		var d10 time.Time
+		if d10.UnmarshalText([]byte(`2030-01-01T00:00:00Z`)) == nil {
+			o.Due = d10
+		}
+	}
+}
+
+// ValidateSchema validates a against its `schema` tags, like
+// schema.Validate but without reflection.
+func (a *Account) ValidateSchema() error {
+	if errs := a.validateSchema("", nil); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (a *Account) validateSchema(path string, errs schema.ValidationErrors) schema.ValidationErrors {
+	prefix := ""
+	if path != "" {
+		prefix = path + "."
+	}
+	if a.Email != nil {
+		if a.Name == "" {
+			errs = append(errs, schema.ValidationError{Field: path, Message: "field \"name\" is required because \"email\" is present", Keyword: "dependentRequired"})
+		}
+	}
+	if a.Audit != nil && a.Audit.Reviewer != "" {
+		if !(a.Audit != nil && a.Audit.CreatedBy != "") {
+			errs = append(errs, schema.ValidationError{Field: path, Message: "field \"createdBy\" is required because \"reviewer\" is present", Keyword: "dependentRequired"})
+		}
+	}
+	if a.Audit != nil {
+		errs = a.Audit.validateSchema(prefix+"Audit", errs)
+	}
+	if a.Email != nil {
+		s1 := (*a.Email)
+		if s1 != "" {
+			if !schema.MatchFormat("email", s1) {
+				errs = append(errs, schema.ValidationError{Field: prefix + "email", Message: "must be a valid email", Value: s1, Keyword: "format"})
+			}
+		}
+	}
+	n2 := uint64(a.Age)
+	if n2 < 18 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "age", Message: "must be >= 18 (got " + strconv.FormatUint(n2, 10) + ")", Value: schema.NumberValue(n2), Keyword: "minimum"})
+	}
+	return errs
+}
+
+// ApplySchemaDefaults sets the zero-valued fields of a that have a
+// `default=` to their default, like schema.ParseJSON.
+func (a *Account) ApplySchemaDefaults() {
+}
+
+// ValidateSchema validates l against its `schema` tags, like
+// schema.Validate but without reflection.
+func (l *Line) ValidateSchema() error {
+	if errs := l.validateSchema("", nil); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (l *Line) validateSchema(path string, errs schema.ValidationErrors) schema.ValidationErrors {
+	prefix := ""
+	if path != "" {
+		prefix = path + "."
+	}
+	if l.SKU == "" {
+		errs = append(errs, schema.ValidationError{Field: prefix + "sku", Message: "field is required", Value: l.SKU, Keyword: "required"})
+	} else {
+		n1 := utf8.RuneCountInString(l.SKU)
+		if n1 < 3 {
+			errs = append(errs, schema.ValidationError{Field: prefix + "sku", Message: "must be at least 3 characters long (got " + strconv.Itoa(n1) + ")", Value: l.SKU, Keyword: "minLength"})
+		}
+	}
+	n2 := float64(l.Price)
+	if n2 < 0 {
+		errs = append(errs, schema.ValidationError{Field: prefix + "price", Message: "must be >= 0 (got " + strconv.FormatFloat(n2, 'g', -1, 64) + ")", Value: n2, Keyword: "minimum"})
+	}
+	if l.Qty != nil {
+		n3 := int64(*l.Qty)
+		if n3 < 1 {
+			errs = append(errs, schema.ValidationError{Field: prefix + "qty", Message: "must be >= 1 (got " + strconv.FormatInt(n3, 10) + ")", Value: schema.NumberValue(n3), Keyword: "minimum"})
+		}
+	}
+	return errs
+}
+
+// ApplySchemaDefaults sets the zero-valued fields of l that have a
+// `default=` to their default, like schema.ParseJSON.
+func (l *Line) ApplySchemaDefaults() {
+	if l.Price == 0 {
+		l.Price = 1
+	}
+	if l.Qty != nil {
+		if (*l.Qty) == 0 {
+			(*l.Qty) = 1
+		}
+	}
+}
+
+// ValidateSchema validates a against its `schema` tags, like
+// schema.Validate but without reflection.
+func (a *Address) ValidateSchema() error {
+	if errs := a.validateSchema("", nil); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (a *Address) validateSchema(path string, errs schema.ValidationErrors) schema.ValidationErrors {
+	prefix := ""
+	if path != "" {
+		prefix = path + "."
+	}
+	if a.Street == "" {
+		errs = append(errs, schema.ValidationError{Field: prefix + "street", Message: "field is required", Value: a.Street, Keyword: "required"})
+	}
+	if a.Zip != "" {
+		if !orderSchemaPatterns[3].MatchString(a.Zip) {
+			errs = append(errs, schema.ValidationError{Field: prefix + "zip", Message: "must match pattern \"^[0-9]{5}$\"", Value: a.Zip, Keyword: "pattern"})
+		}
+	}
+	return errs
+}
+
+// ApplySchemaDefaults sets the zero-valued fields of a that have a
+// `default=` to their default, like schema.ParseJSON.
+func (a *Address) ApplySchemaDefaults() {
+}
+
+// ValidateSchema validates a against its `schema` tags, like
+// schema.Validate but without reflection.
+func (a *Audit) ValidateSchema() error {
+	if errs := a.validateSchema("", nil); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (a *Audit) validateSchema(path string, errs schema.ValidationErrors) schema.ValidationErrors {
+	return errs
+}
+
+// ApplySchemaDefaults sets the zero-valued fields of a that have a
+// `default=` to their default, like schema.ParseJSON.
+func (a *Audit) ApplySchemaDefaults() {
+}
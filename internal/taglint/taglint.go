@@ -0,0 +1,18 @@
+// Package taglint gives the goschema commands the tag checks of the schema
+// package without making them part of its API. The schema package sets the
+// functions when it is initialised, so importers must also import it.
+package taglint
+
+import "reflect"
+
+var (
+	// Tag checks the `schema` tag of a field of type t, as schema.Lint does
+	// for every field. It returns nil or a schema.LintErrors without
+	// locations. A nil t stands for a type whose schema cannot be known
+	// statically; only the checks that do not depend on it are run.
+	Tag func(t reflect.Type, tag string) error
+
+	// StructTag checks the struct-level tag of a `_` sentinel field, given
+	// the JSON names of the fields of the struct, like Tag.
+	StructTag func(tag string, fields []string) error
+)
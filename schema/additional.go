@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// isExtraField reports whether f is tagged `schema:"additionalProperties"`,
+// which makes it collect the members of the JSON object that match no
+// field. Such a field must be a map with string keys, skipped by
+// encoding/json with `json:"-"`.
+func isExtraField(f reflect.StructField) (bool, error) {
+	opts, err := parseTagOptions(f.Tag.Get("schema"))
+	if err != nil || !opts.flag("additionalProperties") {
+		// Malformed tags are reported with the field's schema.
+		return false, nil
+	}
+	if f.Type.Kind() != reflect.Map || f.Type.Key().Kind() != reflect.String {
+		return false, fmt.Errorf("additionalProperties fields must be maps with string keys, got %s", f.Type)
+	}
+	if f.Tag.Get("json") != "-" {
+		return false, fmt.Errorf("additionalProperties fields must be tagged `json:\"-\"`")
+	}
+	if len(opts) > 1 {
+		return false, fmt.Errorf("additionalProperties fields take no other keywords")
+	}
+	return true, nil
+}
+
+// hasAdditionalRules reports whether an object in fs forbids or collects
+// additional properties. encoding/json only rejects unknown keys for a
+// whole value, stopping at the first one, so such values are left to the
+// decoder, which handles every object on its own.
+func hasAdditionalRules(fs FieldSchema) bool {
+	switch {
+	case fs.Nested != nil:
+		n := fs.Nested
+		if n.ExtraField != "" || (n.AdditionalProperties != nil && !*n.AdditionalProperties) {
+			return true
+		}
+		for _, f := range n.Fields {
+			if hasAdditionalRules(f) {
+				return true
+			}
+		}
+	case fs.Array != nil && fs.Array.Items != nil:
+		return hasAdditionalRules(*fs.Array.Items)
+	case fs.Map != nil && fs.Map.Values != nil:
+		return hasAdditionalRules(*fs.Map.Values)
+	}
+	return false
+}
+
+// decodes reports whether [ParseJSON] hands values of type t, whose schema
+// is fs, to the decoder.
+func decodes(t reflect.Type, fs FieldSchema) bool {
+	return hasWrapper(t, map[reflect.Type]bool{}) || hasAdditionalRules(fs)
+}
@@ -0,0 +1,115 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Sealed struct {
+	_    struct{} `schema:"additionalProperties=false"`
+	Code string   `json:"code"`
+}
+
+type Envelope struct {
+	_      struct{}          `schema:"additionalProperties=false"`
+	Kind   string            `json:"kind"`
+	Open   Place             `json:"open"`
+	Seals  []Sealed          `json:"seals"`
+	ByName map[string]Sealed `json:"by_name"`
+}
+
+type Event struct {
+	Type  string         `json:"type" schema:"required"`
+	Extra map[string]any `json:"-"    schema:"additionalProperties"`
+	Meta  Tagged         `json:"meta"`
+}
+
+type Tagged struct {
+	Labels map[string]string `json:"-" schema:"additionalProperties"`
+}
+
+func TestAdditionalProperties_PerObject(t *testing.T) {
+	data := []byte(`{"kind":"k","x":1,"open":{"city":"Rome","y":2},"seals":[{"code":"a","z":3}],"by_name":{"b":{"w":4}},"v":5}`)
+	for name, parse := range map[string]func([]byte) error{
+		"ParseJSON":  func(b []byte) error { _, err := schema.ParseJSON[Envelope](b); return err },
+		"DecodeJSON": func(b []byte) error { _, err := schema.DecodeJSON[Envelope](b); return err },
+		"Compiled":   func(b []byte) error { _, err := schema.MustCompile[Envelope]().ParseJSON(b); return err },
+	} {
+		ve := mustValidationErrors(t, parse(data))
+		var got []string
+		for _, e := range ve {
+			got = append(got, e.Field)
+		}
+		// Every unknown key is reported, except in the lenient Place.
+		if want := []string{"x", "seals[0].z", "by_name.b.w", "v"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestAdditionalProperties_Collected(t *testing.T) {
+	e, err := schema.ParseJSON[Event]([]byte(`{"type":"click","x":1,"tags":["a"],"meta":{"env":"prod","TYPE":"dup"}}`))
+	assertNoError(t, err)
+	if e.Type != "click" {
+		t.Errorf("unexpected type %q", e.Type)
+	}
+	want := map[string]any{"x": 1.0, "tags": []any{"a"}}
+	if !reflect.DeepEqual(e.Extra, want) {
+		t.Errorf("got extra %v, want %v", e.Extra, want)
+	}
+	if want := map[string]string{"env": "prod", "TYPE": "dup"}; !reflect.DeepEqual(e.Meta.Labels, want) {
+		t.Errorf("got labels %v, want %v", e.Meta.Labels, want)
+	}
+
+	// Values that don't fit the map are reported at their path.
+	_, err = schema.ParseJSON[Event]([]byte(`{"type":"click","meta":{"n":1}}`))
+	ve := mustValidationErrors(t, err)
+	if len(ve) != 1 || ve[0].Field != "meta.n" || ve[0].Message != "expected type string" {
+		t.Errorf("expected a type error for meta.n, got %v", ve)
+	}
+
+	// The field is not part of the JSON form.
+	b, err := json.Marshal(e)
+	assertNoError(t, err)
+	if string(b) != `{"type":"click","meta":{}}` {
+		t.Errorf("unexpected JSON: %s", b)
+	}
+}
+
+func TestAdditionalProperties_Partial(t *testing.T) {
+	e, present, err := schema.ParseJSONPartial[Event]([]byte(`{"x":1}`))
+	assertNoError(t, err)
+	if e.Extra["x"] != 1.0 || !present.Has("x") {
+		t.Errorf("expected x to be collected and present, got %v %v", e.Extra, present)
+	}
+}
+
+func TestAdditionalProperties_FieldErrors(t *testing.T) {
+	type NotMap struct {
+		Extra []string `json:"-" schema:"additionalProperties"`
+	}
+	type NotSkipped struct {
+		Extra map[string]any `json:"extra" schema:"additionalProperties"`
+	}
+	type Twice struct {
+		A map[string]any `json:"-" schema:"additionalProperties"`
+		B map[string]any `json:"-" schema:"additionalProperties"`
+	}
+	type Closed struct {
+		_     struct{}       `schema:"additionalProperties=false"`
+		Extra map[string]any `json:"-" schema:"additionalProperties"`
+	}
+	for name, err := range map[string]error{
+		"not a map":   schema.ValidateJSON[NotMap]([]byte(`{}`)),
+		"not skipped": schema.ValidateJSON[NotSkipped]([]byte(`{}`)),
+		"twice":       schema.ValidateJSON[Twice]([]byte(`{}`)),
+		"closed":      schema.ValidateJSON[Closed]([]byte(`{}`)),
+	} {
+		if _, ok := err.(schema.ValidationErrors); ok || err == nil {
+			t.Errorf("%s: expected a schema error, got %v", name, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package schema_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/twoojoo/goschema/schema"
@@ -105,6 +106,35 @@ func TestDependentRequired_Validation(t *testing.T) {
 	}
 }
 
+// DepBilling is embedded: its fields are promoted into DepEmbedded.
+type DepBilling struct {
+	CC   string `json:"credit_card"`
+	Addr string `json:"billing_addr"`
+}
+
+type DepEmbedded struct {
+	_         any    `schema:"dependentRequired:billing_id=credit_card,dependentRequired:billing_addr=billing_id"`
+	BillingID string `json:"billing_id"`
+	*DepBilling
+}
+
+func TestDependentRequired_PromotedFields(t *testing.T) {
+	assertNoError(t, schema.Validate(DepEmbedded{}))
+	assertNoError(t, schema.Validate(DepEmbedded{BillingID: "123", DepBilling: &DepBilling{CC: "visa"}}))
+
+	ve := mustValidationErrors(t, schema.Validate(DepEmbedded{BillingID: "123"}))
+	if len(ve) != 1 || !strings.Contains(ve[0].Message, `"credit_card" is required`) {
+		t.Errorf("expected credit_card to be required, got %v", ve)
+	}
+	ve = mustValidationErrors(t, schema.Validate(DepEmbedded{DepBilling: &DepBilling{Addr: "1 St"}}))
+	if len(ve) != 1 || !strings.Contains(ve[0].Message, `"billing_id" is required`) {
+		t.Errorf("expected billing_id to be required, got %v", ve)
+	}
+
+	assertNoError(t, schema.Lint[DepEmbedded]())
+	assertNoError(t, schema.Check[DepEmbedded]())
+}
+
 // ---- ToJSONSchema emission ----
 
 func TestToJSONSchema_Advanced(t *testing.T) {
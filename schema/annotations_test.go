@@ -0,0 +1,73 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Account struct {
+	_        any      `schema:"title=Account"`
+	ID       string   `json:"id"       schema:"readOnly,title=Identifier,description=Server-assigned ID"`
+	Password string   `json:"password" schema:"writeOnly,minLength=8"`
+	Plan     string   `json:"plan"     schema:"enum=free|pro,default=free,examples=free|pro"`
+	Seats    int      `json:"seats"    schema:"default=1,examples=1|5|10"`
+	Legacy   bool     `json:"legacy"   schema:"deprecated,default=false"`
+	Roles    []string `json:"roles"    schema:"default=[\"member\"],examples=[\"admin\"]|[\"member\"]"`
+	Owner    Address  `json:"owner"    schema:"title=Billing owner"`
+}
+
+func TestAnnotations_ToJSONSchema(t *testing.T) {
+	js, err := schema.ToJSONSchema[Account]()
+	assertNoError(t, err)
+	props := js["properties"].(map[string]any)
+
+	id := props["id"].(map[string]any)
+	if id["readOnly"] != true || id["title"] != "Identifier" || id["description"] != "Server-assigned ID" {
+		t.Errorf("unexpected annotations on id: %v", id)
+	}
+	if props["password"].(map[string]any)["writeOnly"] != true {
+		t.Errorf("expected writeOnly on password: %v", props["password"])
+	}
+
+	plan := props["plan"].(map[string]any)
+	if plan["default"] != "free" {
+		t.Errorf("expected string default, got %v", plan["default"])
+	}
+	if ex, _ := plan["examples"].([]any); len(ex) != 2 || ex[1] != "pro" {
+		t.Errorf("unexpected plan examples: %v", plan["examples"])
+	}
+
+	seats := props["seats"].(map[string]any)
+	if seats["default"] != int64(1) {
+		t.Errorf("expected typed integer default, got %T %v", seats["default"], seats["default"])
+	}
+	if ex, _ := seats["examples"].([]any); len(ex) != 3 || ex[2] != int64(10) {
+		t.Errorf("unexpected seats examples: %v", seats["examples"])
+	}
+
+	legacy := props["legacy"].(map[string]any)
+	if legacy["deprecated"] != true || legacy["default"] != false {
+		t.Errorf("unexpected legacy annotations: %v", legacy)
+	}
+
+	roles := props["roles"].(map[string]any)
+	if d, _ := roles["default"].([]any); len(d) != 1 || d[0] != "member" {
+		t.Errorf("expected JSON default for roles, got %v", roles["default"])
+	}
+	if ex, _ := roles["examples"].([]any); len(ex) != 2 {
+		t.Errorf("unexpected roles examples: %v", roles["examples"])
+	}
+
+	// Field-level title wins over the nested type's own title.
+	if props["owner"].(map[string]any)["title"] != "Billing owner" {
+		t.Errorf("expected field title on nested object: %v", props["owner"])
+	}
+	if js["title"] != "Account" {
+		t.Errorf("expected struct title, got %v", js["title"])
+	}
+}
+
+func TestAnnotations_NotValidated(t *testing.T) {
+	assertNoError(t, schema.Validate(Account{Password: "long enough", Plan: "pro", Owner: Address{Street: "Main St", City: "Rome"}}))
+}
@@ -6,13 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"reflect"
 	"strings"
 )
 
 // Validate checks a value against its type's JSON Schema constraints.
-// It supports structs, slices, arrays, and maps.
-func Validate(v any) error {
+// It supports structs, slices, arrays, and maps. Types with a generated
+// [Validator] are checked by their ValidateSchema method.
+func Validate(v any, opts ...Option) error {
 	rv := reflect.ValueOf(v)
 	if !rv.IsValid() {
 		return ValidationErrors{{Field: "", Message: "value is nil", Value: nil}}
@@ -26,23 +28,33 @@ func Validate(v any) error {
 		rv = rv.Elem()
 	}
 
-	fs, err := reflectTypeToSchema(rv.Type())
+	o := newOptions(opts)
+	// Go values carry no presence information.
+	o.presence = false
+	r, err := resolve(rv.Type())
 	if err != nil {
 		return err
 	}
+	return validateResolved(rv, r, o)
+}
 
-	errs := validateField(rv, fs, "")
-	if len(errs) == 0 {
-		return nil
+// validateResolved is Validate for a value rv of the resolved type r,
+// once o.presence is off.
+func validateResolved(rv reflect.Value, r *resolved, o *options) error {
+	if gen, ok := generatedValidator(rv); ok && !o.reflective {
+		return gen.ValidateSchema()
+	}
+	if errs := validateField(rv, r.fs, "", o); len(errs) > 0 {
+		return errs
 	}
-	return errs
+	return nil
 }
 
 // MustValidate is like [Validate] but panics on any validation failure.
 // Intended for init-time assertions and tests where a validation error is a
 // programming mistake rather than a runtime condition.
-func MustValidate(v any) {
-	if err := Validate(v); err != nil {
+func MustValidate(v any, opts ...Option) {
+	if err := Validate(v, opts...); err != nil {
 		panic("goschema: MustValidate failed: " + err.Error())
 	}
 }
@@ -63,12 +75,12 @@ func ToJSONSchema[T any]() (map[string]any, error) {
 		return nil, fmt.Errorf("goschema: ToJSONSchema requires a non-nil type")
 	}
 
-	fs, err := reflectTypeToSchema(t)
+	r, err := resolve(t)
 	if err != nil {
 		return nil, err
 	}
 
-	return fieldSchemaToJSON(fs), nil
+	return fieldSchemaToJSON(r.fs), nil
 }
 
 // ToJSONSchemaIndent is like ToJSONSchema but returns the schema as indented
@@ -92,58 +104,137 @@ func MustToJSONSchemaIndent[T any](prefix, indent string) []byte {
 
 // ParseJSON unmarshals JSON data into a value of type T and validates it against
 // the struct's `schema` tags. It is the idiomatic entry-point combining
-// json.Unmarshal, default-filling, and Validate in a single call.
+// json.Unmarshal, default-filling, and Validate in a single call. Values
+// supplied for `readOnly` fields are rejected unless [WithReadOnly] says
+// otherwise. Input that encoding/json can't decode is decoded again by
+// [DecodeJSON], so that every supplied readOnly field and type mismatch is
+// reported at once, together with the constraint violations of the values
+// that did decode. With [Presence], `required` and `default=` look at which keys
+// are present rather than at zero values. Like them, types with [Optional]
+// or [Nullable] fields, or with objects that forbid or collect additional
+// properties, are decoded by [DecodeJSON]. Every ValidationError carries
+// the position of its value in data.
 //
 //	user, err := schema.ParseJSON[User](data)
-func ParseJSON[T any](data []byte) (T, error) {
-	var v T
+func ParseJSON[T any](data []byte, opts ...Option) (T, error) {
+	v, err := parse[T](data, opts)
+	return v, locateErrors(err, data)
+}
 
-	// Resolve schema for unmarshal options (e.g. DisallowUnknownFields)
-	t := reflect.TypeOf(v)
-	for t.Kind() == reflect.Ptr {
-		t = t.Elem()
-	}
-	fs, err := reflectTypeToSchema(t)
+// parse is ParseJSON without the positions of errors, for documents that
+// are not the caller's input.
+func parse[T any](data []byte, opts []Option) (T, error) {
+	r, err := resolve(reflect.TypeOf((*T)(nil)).Elem())
 	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	o := newOptions(opts)
+	if o.presence || r.decodes {
+		// Only the decoder knows which keys were present, where the
+		// values of Optional and Nullable fields are, and which object
+		// an unknown key belongs to.
+		return decodeJSON[T](r.plan, data, o)
+	}
+	return parseJSON[T](data, r, o)
+}
+
+// parseJSON is ParseJSON for types that encoding/json can decode, given
+// the resolved schema of T.
+func parseJSON[T any](data []byte, r *resolved, o *options) (T, error) {
+	var v T
+
+	// encoding/json stops at the first problem it meets, so invalid input
+	// goes through the decoder, which reports every supplied readOnly
+	// field and type mismatch along with the constraint violations of the
+	// values that did decode.
+	all := func(err error) (T, error) {
+		if _, errs := decodeJSON[T](r.plan, data, o); errs != nil {
+			return v, errs
+		}
 		return v, err
 	}
 
-	// Unmarshal
-	dec := json.NewDecoder(bytes.NewReader(data))
-	// Strict mode only applies if we have an object schema with AdditionalProperties=false.
-	if fs.Nested != nil && fs.Nested.AdditionalProperties != nil && !*fs.Nested.AdditionalProperties {
-		dec.DisallowUnknownFields()
+	if o.rejectDuplicates {
+		if errs := duplicateKeys(data, r.plan); errs != nil {
+			return all(errs)
+		}
 	}
+
+	// Types without readOnly fields don't need data parsed twice.
+	checked := data
+	if r.readOnly {
+		var err error
+		if checked, err = checkReadOnly(data, r.fs, o); err != nil {
+			return all(err)
+		}
+	}
+
+	// Unmarshal
+	dec := json.NewDecoder(bytes.NewReader(checked))
 	if err := dec.Decode(&v); err != nil {
-		return v, wrapUnmarshalError(err)
+		return all(wrapUnmarshalError(err))
 	}
 
 	// Apply defaults before validation.
 	rv := reflect.ValueOf(&v).Elem()
-	applyFieldDefaults(rv, fs)
+	if gen, ok := any(&v).(DefaultApplier); ok && !o.reflective {
+		gen.ApplySchemaDefaults()
+	} else {
+		applyFieldDefaults(rv, r.fs)
+	}
 
-	if err := Validate(v); err != nil {
-		return v, err
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return v, ValidationErrors{{Field: "", Message: "value is nil", Value: nil}}
+		}
+		rv = rv.Elem()
+	}
+	return v, validateResolved(rv, r, o)
+}
+
+// ParseJSONFile reads the JSON file at path and parses it like
+// [ParseJSON]. The ValidationErrors name the file, so that they print as
+// editors expect: `config.json:42:13: field "servers[3].port": ...`.
+//
+//	cfg, err := schema.ParseJSONFile[Config]("config.json")
+func ParseJSONFile[T any](path string, opts ...Option) (T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		var zero T
+		return zero, err
 	}
-	return v, nil
+	v, err := ParseJSON[T](data, opts...)
+	return v, inFile(err, path)
+}
+
+// inFile sets the File of the ValidationErrors in err.
+func inFile(err error, path string) error {
+	if ve, ok := err.(ValidationErrors); ok {
+		for i := range ve {
+			ve[i].File = path
+		}
+	}
+	return err
 }
 
 // Parse is an alias for ParseJSON.
 // Deprecated: use ParseJSON instead.
-func Parse[T any](data []byte) (T, error) {
-	return ParseJSON[T](data)
+func Parse[T any](data []byte, opts ...Option) (T, error) {
+	return ParseJSON[T](data, opts...)
 }
 
 // ValidateJSON unmarshals JSON data into type T and validates it,
 // but discards the resulting object. Returns error if unmarshal or validation fails.
-func ValidateJSON[T any](data []byte) error {
-	_, err := ParseJSON[T](data)
+func ValidateJSON[T any](data []byte, opts ...Option) error {
+	_, err := ParseJSON[T](data, opts...)
 	return err
 }
 
 // MustParseJSON is like [ParseJSON] but panics on any error.
-func MustParseJSON[T any](data []byte) T {
-	v, err := ParseJSON[T](data)
+func MustParseJSON[T any](data []byte, opts ...Option) T {
+	v, err := ParseJSON[T](data, opts...)
 	if err != nil {
 		panic("goschema: MustParseJSON failed: " + err.Error())
 	}
@@ -152,13 +243,13 @@ func MustParseJSON[T any](data []byte) T {
 
 // MustParse is an alias for MustParseJSON.
 // Deprecated: use MustParseJSON instead.
-func MustParse[T any](data []byte) T {
-	return MustParseJSON[T](data)
+func MustParse[T any](data []byte, opts ...Option) T {
+	return MustParseJSON[T](data, opts...)
 }
 
 // MustValidateJSON is like [ValidateJSON] but panics on error.
-func MustValidateJSON[T any](data []byte) {
-	if err := ValidateJSON[T](data); err != nil {
+func MustValidateJSON[T any](data []byte, opts ...Option) {
+	if err := ValidateJSON[T](data, opts...); err != nil {
 		panic("goschema: MustValidateJSON failed: " + err.Error())
 	}
 }
@@ -175,6 +266,7 @@ func wrapUnmarshalError(err error) error {
 		return ValidationErrors{{
 			Field:   typeErr.Field,
 			Message: fmt.Sprintf("expected type %s", typeErr.Type.String()),
+			Keyword: "type",
 			Value:   typeErr.Value,
 		}}
 	}
@@ -197,6 +289,7 @@ func wrapUnmarshalError(err error) error {
 		return ValidationErrors{{
 			Field:   field,
 			Message: msg,
+			Keyword: "additionalProperties",
 		}}
 	}
 
@@ -259,7 +352,7 @@ func fieldSchemaToJSON(fs FieldSchema) map[string]any {
 		m = numberSchemaToJSON(fs.Number)
 		m["type"] = "number"
 	case "boolean":
-		m = map[string]any{"type": "boolean"}
+		m = boolSchemaToJSON(fs.Bool)
 	case "array":
 		m = arraySchemaToJSON(fs.Array)
 	case "object":
@@ -274,6 +367,40 @@ func fieldSchemaToJSON(fs FieldSchema) map[string]any {
 		m = map[string]any{}
 	}
 
+	if len(fs.Enum) > 0 {
+		m["enum"] = fs.Enum
+	}
+	if fs.Const != nil {
+		m["const"] = *fs.Const
+	}
+
+	// Annotations
+	if fs.Title != "" {
+		m["title"] = fs.Title
+	}
+	if fs.Description != "" {
+		m["description"] = fs.Description
+	}
+	if fs.Default != nil {
+		if v, err := parseTypedValue(*fs.Default, fs.Type); err == nil {
+			m["default"] = v
+		} else {
+			m["default"] = *fs.Default
+		}
+	}
+	if len(fs.Examples) > 0 {
+		m["examples"] = fs.Examples
+	}
+	if fs.Deprecated {
+		m["deprecated"] = true
+	}
+	if fs.ReadOnly {
+		m["readOnly"] = true
+	}
+	if fs.WriteOnly {
+		m["writeOnly"] = true
+	}
+
 	// Advanced Keywords
 	if fs.Nullable {
 		m["nullable"] = true
@@ -348,6 +475,23 @@ func numberSchemaToJSON(c *NumberConstraints) map[string]any {
 	if c.MultipleOf != nil {
 		m["multipleOf"] = *c.MultipleOf
 	}
+	if len(c.Enum) > 0 {
+		m["enum"] = c.Enum
+	}
+	if c.Const != nil {
+		m["const"] = *c.Const
+	}
+	return m
+}
+
+func boolSchemaToJSON(c *BoolConstraints) map[string]any {
+	m := map[string]any{"type": "boolean"}
+	if c == nil {
+		return m
+	}
+	if len(c.Enum) > 0 {
+		m["enum"] = c.Enum
+	}
 	if c.Const != nil {
 		m["const"] = *c.Const
 	}
@@ -401,10 +545,18 @@ func (ve ValidationErrors) MarshalJSON() ([]byte, error) {
 		Field   string `json:"field"`
 		Message string `json:"message"`
 		Value   any    `json:"value,omitempty"`
+		Keyword string `json:"keyword,omitempty"`
+		File    string `json:"file,omitempty"`
+		Line    int    `json:"line,omitempty"`
+		Column  int    `json:"column,omitempty"`
+		Offset  *int   `json:"offset,omitempty"`
 	}
 	entries := make([]entry, len(ve))
 	for i, e := range ve {
-		entries[i] = entry{Field: e.Field, Message: e.Message, Value: e.Value}
+		entries[i] = entry{Field: e.Field, Message: e.Message, Value: e.Value, Keyword: e.Keyword, File: e.File}
+		if e.Line > 0 {
+			entries[i].Line, entries[i].Column, entries[i].Offset = e.Line, e.Column, &e.Offset
+		}
 	}
 	return json.Marshal(entries)
 }
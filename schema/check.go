@@ -0,0 +1,326 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SchemaError is a problem in a resolved schema: a set of constraints that no
+// value can satisfy, or a default that would fail validation.
+type SchemaError struct {
+	Path    string // JSON field path (e.g. "address.zip", "tags[]" for items)
+	Keyword string // offending keyword
+	Message string // Human-readable reason
+}
+
+func (e SchemaError) Error() string {
+	return fmt.Sprintf("field %q: %s: %s", e.Path, e.Keyword, e.Message)
+}
+
+// SchemaErrors is the collection of problems returned by [CheckObjectSchema]
+// and [Check]. It implements the error interface.
+type SchemaErrors []SchemaError
+
+func (se SchemaErrors) Error() string {
+	msgs := make([]string, len(se))
+	for i, e := range se {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Check resolves the schema of T and reports contradictory constraints and
+// invalid defaults with [CheckObjectSchema]. Like [Lint], it is meant to be
+// called from tests:
+//
+//	if err := schema.Check[User](); err != nil {
+//		t.Fatal(err)
+//	}
+func Check[T any]() error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	fs, err := reflectTypeToSchema(t)
+	if err != nil {
+		return err
+	}
+	return schemaErrors(checkField(fs, ""))
+}
+
+// CheckObjectSchema reports the constraints of obj, at any depth, that can
+// never be satisfied together (`minimum=10,maximum=5`, an enum value that
+// fails the field's own pattern, an empty enum, ...), defaults that would fail validation, and dependentRequired
+// rules naming fields that do not exist. It returns nil or a SchemaErrors.
+func CheckObjectSchema(obj *ObjectSchema) error {
+	return schemaErrors(checkObject(obj, ""))
+}
+
+func schemaErrors(errs SchemaErrors) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func checkObject(obj *ObjectSchema, path string) SchemaErrors {
+	var errs SchemaErrors
+	for _, source := range sortedKeys(obj.DependentRequired) {
+		if !obj.hasField(source) {
+			errs = append(errs, SchemaError{Path: path, Keyword: "dependentRequired", Message: fmt.Sprintf("unknown field %q", source)})
+		}
+		for _, target := range obj.DependentRequired[source] {
+			if !obj.hasField(target) {
+				errs = append(errs, SchemaError{Path: path, Keyword: "dependentRequired", Message: fmt.Sprintf("%q requires unknown field %q", source, target)})
+			}
+		}
+	}
+	for _, name := range sortedKeys(obj.Fields) {
+		errs = append(errs, checkField(obj.Fields[name], fieldPath(path, name))...)
+	}
+	return errs
+}
+
+// hasField reports whether obj has a field, or a promoted one, named name.
+func (obj *ObjectSchema) hasField(name string) bool {
+	_, ok := obj.Fields[name]
+	return ok || slices.Contains(obj.promoted, name)
+}
+
+func checkField(fs FieldSchema, path string) SchemaErrors {
+	var errs SchemaErrors
+	report := func(keyword, format string, args ...any) {
+		errs = append(errs, SchemaError{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if c := fs.String; c != nil {
+		if c.MinLength != nil && c.MaxLength != nil && *c.MinLength > *c.MaxLength {
+			report("minLength", "minLength %d is greater than maxLength %d", *c.MinLength, *c.MaxLength)
+		}
+		if c.Pattern != nil {
+			if _, err := regexp.Compile(*c.Pattern); err != nil {
+				report("pattern", "invalid regular expression: %v", err)
+			}
+		}
+		plain := *c
+		plain.Enum, plain.Const, plain.Required = nil, nil, false
+		if c.Enum != nil && len(c.Enum) == 0 {
+			report("enum", "no value is allowed (the enum is empty)")
+		}
+		for _, e := range c.Enum {
+			for _, ve := range validateString(reflect.ValueOf(e), &plain, path, newOptions(nil)) {
+				report("enum", "value %q can never be valid: %s", e, ve.Message)
+			}
+		}
+		if c.Const != nil {
+			for _, ve := range validateString(reflect.ValueOf(*c.Const), &plain, path, newOptions(nil)) {
+				report("const", "value %q can never be valid: %s", *c.Const, ve.Message)
+			}
+			if len(c.Enum) > 0 && !slices.Contains(c.Enum, *c.Const) {
+				report("const", "value %q is not in enum %v", *c.Const, c.Enum)
+			}
+		}
+	}
+
+	if c := fs.Number; c != nil {
+		errs = append(errs, checkNumber(c, fs.Type == "integer", path)...)
+	}
+
+	if c := fs.Bool; c != nil {
+		if c.Enum != nil && len(c.Enum) == 0 {
+			report("enum", "no value is allowed (the enum is empty)")
+		}
+		if c.Const != nil && len(c.Enum) > 0 && !slices.Contains(c.Enum, *c.Const) {
+			report("const", "value %v is not in enum %v", *c.Const, c.Enum)
+		}
+	}
+
+	if c := fs.Time; c != nil {
+		after, errAfter := absoluteTimeBound(c.After)
+		before, errBefore := absoluteTimeBound(c.Before)
+		if errAfter == nil && errBefore == nil && !after.Before(before) {
+			report("after", "after=%s is not earlier than before=%s", *c.After, *c.Before)
+		}
+		if c.MinAge != nil && c.MaxAge != nil {
+			minAge, err1 := parsePeriod(*c.MinAge)
+			maxAge, err2 := parsePeriod(*c.MaxAge)
+			// Compare the birth dates the two ages allow at a fixed instant.
+			ref := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+			if err1 == nil && err2 == nil && maxAge.before(ref).After(minAge.before(ref)) {
+				report("minAge", "minAge %s is greater than maxAge %s", *c.MinAge, *c.MaxAge)
+			}
+		}
+	}
+
+	if c := fs.Array; c != nil {
+		if c.MinItems != nil && c.MaxItems != nil && *c.MinItems > *c.MaxItems {
+			report("minItems", "minItems %d is greater than maxItems %d", *c.MinItems, *c.MaxItems)
+		}
+		if c.Items != nil {
+			errs = append(errs, checkField(*c.Items, path+"[]")...)
+		}
+	}
+
+	if c := fs.Map; c != nil {
+		if c.MinProperties != nil && c.MaxProperties != nil && *c.MinProperties > *c.MaxProperties {
+			report("minProperties", "minProperties %d is greater than maxProperties %d", *c.MinProperties, *c.MaxProperties)
+		}
+		if c.Values != nil {
+			errs = append(errs, checkField(*c.Values, fieldPath(path, "*"))...)
+		}
+	}
+
+	if fs.Nested != nil {
+		errs = append(errs, checkObject(fs.Nested, path)...)
+	}
+
+	if fs.Const != nil && len(fs.Enum) > 0 && !slices.ContainsFunc(fs.Enum, func(e any) bool { return reflect.DeepEqual(e, *fs.Const) }) {
+		report("const", "value %s is not in enum %s", jsonList([]any{*fs.Const}), jsonList(fs.Enum))
+	}
+
+	for _, sub := range slices.Concat(fs.AllOf, fs.AnyOf, fs.OneOf) {
+		errs = append(errs, checkField(sub, path)...)
+	}
+	if fs.Not != nil {
+		errs = append(errs, checkField(*fs.Not, path)...)
+	}
+
+	if fs.Default != nil && fs.Nested == nil {
+		errs = append(errs, checkDefault(fs, path)...)
+	}
+	return errs
+}
+
+func checkNumber(c *NumberConstraints, integer bool, path string) SchemaErrors {
+	var errs SchemaErrors
+	report := func(keyword, format string, args ...any) {
+		errs = append(errs, SchemaError{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, b := range []struct {
+		keyword string
+		f       *float64
+	}{
+		{"minimum", c.Minimum},
+		{"maximum", c.Maximum},
+		{"exclusiveMinimum", c.ExclusiveMin},
+		{"exclusiveMaximum", c.ExclusiveMax},
+		{"multipleOf", c.MultipleOf},
+	} {
+		if b.f != nil && (math.IsNaN(*b.f) || math.IsInf(*b.f, 0)) {
+			report(b.keyword, "%s must be a finite number (got %g)", b.keyword, *b.f)
+		}
+	}
+
+	// The lowest and highest allowed values, and whether they are excluded.
+	lo, loExcl := math.Inf(-1), false
+	if c.Minimum != nil {
+		lo = *c.Minimum
+	}
+	if c.ExclusiveMin != nil && *c.ExclusiveMin >= lo {
+		lo, loExcl = *c.ExclusiveMin, true
+	}
+	hi, hiExcl := math.Inf(1), false
+	if c.Maximum != nil {
+		hi = *c.Maximum
+	}
+	if c.ExclusiveMax != nil && *c.ExclusiveMax <= hi {
+		hi, hiExcl = *c.ExclusiveMax, true
+	}
+	switch {
+	case lo > hi || (lo == hi && (loExcl || hiExcl)):
+		report("minimum", "no number satisfies %s", boundsString(c))
+	case integer && !math.IsInf(lo, 0) && !math.IsInf(hi, 0):
+		first, last := math.Ceil(lo), math.Floor(hi)
+		if loExcl && first == lo {
+			first++
+		}
+		if hiExcl && last == hi {
+			last--
+		}
+		if first > last {
+			report("minimum", "no integer satisfies %s", boundsString(c))
+		}
+	}
+	if c.MultipleOf != nil && *c.MultipleOf <= 0 {
+		report("multipleOf", "multipleOf must be greater than 0 (got %g)", *c.MultipleOf)
+	}
+
+	plain := *c
+	plain.Enum, plain.Const, plain.Required = nil, nil, false
+	check := func(keyword string, f float64) {
+		if integer && f != math.Trunc(f) {
+			report(keyword, "value %g is not an integer", f)
+		}
+		for _, ve := range validateNumber(reflect.ValueOf(f), &plain, path) {
+			report(keyword, "value %g can never be valid: %s", f, ve.Message)
+		}
+	}
+	if c.Enum != nil && len(c.Enum) == 0 {
+		report("enum", "no value is allowed (the enum is empty)")
+	}
+	for _, e := range c.Enum {
+		check("enum", e)
+	}
+	if c.Const != nil {
+		check("const", *c.Const)
+		if len(c.Enum) > 0 && !slices.Contains(c.Enum, *c.Const) {
+			report("const", "value %g is not in enum %v", *c.Const, c.Enum)
+		}
+	}
+	return errs
+}
+
+// boundsString formats the bounds of c as tag options.
+func boundsString(c *NumberConstraints) string {
+	var parts []string
+	for _, b := range []struct {
+		key string
+		v   *float64
+	}{
+		{"minimum", c.Minimum},
+		{"exclusiveMinimum", c.ExclusiveMin},
+		{"maximum", c.Maximum},
+		{"exclusiveMaximum", c.ExclusiveMax},
+	} {
+		if b.v != nil {
+			parts = append(parts, fmt.Sprintf("%s=%g", b.key, *b.v))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// absoluteTimeBound parses a temporal bound that does not depend on the
+// clock; "now" and missing bounds are reported as errors.
+func absoluteTimeBound(s *string) (time.Time, error) {
+	if s == nil || *s == "now" {
+		return time.Time{}, fmt.Errorf("not an absolute bound")
+	}
+	return parseTimeBound(*s, time.Time{})
+}
+
+// checkDefault validates the default of fs against the field's own
+// constraints, as ParseJSON would after filling it in.
+func checkDefault(fs FieldSchema, path string) SchemaErrors {
+	var errs SchemaErrors
+	raw := *fs.Default
+	v, err := parseTypedValue(raw, fs.Type)
+	if err != nil {
+		return append(errs, SchemaError{Path: path, Keyword: "default", Message: fmt.Sprintf("%s is not a valid %s: %v", raw, fs.Type, err)})
+	}
+	if v == nil {
+		return errs
+	}
+	// Temporal constraints need a time.Time and the current clock; the
+	// date-time format of the string form is still checked.
+	fs.Time, fs.Default = nil, nil
+	for _, ve := range validateField(reflect.ValueOf(v), fs, path, newOptions(nil)) {
+		errs = append(errs, SchemaError{Path: path, Keyword: "default", Message: fmt.Sprintf("%s would fail validation: %s", raw, ve.Message)})
+	}
+	return errs
+}
@@ -0,0 +1,102 @@
+package schema_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Contradictory struct {
+	_       any       `schema:"dependentRequired:card=billing|ghost"`
+	Range   int       `json:"range"   schema:"minimum=10,maximum=5"`
+	Odd     int       `json:"odd"     schema:"exclusiveMinimum=1,exclusiveMaximum=2"`
+	Name    string    `json:"name"    schema:"minLength=10,maxLength=3"`
+	Code    string    `json:"code"    schema:"pattern=^[a-z]+$,enum=abc|ABC"`
+	Level   int       `json:"level"   schema:"minimum=1,maximum=5,default=9"`
+	Color   string    `json:"color"   schema:"enum=red|green,default=blue"`
+	Items   []string  `json:"items"   schema:"minItems=3,maxItems=1"`
+	Scores  []float64 `json:"scores"  schema:"items:minimum=5,items:maximum=1"`
+	Card    string    `json:"card"`
+	Billing string    `json:"billing"`
+}
+
+func TestCheck_Findings(t *testing.T) {
+	err := schema.Check[Contradictory]()
+	se, ok := err.(schema.SchemaErrors)
+	if !ok {
+		t.Fatalf("expected SchemaErrors, got %T: %v", err, err)
+	}
+	want := []string{
+		`field "": dependentRequired: "card" requires unknown field "ghost"`,
+		`field "code": enum: value "ABC" can never be valid: must match pattern`,
+		`field "color": default: blue would fail validation: must be one of [red green]`,
+		`field "items": minItems: minItems 3 is greater than maxItems 1`,
+		`field "level": default: 9 would fail validation: must be <= 5`,
+		`field "name": minLength: minLength 10 is greater than maxLength 3`,
+		`field "odd": minimum: no integer satisfies exclusiveMinimum=1,exclusiveMaximum=2`,
+		`field "range": minimum: no number satisfies minimum=10,maximum=5`,
+		`field "scores[]": minimum: no number satisfies minimum=5,maximum=1`,
+	}
+	msg := se.Error()
+	for _, w := range want {
+		if !strings.Contains(msg, w) {
+			t.Errorf("missing finding %q in:\n%s", w, strings.ReplaceAll(msg, "; ", "\n"))
+		}
+	}
+	if len(se) != len(want) {
+		t.Errorf("expected %d findings, got %d:\n%s", len(want), len(se), strings.ReplaceAll(msg, "; ", "\n"))
+	}
+}
+
+func TestCheck_CleanTypes(t *testing.T) {
+	for name, check := range map[string]func() error{
+		"User":     schema.Check[User],
+		"Account":  schema.Check[Account],
+		"Team":     schema.Check[Team],
+		"Job":      schema.Check[Job],
+		"Pipeline": schema.Check[Pipeline],
+		"Ticket":   schema.Check[Ticket],
+	} {
+		if err := check(); err != nil {
+			t.Errorf("%s: unexpected findings: %v", name, err)
+		}
+	}
+}
+
+func TestCheckObjectSchema(t *testing.T) {
+	maxAge, minAge := "18y", "21y"
+	nan := math.NaN()
+	obj := &schema.ObjectSchema{
+		Fields: map[string]schema.FieldSchema{
+			"born":  {Type: "string", Time: &schema.TimeConstraints{MinAge: &minAge, MaxAge: &maxAge}},
+			"plan":  {Type: "string", String: &schema.StringConstraints{Enum: []string{}}},
+			"score": {Type: "number", Number: &schema.NumberConstraints{Minimum: &nan}},
+		},
+		DependentRequired: map[string][]string{"nope": {"born"}},
+	}
+	err := schema.CheckObjectSchema(obj)
+	if err == nil {
+		t.Fatal("expected findings")
+	}
+	for _, w := range []string{`unknown field "nope"`, "minAge 21y is greater than maxAge 18y", "no value is allowed", "minimum must be a finite number (got NaN)"} {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("missing finding %q in %v", w, err)
+		}
+	}
+}
+
+type Impossible struct {
+	Range int `json:"range" schema:"minimum=10,maximum=5"`
+}
+
+func TestCompile_StrictChecksConsistency(t *testing.T) {
+	if _, err := schema.Compile[Impossible](); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err := schema.Compile[Impossible](schema.Strict())
+	if _, ok := err.(schema.SchemaErrors); !ok {
+		t.Errorf("expected SchemaErrors from strict compile, got %T: %v", err, err)
+	}
+}
@@ -0,0 +1,113 @@
+package schema
+
+import (
+	"os"
+	"reflect"
+)
+
+// Schema is the resolved schema of a Go type T, compiled once with
+// [Compile] so that problems in its tags surface at start-up rather than on
+// the first request.
+type Schema[T any] struct {
+	r    *resolved
+	opts []Option
+}
+
+// Compile resolves the schema of T. The options are applied to every call
+// made through the returned Schema; with [Strict], any [Lint] or [Check]
+// finding is returned as an error.
+//
+//	var userSchema = schema.MustCompile[User](schema.Strict())
+func Compile[T any](opts ...Option) (*Schema[T], error) {
+	// The schema is not shared with the cache, as FieldSchema hands it out.
+	r, err := newResolved(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	if newOptions(opts).strict {
+		if err := Lint[T](); err != nil {
+			return nil, err
+		}
+		if err := schemaErrors(checkField(r.fs, "")); err != nil {
+			return nil, err
+		}
+	}
+	return &Schema[T]{r: r, opts: opts}, nil
+}
+
+// MustCompile is like [Compile] but panics on error. Intended for
+// package-level schema variables.
+func MustCompile[T any](opts ...Option) *Schema[T] {
+	s, err := Compile[T](opts...)
+	if err != nil {
+		panic("goschema: MustCompile failed: " + err.Error())
+	}
+	return s
+}
+
+// FieldSchema returns the resolved schema of T.
+func (s *Schema[T]) FieldSchema() FieldSchema {
+	return s.r.fs
+}
+
+// Validate is [Validate] with the compiled options, followed by opts.
+func (s *Schema[T]) Validate(v T, opts ...Option) error {
+	return Validate(v, s.options(opts)...)
+}
+
+// ParseJSON is [ParseJSON] with the compiled options, followed by opts.
+func (s *Schema[T]) ParseJSON(data []byte, opts ...Option) (T, error) {
+	opts = s.options(opts)
+	var v T
+	var err error
+	if o := newOptions(opts); o.presence || s.r.decodes {
+		v, err = decodeJSON[T](s.r.plan, data, o)
+	} else {
+		v, err = parseJSON[T](data, s.r, o)
+	}
+	return v, locateErrors(err, data)
+}
+
+// ParseJSONFile is [ParseJSONFile] with the compiled options, followed by
+// opts.
+func (s *Schema[T]) ParseJSONFile(path string, opts ...Option) (T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	v, err := s.ParseJSON(data, opts...)
+	return v, inFile(err, path)
+}
+
+// DecodeJSON is [DecodeJSON] with the compiled options, followed by opts.
+// The decoding plan is built once, by Compile.
+func (s *Schema[T]) DecodeJSON(data []byte, opts ...Option) (T, error) {
+	v, err := decodeJSON[T](s.r.plan, data, newOptions(s.options(opts)))
+	return v, locateErrors(err, data)
+}
+
+// ParseJSONPartial is [ParseJSONPartial] with the compiled options,
+// followed by opts.
+func (s *Schema[T]) ParseJSONPartial(data []byte, opts ...Option) (T, Paths, error) {
+	v, paths, err := decodePaths[T](s.r.plan, data, partialOptions(s.options(opts)))
+	return v, paths, locateErrors(err, data)
+}
+
+// PruneJSON is [PruneJSON] with the compiled options, followed by opts.
+func (s *Schema[T]) PruneJSON(data []byte, opts ...Option) ([]byte, PruneReport, error) {
+	return pruneJSON[T](s.r.plan, data, newOptions(s.options(opts)))
+}
+
+// JSONSchema returns the JSON Schema representation of T, like
+// [ToJSONSchema].
+func (s *Schema[T]) JSONSchema() map[string]any {
+	return fieldSchemaToJSON(s.r.fs)
+}
+
+func (s *Schema[T]) options(opts []Option) []Option {
+	if len(opts) == 0 {
+		return s.opts
+	}
+	return append(s.opts[:len(s.opts):len(s.opts)], opts...)
+}
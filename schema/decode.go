@@ -0,0 +1,744 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DecodeJSON is a single-pass alternative to [ParseJSON]: it tokenizes data
+// once, guided by the schema of T, and populates the value, fills in
+// `default=` values and checks constraints as it goes. The result is the
+// same as ParseJSON's, which decodes invalid input a second time to
+// collect its problems, and every problem is reported at once: type
+// mismatches, every unknown field (in objects with
+// `additionalProperties=false`), supplied readOnly fields and constraint
+// violations, in a single ValidationErrors, with the position of each
+// value in data. Only malformed JSON stops decoding early.
+//
+// Values that encoding/json decodes by custom means (json.Unmarshaler and
+// encoding.TextUnmarshaler types, interfaces, structs with embedded fields
+// or `,string` options) are handed to encoding/json and validated as a
+// whole. Generated [Validator] methods are not used.
+//
+//	user, err := schema.DecodeJSON[User](data)
+func DecodeJSON[T any](data []byte, opts ...Option) (T, error) {
+	r, err := resolve(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	v, err := decodeJSON[T](r.plan, data, newOptions(opts))
+	return v, locateErrors(err, data)
+}
+
+// decodeJSON decodes data into a new T with a compiled plan.
+func decodeJSON[T any](plan *decodePlan, data []byte, o *options) (T, error) {
+	v, _, err := decodePaths[T](plan, data, o)
+	return v, err
+}
+
+// decodePaths is decodeJSON that also returns, with o.partial, the paths
+// present in data.
+func decodePaths[T any](plan *decodePlan, data []byte, o *options) (T, Paths, error) {
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+
+	d := &decoder{jsonParser: jsonParser{data: data}, o: o, partial: o.partial}
+	if o.partial {
+		d.paths = Paths{}
+	}
+	d.skipSpace()
+	errs, err := d.decode(rv, plan, "", defaultsSet)
+	if err != nil {
+		return v, nil, syntaxErrors(err)
+	}
+	errs = append(d.errs, errs...)
+	if o.rejectDuplicates {
+		errs = append(duplicateKeys(data, plan), errs...)
+	}
+
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return v, d.paths, ValidationErrors{{Field: "", Message: "value is nil", Value: nil}}
+		}
+		rv = rv.Elem()
+	}
+	if len(errs) > 0 {
+		return v, d.paths, errs
+	}
+	return v, d.paths, nil
+}
+
+// syntaxErrors converts an error of the tokenizer into ValidationErrors,
+// worded like the errors wrapUnmarshalError makes of encoding/json's.
+func syntaxErrors(err error) error {
+	var syntaxErr *jsonSyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return err
+	}
+	if syntaxErr.msg == "unexpected end of JSON input" {
+		return ValidationErrors{{Field: "", Message: "invalid JSON: unexpected end of input"}}
+	}
+	return ValidationErrors{{
+		Field:   "",
+		Message: fmt.Sprintf("invalid JSON syntax at offset %d: %s", syntaxErr.offset+1, syntaxErr.msg),
+	}}
+}
+
+// planKind is how the decoder populates a value.
+type planKind uint8
+
+const (
+	planJSON planKind = iota // delegated to encoding/json
+	planString
+	planInt
+	planUint
+	planFloat
+	planBool
+	planPtr
+	planSlice
+	planArray
+	planMap
+	planStruct
+)
+
+// decodePlan is the compiled form of a Go type and its schema that drives
+// the decoder.
+type decodePlan struct {
+	kind planKind
+	typ  reflect.Type
+
+	// fs is the schema of the value; own is fs without the schemas of the
+	// items, map values or fields, which are checked as they are decoded,
+	// and without dependentRequired, which is checked with the fields.
+	fs  FieldSchema
+	own FieldSchema
+	// check is whether own has anything to check: values without
+	// constraints of their own are not handed to validateField.
+	check bool
+
+	elem   *decodePlan    // pointer target, item or map value
+	fields []planField    // struct fields, in declaration order
+	names  map[string]int // index in fields by JSON name
+	extra  []int          // index of the field collecting additional properties
+}
+
+// planField is a struct field in a decodePlan.
+type planField struct {
+	name  string
+	index int
+	plan  *decodePlan
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	jsonNumberType      = reflect.TypeOf(json.Number(""))
+)
+
+// buildDecodePlan compiles the plan of a value of type t with schema fs.
+func buildDecodePlan(t reflect.Type, fs FieldSchema) *decodePlan {
+	p := &decodePlan{typ: t, fs: fs, own: fs}
+	if t.Kind() != reflect.Ptr && (reflect.PointerTo(t).Implements(jsonUnmarshalerType) ||
+		reflect.PointerTo(t).Implements(textUnmarshalerType) || t == jsonNumberType) {
+		return p
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		p.kind = planString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		p.kind = planInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		p.kind = planUint
+	case reflect.Float32, reflect.Float64:
+		p.kind = planFloat
+	case reflect.Bool:
+		p.kind = planBool
+	case reflect.Ptr:
+		p.kind = planPtr
+		p.elem = buildDecodePlan(t.Elem(), fs)
+	case reflect.Slice, reflect.Array:
+		// encoding/json decodes []byte from base64 text, not from an array.
+		if fs.Type != "array" || fs.Array == nil || fs.Array.Items == nil ||
+			(t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8) {
+			return p
+		}
+		p.kind = planSlice
+		if t.Kind() == reflect.Array {
+			p.kind = planArray
+		}
+		p.elem = buildDecodePlan(t.Elem(), *fs.Array.Items)
+		own := *fs.Array
+		own.Items = nil
+		p.own.Array = &own
+	case reflect.Map:
+		if fs.Type != "object" || fs.Map == nil || fs.Map.Values == nil ||
+			t.Key().Kind() != reflect.String || reflect.PointerTo(t.Key()).Implements(textUnmarshalerType) {
+			return p
+		}
+		p.kind = planMap
+		p.elem = buildDecodePlan(t.Elem(), *fs.Map.Values)
+		own := *fs.Map
+		own.Values = nil
+		p.own.Map = &own
+	case reflect.Struct:
+		if fs.Type != "object" || fs.Nested == nil {
+			return p
+		}
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if f.Anonymous || strings.Contains(f.Tag.Get("json"), ",string") {
+				// Promoted fields and quoted values are left to encoding/json.
+				return p
+			}
+		}
+		p.kind = planStruct
+		p.names = make(map[string]int)
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name := jsonFieldName(f)
+			if name == "-" {
+				continue
+			}
+			if _, dup := p.names[name]; !dup {
+				p.names[name] = len(p.fields)
+			}
+			p.fields = append(p.fields, planField{name: name, index: i, plan: buildDecodePlan(f.Type, fs.Nested.Fields[name])})
+		}
+		if f, ok := t.FieldByName(fs.Nested.ExtraField); ok {
+			p.extra = f.Index
+		}
+		own := *fs.Nested
+		own.Fields, own.DependentRequired = nil, nil
+		p.own.Nested = &own
+	}
+	p.check = p.kind == planPtr || hasChecks(p.own)
+	return p
+}
+
+// hasChecks reports whether validateField can find a violation in a
+// decoded value with schema fs, other than in its items, map values or
+// fields.
+func hasChecks(fs FieldSchema) bool {
+	if fs.Required || fs.Default != nil || fs.Enum != nil || fs.Const != nil ||
+		fs.Not != nil || len(fs.AllOf) > 0 || len(fs.AnyOf) > 0 || len(fs.OneOf) > 0 {
+		return true
+	}
+	for _, c := range []any{fs.String, fs.Number, fs.Bool, fs.Time, fs.Array, fs.Map} {
+		if v := reflect.ValueOf(c); !v.IsNil() && !v.Elem().IsZero() {
+			return true
+		}
+	}
+	return fs.Nested != nil && (fs.Nested.Fields != nil || fs.Nested.DependentRequired != nil ||
+		fs.Nested.AdditionalProperties != nil || fs.Nested.ExtraField != "")
+}
+
+// field returns the index of the field that encoding/json would decode the
+// JSON key into: an exact name match, or else a case-insensitive one.
+func (p *decodePlan) field(key string) (int, bool) {
+	if i, ok := p.names[key]; ok {
+		return i, true
+	}
+	for i, f := range p.fields {
+		if strings.EqualFold(f.name, key) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// defaultsMode tells which `default=` values ParseJSON would apply to a
+// value. applyFieldDefaults only sets settable values, so map values, and
+// the struct fields below them, keep their zero value.
+type defaultsMode uint8
+
+const (
+	defaultsSet   defaultsMode = iota // settable: the value and its contents get defaults
+	defaultsFixed                     // map value: only pointer targets and slice items below it get defaults
+	defaultsNone                      // never visited
+)
+
+// elem returns the mode of the target of a pointer with mode m. Nil
+// pointers get no default, but the target of any other one is settable.
+func (m defaultsMode) elem() defaultsMode {
+	if m == defaultsNone {
+		return defaultsNone
+	}
+	return defaultsSet
+}
+
+// item returns the mode of the items of a slice or array with mode m.
+func (m defaultsMode) item(k planKind) defaultsMode {
+	if m == defaultsNone || (k == planArray && m == defaultsFixed) {
+		return m
+	}
+	return defaultsSet
+}
+
+// value returns the mode of the values of a map with mode m.
+func (m defaultsMode) value() defaultsMode {
+	if m == defaultsNone {
+		return defaultsNone
+	}
+	return defaultsFixed
+}
+
+// field returns the mode of the fields of a struct with mode m.
+func (m defaultsMode) field() defaultsMode {
+	if m == defaultsSet {
+		return defaultsSet
+	}
+	return defaultsNone
+}
+
+// applyDefaults fills in the defaults of a value the decoder did not walk
+// itself, as applyFieldDefaults would in ParseJSON.
+func applyDefaults(v reflect.Value, fs FieldSchema, m defaultsMode) {
+	switch m {
+	case defaultsSet:
+		applyFieldDefaults(v, fs)
+	case defaultsFixed:
+		// A copy is not settable, but shares what pointers, slices and
+		// maps refer to, like the values returned by MapIndex.
+		if v.Kind() != reflect.Interface || !v.IsNil() {
+			applyFieldDefaults(reflect.ValueOf(v.Interface()), fs)
+		}
+	}
+}
+
+// decoder is the state of a single DecodeJSON call.
+type decoder struct {
+	jsonParser
+	o *options
+
+	// partial is set while decoding the members of objects in partial
+	// mode, where missing keys are left alone, and paths collects the
+	// paths of the members present. Array items are complete values.
+	partial bool
+	paths   Paths
+
+	// errs holds the problems met while decoding, in document order:
+	// values of the wrong type, unknown and readOnly fields. Values that
+	// fail to decode are not checked against their schema.
+	errs ValidationErrors
+}
+
+// decode decodes the value at the current position into v, following p,
+// and returns the constraint violations found in it. The error is only set
+// for malformed JSON, which ends decoding.
+func (d *decoder) decode(v reflect.Value, p *decodePlan, path string, m defaultsMode) (ValidationErrors, error) {
+	c, err := d.peek()
+	if err != nil {
+		return nil, err
+	}
+	if c == 'n' {
+		if _, err := d.literal(); err != nil {
+			return nil, err
+		}
+		switch p.kind {
+		case planJSON:
+			return d.decodeJSON(v, p, path, m, []byte("null")), nil
+		case planPtr, planSlice, planMap:
+			v.SetZero()
+		case planStruct:
+			if d.o.presence {
+				// encoding/json leaves the struct alone: none of its
+				// keys are present.
+				return d.fields(v, p, path, m.field(), make([]ValidationErrors, len(p.fields)), make([]bool, len(p.fields))), nil
+			}
+		}
+		return d.whole(v, p, path, m), nil
+	}
+
+	// ParseJSON applies the default of a zero container before those of
+	// its contents, so the contents of a container with a default are
+	// filled in by finish, once the container is known to be non-zero.
+	// With Presence, the value is present and never gets its default.
+	inner := m
+	if m == defaultsSet && p.fs.Default != nil && !d.o.presence {
+		inner = defaultsNone
+	}
+
+	var errs ValidationErrors
+	switch p.kind {
+	case planJSON:
+		start := d.pos
+		if err := d.skip(); err != nil {
+			return nil, err
+		}
+		return d.decodeJSON(v, p, path, m, d.data[start:d.pos]), nil
+
+	case planString:
+		if c != '"' {
+			return d.mismatch(v.Type(), path, c)
+		}
+		s, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.ValidString(s) {
+			// encoding/json replaces each invalid byte.
+			s = string([]rune(s))
+		}
+		v.SetString(s)
+
+	case planInt, planUint, planFloat:
+		if c != '-' && (c < '0' || c > '9') {
+			return d.mismatch(v.Type(), path, c)
+		}
+		start := d.pos
+		if err := d.number(); err != nil {
+			return nil, err
+		}
+		if !setNumber(v, p.kind, string(d.data[start:d.pos])) {
+			d.errs = append(d.errs, ValidationError{
+				Field:   path,
+				Message: fmt.Sprintf("expected type %s", v.Type()),
+				Keyword: "type",
+				Value:   "number " + string(d.data[start:d.pos]),
+			})
+			return nil, nil
+		}
+
+	case planBool:
+		if c != 't' && c != 'f' {
+			return d.mismatch(v.Type(), path, c)
+		}
+		if _, err := d.literal(); err != nil {
+			return nil, err
+		}
+		v.SetBool(c == 't')
+
+	case planPtr:
+		if v.IsNil() {
+			v.Set(reflect.New(p.typ.Elem()))
+		}
+		// The target is checked with the same schema as the pointer.
+		return d.decode(v.Elem(), p.elem, path, m.elem())
+
+	case planSlice, planArray:
+		if c != '[' {
+			return d.mismatch(v.Type(), path, c)
+		}
+		if errs, err = d.decodeArray(v, p, path, inner); err != nil {
+			return nil, err
+		}
+
+	case planMap:
+		if c != '{' {
+			return d.mismatch(v.Type(), path, c)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(p.typ))
+		}
+		key := reflect.New(p.typ.Key()).Elem()
+		err := d.object(func(k string, _ int) error {
+			e := reflect.New(p.elem.typ).Elem()
+			if d.partial {
+				d.paths[fieldPath(path, k)] = struct{}{}
+			}
+			es, err := d.decode(e, p.elem, fieldPath(path, k), inner.value())
+			errs = append(errs, es...)
+			key.SetString(k)
+			v.SetMapIndex(key, e)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+	case planStruct:
+		if c != '{' {
+			return d.mismatch(v.Type(), path, c)
+		}
+		if errs, err = d.decodeStruct(v, p, path, inner); err != nil {
+			return nil, err
+		}
+	}
+	return d.finish(v, p, path, m, errs), nil
+}
+
+// decodeArray decodes a JSON array into a slice or array and returns the
+// problems found in its items.
+func (d *decoder) decodeArray(v reflect.Value, p *decodePlan, path string, m defaultsMode) (ValidationErrors, error) {
+	var errs ValidationErrors
+	im := m.item(p.kind)
+	defer func(partial bool) { d.partial = partial }(d.partial)
+	d.partial = false
+	s := v
+	if p.kind == planSlice {
+		s = reflect.MakeSlice(p.typ, 0, 0)
+	}
+	n := 0
+	err := d.array(func(i int) error {
+		if p.kind == planArray && i >= s.Len() {
+			return d.skip()
+		}
+		if p.kind == planSlice {
+			s = reflect.Append(s, reflect.Zero(p.elem.typ))
+		}
+		n++
+		es, err := d.decode(s.Index(i), p.elem, fmt.Sprintf("%s[%d]", path, i), im)
+		errs = append(errs, es...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if p.kind == planSlice {
+		v.Set(s)
+		return errs, nil
+	}
+	// encoding/json zeroes the items missing from the input.
+	for i := n; i < v.Len(); i++ {
+		item := v.Index(i)
+		item.SetZero()
+		errs = append(errs, d.whole(item, p.elem, fmt.Sprintf("%s[%d]", path, i), im)...)
+	}
+	return errs, nil
+}
+
+// decodeStruct decodes a JSON object into a struct and returns the
+// problems found in its fields, in declaration order.
+func (d *decoder) decodeStruct(v reflect.Value, p *decodePlan, path string, m defaultsMode) (ValidationErrors, error) {
+	fieldErrs := make([]ValidationErrors, len(p.fields))
+	seen := make([]bool, len(p.fields))
+	fm := m.field()
+
+	err := d.object(func(key string, _ int) error {
+		i, ok := p.field(key)
+		if !ok {
+			return d.additional(v, p, path, key)
+		}
+		f := &p.fields[i]
+		fp := fieldPath(path, f.name)
+		if f.plan.fs.ReadOnly && d.o.readOnly != AllowReadOnly {
+			if d.o.readOnly == RejectReadOnly {
+				d.errs = append(d.errs, ValidationError{Field: fp, Message: "field is read-only", Keyword: "readOnly"})
+			}
+			return d.skip()
+		}
+		if d.partial {
+			d.paths[fp] = struct{}{}
+		}
+		es, err := d.decode(v.Field(f.index), f.plan, fp, fm)
+		fieldErrs[i], seen[i] = es, true
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d.fields(v, p, path, fm, fieldErrs, seen), nil
+}
+
+// additional decodes a member that matches no field of the struct into
+// the field collecting additional properties, if there is one. Otherwise
+// the member is skipped, and reported with `additionalProperties=false`.
+func (d *decoder) additional(v reflect.Value, p *decodePlan, path, key string) error {
+	fp := fieldPath(path, key)
+	if p.extra == nil {
+		if ap := p.fs.Nested.AdditionalProperties; ap != nil && !*ap {
+			d.errs = append(d.errs, ValidationError{Field: fp, Message: fmt.Sprintf("unknown field %q", key), Keyword: "additionalProperties"})
+		}
+		return d.skip()
+	}
+	start := d.pos
+	if err := d.skip(); err != nil {
+		return err
+	}
+	m := v.FieldByIndex(p.extra)
+	ev := reflect.New(m.Type().Elem())
+	if !d.unmarshal(ev, fp, d.data[start:d.pos], false) {
+		return nil
+	}
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	m.SetMapIndex(reflect.ValueOf(key).Convert(m.Type().Key()), ev.Elem())
+	if d.partial {
+		d.paths[fp] = struct{}{}
+	}
+	return nil
+}
+
+// fields completes a struct once its JSON object is decoded: the fields
+// that were not seen are handled as absent, and the problems of
+// dependentRequired and of every field are returned in declaration order.
+// In partial mode, missing keys and dependentRequired are not checked.
+func (d *decoder) fields(v reflect.Value, p *decodePlan, path string, fm defaultsMode, fieldErrs []ValidationErrors, seen []bool) ValidationErrors {
+	if d.partial {
+		var errs ValidationErrors
+		for _, fe := range fieldErrs {
+			errs = append(errs, fe...)
+		}
+		return errs
+	}
+	for i, f := range p.fields {
+		if seen[i] {
+			continue
+		}
+		fv, fp := v.Field(f.index), fieldPath(path, f.name)
+		if d.o.presence {
+			fieldErrs[i] = d.absent(fv, f.plan, fp, fm)
+		} else {
+			fieldErrs[i] = d.whole(fv, f.plan, fp, fm)
+		}
+	}
+
+	present := func(name string) bool { return isPresent(v, p.fs.Nested, name) }
+	if d.o.presence {
+		present = func(name string) bool {
+			i, ok := p.names[name]
+			return ok && seen[i]
+		}
+	}
+	errs := checkDependentRequired(p.fs.Nested.DependentRequired, path, present)
+	for _, fe := range fieldErrs {
+		errs = append(errs, fe...)
+	}
+	return errs
+}
+
+// absent handles a field whose key is missing, with Presence: a required
+// field is reported, and any other gets its defaults and is only checked if
+// a default was set for the field itself.
+func (d *decoder) absent(v reflect.Value, p *decodePlan, path string, m defaultsMode) ValidationErrors {
+	if p.fs.Required {
+		return ValidationErrors{{Field: path, Message: "field is required", Value: nil, Keyword: "required"}}
+	}
+	applyDefaults(v, p.fs, m)
+	if m == defaultsSet && p.fs.Default != nil {
+		return validateField(v, p.fs, path, d.o)
+	}
+	return nil
+}
+
+// finish returns the constraint violations of a decoded value: those of its
+// own schema followed by inner, the violations found in its contents.
+// Values with a default are filled in and checked afresh instead.
+func (d *decoder) finish(v reflect.Value, p *decodePlan, path string, m defaultsMode, inner ValidationErrors) ValidationErrors {
+	if m == defaultsSet && p.fs.Default != nil && !d.o.presence {
+		return d.whole(v, p, path, m)
+	}
+	if !p.check {
+		return inner
+	}
+	switch p.kind {
+	case planSlice, planArray, planMap, planStruct:
+		return append(validateField(v, p.own, path, d.o), inner...)
+	}
+	return validateField(v, p.fs, path, d.o)
+}
+
+// whole applies the defaults of a value that the decoder did not walk, an
+// absent field or one decoded by encoding/json, and checks it as a whole.
+// With Presence, such values are present and get no defaults.
+func (d *decoder) whole(v reflect.Value, p *decodePlan, path string, m defaultsMode) ValidationErrors {
+	if !d.o.presence {
+		applyDefaults(v, p.fs, m)
+	}
+	return validateField(v, p.fs, path, d.o)
+}
+
+// decodeJSON decodes raw into v with encoding/json, then applies defaults
+// and checks the result as ParseJSON would.
+func (d *decoder) decodeJSON(v reflect.Value, p *decodePlan, path string, m defaultsMode, raw []byte) ValidationErrors {
+	// encoding/json can only be strict about unknown keys for the whole
+	// value, so the value's own additionalProperties applies throughout.
+	closed := p.fs.Nested != nil && p.fs.Nested.AdditionalProperties != nil && !*p.fs.Nested.AdditionalProperties
+	if !d.unmarshal(v.Addr(), path, raw, closed) {
+		return nil
+	}
+	if d.o.presence {
+		// encoding/json does not tell which keys it saw, so the value
+		// is handled as without Presence.
+		o := *d.o
+		o.presence = false
+		applyDefaults(v, p.fs, m)
+		return validateField(v, p.fs, path, &o)
+	}
+	return d.whole(v, p, path, m)
+}
+
+// unmarshal decodes raw into the value ptr points to with encoding/json,
+// and reports whether it succeeded. Errors are recorded at their path
+// under path.
+func (d *decoder) unmarshal(ptr reflect.Value, path string, raw []byte, disallowUnknown bool) bool {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if disallowUnknown {
+		dec.DisallowUnknownFields()
+	}
+	err := dec.Decode(ptr.Interface())
+	if err == nil {
+		return true
+	}
+	ve, ok := wrapUnmarshalError(err).(ValidationErrors)
+	if !ok {
+		ve = ValidationErrors{{Field: "", Message: err.Error(), Value: string(raw)}}
+	}
+	for i := range ve {
+		if ve[i].Field == "" {
+			ve[i].Field = path
+		} else {
+			ve[i].Field = fieldPath(path, ve[i].Field)
+		}
+	}
+	d.errs = append(d.errs, ve...)
+	return false
+}
+
+// mismatch skips the value at the current position, which starts with c,
+// and records that it cannot be decoded into a value of type t.
+func (d *decoder) mismatch(t reflect.Type, path string, c byte) (ValidationErrors, error) {
+	if err := d.skip(); err != nil {
+		return nil, err
+	}
+	got := "number"
+	switch c {
+	case '{':
+		got = "object"
+	case '[':
+		got = "array"
+	case '"':
+		got = "string"
+	case 't', 'f':
+		got = "bool"
+	}
+	d.errs = append(d.errs, ValidationError{Field: path, Message: fmt.Sprintf("expected type %s", t), Value: got, Keyword: "type"})
+	return nil, nil
+}
+
+// setNumber sets v to the number literal s, reporting false if it does not
+// fit, as encoding/json does.
+func setNumber(v reflect.Value, kind planKind, s string) bool {
+	switch kind {
+	case planInt:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v.OverflowInt(n) {
+			return false
+		}
+		v.SetInt(n)
+	case planUint:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil || v.OverflowUint(n) {
+			return false
+		}
+		v.SetUint(n)
+	default:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil || v.OverflowFloat(f) {
+			return false
+		}
+		v.SetFloat(f)
+	}
+	return true
+}
@@ -0,0 +1,259 @@
+package schema_test
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Shipment struct {
+	_ struct{} `schema:"dependentRequired:insured=value"`
+
+	ID       string             `json:"id"       schema:"format=uuid,readOnly"`
+	Carrier  string             `json:"carrier"  schema:"enum=ups|dhl,default=ups"`
+	Weight   float64            `json:"weight"   schema:"exclusiveMinimum=0,maximum=70"`
+	Pieces   int8               `json:"pieces"   schema:"minimum=1,default=1"`
+	Insured  bool               `json:"insured"`
+	Value    *uint32            `json:"value"    schema:"maximum=10000"`
+	Tags     []string           `json:"tags"     schema:"maxItems=3,uniqueItems,items:minLength=2"`
+	Dims     [3]int             `json:"dims"     schema:"items:minimum=1"`
+	Parcels  []Parcel           `json:"parcels"  schema:"minItems=1"`
+	ByCode   map[string]Parcel  `json:"byCode"   schema:"maxProperties=2"`
+	ByRef    map[string]*Parcel `json:"byRef"`
+	Notes    map[string]string  `json:"notes"    schema:"default={\"via\":\"api\"}"`
+	Origin   *Place             `json:"origin"   schema:"required"`
+	Dest     Place              `json:"dest"`
+	Pickup   time.Time          `json:"pickup"`
+	Window   time.Duration      `json:"window"   schema:"maximum=2h,default=1h"`
+	Meta     any                `json:"meta"`
+	Stamped  Stamped            `json:"stamped"`
+	Fallback Place              `json:"fallback" schema:"default={\"city\":\"Rome\"}"`
+}
+
+type Parcel struct {
+	SKU string `json:"sku" schema:"required,minLength=3"`
+	Qty int    `json:"qty" schema:"minimum=1,default=1"`
+}
+
+type Place struct {
+	City string `json:"city" schema:"required"`
+	Zip  string `json:"zip"  schema:"pattern=^[0-9]{5}$,default=00000"`
+}
+
+// Stamped embeds a struct, which the decoder leaves to encoding/json.
+type Stamped struct {
+	Place
+	At string `json:"at" schema:"minLength=2"`
+}
+
+// sortedErrors returns the errors of err, or nil, in a stable order.
+func sortedErrors(t *testing.T, err error) schema.ValidationErrors {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	ve := slices.Clone(mustValidationErrors(t, err))
+	slices.SortStableFunc(ve, func(a, b schema.ValidationError) int {
+		return cmp.Or(cmp.Compare(a.Field, b.Field), cmp.Compare(a.Message, b.Message))
+	})
+	return ve
+}
+
+func TestDecodeJSON_MatchesParseJSON(t *testing.T) {
+	inputs := map[string]string{
+		"valid": `{"carrier":"dhl","weight":2.5,"pieces":2,"tags":["ab","cd"],"dims":[1,2,3],
+			"parcels":[{"sku":"abc","qty":2}],"byCode":{"x":{"sku":"abc"}},"byRef":{"y":{"sku":"def"}},
+			"origin":{"city":"Milan","zip":"20100"},"dest":{"city":"Turin"},"pickup":"2024-01-01T10:00:00Z",
+			"window":3600000000000,"meta":{"a":[1,"b"]},"stamped":{"city":"Pisa","at":"noon"}}`,
+		"empty":    `{}`,
+		"defaults": `{"parcels":[{"sku":"abc"},{"sku":"def","qty":0}],"byCode":{"x":{"sku":"ghi"}},"byRef":{"y":{"sku":"jkl"}},"dims":[1]}`,
+		"constraints": `{"carrier":"fedex","weight":0,"pieces":0,"insured":true,"value":20000,
+			"tags":["a","bb","bb","cc"],"dims":[0,5,0],"parcels":[],"byCode":{"a":{},"b":{"sku":"x"},"c":{"sku":"abcd"}},
+			"origin":{"zip":"abc"},"dest":{"zip":"1"},"window":36000000000000,"stamped":{"at":"x"}}`,
+		"nulls":         `{"value":null,"tags":null,"origin":null,"dest":null,"notes":null,"meta":null,"parcels":[null]}`,
+		"case folding":  `{"CARRIER":"dhl","Origin":{"City":"Bari"}}`,
+		"unknown keys":  `{"nope":1,"origin":{"city":"Bari","extra":true}}`,
+		"zero notes":    `{"notes":{},"fallback":{}}`,
+		"not an object": `[1,2]`,
+		"null":          `null`,
+	}
+	for name, in := range inputs {
+		t.Run(name, func(t *testing.T) {
+			want, wantErr := schema.ParseJSON[Shipment]([]byte(in))
+			got, gotErr := schema.DecodeJSON[Shipment]([]byte(in))
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("decoded values differ\ngot:  %+v\nwant: %+v", got, want)
+			}
+			if g, w := sortedErrors(t, gotErr), sortedErrors(t, wantErr); !reflect.DeepEqual(g, w) {
+				t.Errorf("errors differ\ngot:  %v\nwant: %v", g, w)
+			}
+		})
+	}
+}
+
+func TestDecodeJSON_ReportsEverything(t *testing.T) {
+	in := `{"id":"x","carrier":7,"weight":"heavy","pieces":300,"tags":["ok",5],
+		"origin":{"city":true},"dest":{"city":"Como","zip":"1"},"stamped":{"at":1}}`
+	_, err := schema.DecodeJSON[Shipment]([]byte(in))
+	ve := mustValidationErrors(t, err)
+
+	want := []string{
+		`id: field is read-only`,
+		`carrier: expected type string`,
+		`weight: expected type float64`,
+		`pieces: expected type int8`,
+		`tags[1]: expected type string`,
+		`origin.city: expected type string`,
+		`stamped.at: expected type string`,
+		`dest.zip: must match pattern "^[0-9]{5}$"`,
+	}
+	var got []string
+	for _, e := range ve {
+		got = append(got, e.Field+": "+e.Message)
+	}
+	for _, w := range want {
+		if !slices.Contains(got, w) {
+			t.Errorf("missing error %q in:\n%s", w, strings.Join(got, "\n"))
+		}
+	}
+	for _, e := range ve {
+		if e.Field == "pieces" && e.Value != "number 300" {
+			t.Errorf("expected the overflowing literal as value, got %v", e.Value)
+		}
+		if (e.Field == "carrier" || e.Field == "weight" || e.Field == "origin.city") && strings.HasPrefix(e.Message, "must") {
+			t.Errorf("a field that failed to decode was validated: %v", e)
+		}
+	}
+}
+
+func TestDecodeJSON_AdditionalProperties(t *testing.T) {
+	type Closed struct {
+		_    struct{} `schema:"additionalProperties=false"`
+		Name string   `json:"name"`
+		Sub  Place    `json:"sub"`
+	}
+	// Nested objects follow their own additionalProperties.
+	_, err := schema.DecodeJSON[Closed]([]byte(`{"name":"a","x":1,"sub":{"city":"Rome","y":2},"z":3}`))
+	ve := mustValidationErrors(t, err)
+	if len(ve) != 2 || ve[0].Field != "x" || ve[1].Field != "z" {
+		t.Errorf("expected the unknown root fields, got %v", ve)
+	}
+	if ve[0].Message != `unknown field "x"` {
+		t.Errorf("unexpected message: %q", ve[0].Message)
+	}
+}
+
+func TestDecodeJSON_ReadOnlyPolicies(t *testing.T) {
+	in := []byte(`{"id":"m1","name":"ann"}`)
+
+	_, err := schema.DecodeJSON[Member](in)
+	ve := mustValidationErrors(t, err)
+	if len(ve) != 1 || ve[0].Field != "id" || ve[0].Message != "field is read-only" {
+		t.Errorf("expected the read-only id to be rejected, got %v", ve)
+	}
+
+	m, err := schema.DecodeJSON[Member](in, schema.WithReadOnly(schema.AllowReadOnly))
+	assertNoError(t, err)
+	if m.ID != "m1" {
+		t.Errorf("AllowReadOnly should decode the id, got %q", m.ID)
+	}
+
+	m, err = schema.DecodeJSON[Member](in, schema.WithReadOnly(schema.DropReadOnly))
+	assertNoError(t, err)
+	if m.ID != "" || m.Name != "ann" {
+		t.Errorf("DropReadOnly should drop the id only, got %+v", m)
+	}
+}
+
+func TestDecodeJSON_SyntaxErrors(t *testing.T) {
+	for in, want := range map[string]string{
+		``:             "invalid JSON: unexpected end of input",
+		`{"id":`:       "invalid JSON: unexpected end of input",
+		`{"id" "x"}`:   "invalid JSON syntax at offset 7: invalid character '\"' after object key",
+		`{"tags":[1,]`: "invalid JSON syntax at offset 12: invalid character ']' looking for beginning of value",
+	} {
+		_, err := schema.DecodeJSON[Shipment]([]byte(in))
+		ve := mustValidationErrors(t, err)
+		if len(ve) != 1 || ve[0].Message != want {
+			t.Errorf("%q: got %v, want %q", in, ve, want)
+		}
+	}
+}
+
+func TestDecodeJSON_Pointer(t *testing.T) {
+	p, err := schema.DecodeJSON[*Place]([]byte(`{"city":"Rome"}`))
+	assertNoError(t, err)
+	if p == nil || p.City != "Rome" || p.Zip != "00000" {
+		t.Errorf("unexpected value: %+v", p)
+	}
+
+	_, err = schema.DecodeJSON[*Place]([]byte(`null`))
+	ve := mustValidationErrors(t, err)
+	if ve[0].Message != "value is nil" {
+		t.Errorf("unexpected error: %v", ve)
+	}
+}
+
+func TestDecodeJSON_Compiled(t *testing.T) {
+	s := schema.MustCompile[Place]()
+	p, err := s.DecodeJSON([]byte(`{"city":"Rome"}`))
+	assertNoError(t, err)
+	if p.Zip != "00000" {
+		t.Errorf("expected the default zip, got %q", p.Zip)
+	}
+	_, err = s.DecodeJSON([]byte(`{"zip":"x"}`))
+	ve := mustValidationErrors(t, err)
+	assertHasField(t, ve, "city")
+	assertHasField(t, ve, "zip")
+}
+
+func TestDecodeJSON_Bytes(t *testing.T) {
+	type Blob struct {
+		Data []byte `json:"data" schema:"maxItems=4"`
+	}
+	// []byte is base64 text in JSON, as encoding/json has it.
+	b, err := schema.DecodeJSON[Blob]([]byte(`{"data":"aGk="}`))
+	assertNoError(t, err)
+	if string(b.Data) != "hi" {
+		t.Errorf("unexpected data %q", b.Data)
+	}
+	_, err = schema.DecodeJSON[Blob]([]byte(`{"data":"aGVsbG8="}`))
+	assertHasField(t, mustValidationErrors(t, err), "data")
+}
+
+var benchShipment = []byte(`{"carrier":"dhl","weight":2.5,"pieces":2,"tags":["ab","cd"],"dims":[1,2,3],
+	"parcels":[{"sku":"abc","qty":2},{"sku":"def"},{"sku":"ghi","qty":4}],
+	"byCode":{"x":{"sku":"abc","qty":1}},"origin":{"city":"Milan","zip":"20100"},"dest":{"city":"Turin"},
+	"pickup":"2024-01-01T10:00:00Z","notes":{"door":"back","floor":"3"},"stamped":{"city":"Pisa"}}`)
+
+func BenchmarkDecode(b *testing.B) {
+	if _, err := schema.DecodeJSON[Shipment](benchShipment); err != nil {
+		b.Fatal(err)
+	}
+	compiled := schema.MustCompile[Shipment]()
+	for _, bm := range []struct {
+		name string
+		fn   func([]byte) (Shipment, error)
+	}{
+		{"ParseJSON", func(d []byte) (Shipment, error) { return schema.ParseJSON[Shipment](d) }},
+		{"DecodeJSON", func(d []byte) (Shipment, error) { return schema.DecodeJSON[Shipment](d) }},
+		{"Compiled/ParseJSON", func(d []byte) (Shipment, error) { return compiled.ParseJSON(d) }},
+		{"Compiled/DecodeJSON", func(d []byte) (Shipment, error) { return compiled.DecodeJSON(d) }},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(benchShipment)))
+			for b.Loop() {
+				if _, err := bm.fn(benchShipment); err != nil {
+					b.Fatal(fmt.Sprint(err))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type RetryPolicy struct {
+	Retries int    `json:"retries" schema:"minimum=0"`
+	Backoff string `json:"backoff" schema:"default=linear"`
+}
+
+type Pipeline struct {
+	Stages  []string          `json:"stages"  schema:"default=[\"build\",\"test\"]"`
+	Env     map[string]string `json:"env"     schema:"default={\"CI\":\"true\"}"`
+	Retry   RetryPolicy       `json:"retry"   schema:"default={\"retries\":3}"`
+	Limits  *RetryPolicy      `json:"limits"  schema:"default={\"retries\":1,\"backoff\":\"none\"}"`
+	Weights [2]float64        `json:"weights" schema:"default=[0.5,0.5]"`
+	Extra   any               `json:"extra"   schema:"default={\"a\":[1,2]}"`
+	Workers uint8             `json:"workers" schema:"default=4"`
+}
+
+func TestDefault_Structured(t *testing.T) {
+	p, err := schema.ParseJSON[Pipeline]([]byte(`{}`))
+	assertNoError(t, err)
+
+	if strings.Join(p.Stages, ",") != "build,test" {
+		t.Errorf("unexpected stages: %v", p.Stages)
+	}
+	if p.Env["CI"] != "true" {
+		t.Errorf("unexpected env: %v", p.Env)
+	}
+	// Nested defaults still apply inside a struct default.
+	if p.Retry.Retries != 3 || p.Retry.Backoff != "linear" {
+		t.Errorf("unexpected retry: %+v", p.Retry)
+	}
+	if p.Limits != nil {
+		t.Errorf("expected nil limits, got %+v", p.Limits)
+	}
+	if p.Weights != [2]float64{0.5, 0.5} {
+		t.Errorf("unexpected weights: %v", p.Weights)
+	}
+	if m, ok := p.Extra.(map[string]any); !ok || len(m["a"].([]any)) != 2 {
+		t.Errorf("unexpected extra: %v", p.Extra)
+	}
+	if p.Workers != 4 {
+		t.Errorf("unexpected workers: %d", p.Workers)
+	}
+}
+
+type Knob struct {
+	Level *int `json:"level" schema:"nullable,default=5"`
+}
+
+func TestDefault_NilPointers(t *testing.T) {
+	// A nil pointer is a missing or null value, which defaults leave alone.
+	for name, parse := range map[string]func([]byte) (Knob, error){
+		"ParseJSON":  func(b []byte) (Knob, error) { return schema.ParseJSON[Knob](b) },
+		"DecodeJSON": func(b []byte) (Knob, error) { return schema.DecodeJSON[Knob](b) },
+		"Presence":   func(b []byte) (Knob, error) { return schema.ParseJSON[Knob](b, schema.Presence()) },
+	} {
+		for _, in := range []string{`{"level":null}`, `{}`} {
+			k, err := parse([]byte(in))
+			assertNoError(t, err)
+			if k.Level != nil {
+				t.Errorf("%s %s: expected a nil level, got %d", name, in, *k.Level)
+			}
+		}
+	}
+
+	// The target of a pointer gets the default like any value.
+	for name, parse := range map[string]func([]byte) (Pipeline, error){
+		"ParseJSON":  func(b []byte) (Pipeline, error) { return schema.ParseJSON[Pipeline](b) },
+		"DecodeJSON": func(b []byte) (Pipeline, error) { return schema.DecodeJSON[Pipeline](b) },
+	} {
+		p, err := parse([]byte(`{"limits":{}}`))
+		assertNoError(t, err)
+		if p.Limits == nil || p.Limits.Retries != 1 || p.Limits.Backoff != "none" {
+			t.Errorf("%s: unexpected limits: %+v", name, p.Limits)
+		}
+	}
+}
+
+func TestDefault_NotShared(t *testing.T) {
+	a, err := schema.ParseJSON[Pipeline]([]byte(`{}`))
+	assertNoError(t, err)
+	a.Stages[0] = "mutated"
+	a.Env["CI"] = "false"
+
+	b, err := schema.ParseJSON[Pipeline]([]byte(`{}`))
+	assertNoError(t, err)
+	if b.Stages[0] != "build" || b.Env["CI"] != "true" {
+		t.Errorf("defaults must not be shared between values: %v %v", b.Stages, b.Env)
+	}
+}
+
+func TestDefault_NotOverridden(t *testing.T) {
+	p, err := schema.ParseJSON[Pipeline]([]byte(`{"stages":["lint"],"retry":{"retries":5}}`))
+	assertNoError(t, err)
+	if len(p.Stages) != 1 || p.Stages[0] != "lint" || p.Retry.Retries != 5 {
+		t.Errorf("explicit values must win over defaults: %+v", p)
+	}
+}
+
+type BadSliceDefault struct {
+	Ports []int `json:"ports" schema:"default=[\"http\"]"`
+}
+
+type BadStructDefault struct {
+	Retry RetryPolicy `json:"retry" schema:"default={\"attempts\":3}"`
+}
+
+type BadUintDefault struct {
+	N uint `json:"n" schema:"default=-1"`
+}
+
+func TestDefault_MustFitType(t *testing.T) {
+	if _, err := schema.ToJSONSchema[BadSliceDefault](); err == nil {
+		t.Error("expected error for string items in an []int default")
+	}
+	if _, err := schema.ToJSONSchema[BadStructDefault](); err == nil {
+		t.Error("expected error for unknown field in a struct default")
+	}
+	if _, err := schema.ParseJSON[BadUintDefault]([]byte(`{}`)); err == nil {
+		t.Error("expected error for negative default on an unsigned field")
+	}
+}
@@ -0,0 +1,81 @@
+package schema
+
+import "fmt"
+
+// RejectDuplicateKeys makes [ParseJSON], [ValidateJSON], [DecodeJSON] and
+// [ParseJSONPartial] reject objects that repeat a key, at any depth.
+// encoding/json silently keeps the last value of a duplicated key, so a
+// proxy that checks the first one can be made to let through a value that
+// the service behind it never validated.
+//
+// Keys that match the same field count as duplicates, since the field
+// names of structs are matched case-insensitively: {"name":"a","NAME":"b"}
+// is rejected. Each duplicate is reported at the path of its field and
+// located at its own value, with the other problems found in the input.
+func RejectDuplicateKeys() Option {
+	return func(o *options) {
+		o.rejectDuplicates = true
+	}
+}
+
+// duplicateKeys reports the keys of the objects in data that repeat an
+// earlier key of their object, as decoded with p. Malformed input is left
+// to the decoder to report.
+func duplicateKeys(data []byte, p *decodePlan) ValidationErrors {
+	tree, err := parseJSONTree(data)
+	if err != nil {
+		return nil
+	}
+	var errs ValidationErrors
+	findDuplicates(tree, p, "", &errs)
+	// Every duplicate has the path of the same field, so they are located
+	// here, at their own values, rather than by path.
+	for i := range errs {
+		errs[i].setOffset(data, errs[i].Offset)
+	}
+	return errs
+}
+
+// findDuplicates adds the duplicated keys of the objects in n to errs. p is
+// the plan n is decoded with, or nil for values that the decoder doesn't
+// walk, whose keys only repeat when they are equal.
+func findDuplicates(n *jsonNode, p *decodePlan, path string, errs *ValidationErrors) {
+	for p != nil && p.kind == planPtr {
+		p = p.elem
+	}
+	switch n.kind {
+	case jsonObject:
+		// Fields are seen by index, other keys by name.
+		seen := make(map[any]bool, len(n.members))
+		for _, m := range n.members {
+			var key any = m.key
+			member, next := fieldPath(path, m.key), (*decodePlan)(nil)
+			switch {
+			case p != nil && p.kind == planStruct:
+				if i, ok := p.field(m.key); ok {
+					key, member, next = i, fieldPath(path, p.fields[i].name), p.fields[i].plan
+				}
+			case p != nil && p.kind == planMap:
+				next = p.elem
+			}
+			if seen[key] {
+				*errs = append(*errs, ValidationError{
+					Field:   member,
+					Message: fmt.Sprintf("duplicate key %q", m.key),
+					Keyword: "duplicateKeys",
+					Offset:  m.value.offset,
+				})
+			}
+			seen[key] = true
+			findDuplicates(m.value, next, member, errs)
+		}
+	case jsonArray:
+		var elem *decodePlan
+		if p != nil && (p.kind == planSlice || p.kind == planArray) {
+			elem = p.elem
+		}
+		for i, item := range n.items {
+			findDuplicates(item, elem, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	}
+}
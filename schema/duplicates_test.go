@@ -0,0 +1,105 @@
+package schema_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+func TestRejectDuplicateKeys(t *testing.T) {
+	data := []byte(`{"name":"abc","listeners":[{"host":"a","port":1,"host":"b"}],"labels":{"k":{"host":"h","port":1},"k":{"host":"h","port":1}},"name":"x"}`)
+	for name, parse := range map[string]func([]byte, ...schema.Option) error{
+		"ParseJSON":    func(b []byte, o ...schema.Option) error { _, err := schema.ParseJSON[Deployment](b, o...); return err },
+		"ValidateJSON": func(b []byte, o ...schema.Option) error { return schema.ValidateJSON[Deployment](b, o...) },
+		"DecodeJSON":   func(b []byte, o ...schema.Option) error { _, err := schema.DecodeJSON[Deployment](b, o...); return err },
+		"Partial": func(b []byte, o ...schema.Option) error {
+			_, _, err := schema.ParseJSONPartial[Deployment](b, o...)
+			return err
+		},
+		"Compiled": func(b []byte, o ...schema.Option) error {
+			_, err := schema.MustCompile[Deployment](schema.RejectDuplicateKeys()).ParseJSON(b, o...)
+			return err
+		},
+	} {
+		ve := mustValidationErrors(t, parse(data, schema.RejectDuplicateKeys()))
+		var got []string
+		for _, e := range ve {
+			got = append(got, e.Field+": "+e.Message)
+		}
+		// The last value is still the one decoded and validated.
+		want := []string{
+			`listeners[0].host: duplicate key "host"`,
+			`labels.k: duplicate key "k"`,
+			`name: duplicate key "name"`,
+			"name: must be at least 3 characters long (got 1)",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+		if ve[0].Keyword != "duplicateKeys" {
+			t.Errorf("%s: unexpected keyword %q", name, ve[0].Keyword)
+		}
+		if ve[2].Line != 1 || ve[2].Offset != bytes.LastIndex(data, []byte(`"x"`)) {
+			t.Errorf("%s: expected the duplicate to point at the last value, got %+v", name, ve[2])
+		}
+	}
+
+	_, err := schema.ParseJSON[Deployment](data)
+	if ve := mustValidationErrors(t, err); len(ve) != 1 {
+		t.Errorf("expected duplicates to be allowed by default, got %v", ve)
+	}
+}
+
+func TestRejectDuplicateKeys_Fields(t *testing.T) {
+	// Keys matching the same field are duplicates, whatever their case.
+	_, err := schema.ParseJSON[Listener]([]byte(`{"host":"a","HOST":"b","port":1}`), schema.RejectDuplicateKeys())
+	ve := mustValidationErrors(t, err)
+	if len(ve) != 1 || ve[0].Field != "host" || ve[0].Message != `duplicate key "HOST"` {
+		t.Errorf("expected a duplicate host, got %v", ve)
+	}
+
+	// Additional properties and values left to encoding/json compare keys
+	// as they are.
+	_, err = schema.ParseJSON[Event]([]byte(`{"type":"t","x":1,"X":2,"meta":{"a":"1","a":"2"}}`), schema.RejectDuplicateKeys())
+	ve = mustValidationErrors(t, err)
+	if len(ve) != 1 || ve[0].Field != "meta.a" {
+		t.Errorf("expected a duplicate meta.a, got %v", ve)
+	}
+	_, err = schema.ParseJSON[map[string]any]([]byte(`{"a":{"b":1,"b":2}}`), schema.RejectDuplicateKeys())
+	if ve := mustValidationErrors(t, err); len(ve) != 1 || ve[0].Field != "a.b" {
+		t.Errorf("expected a duplicate a.b, got %v", ve)
+	}
+}
+
+func TestRejectDuplicateKeys_Positions(t *testing.T) {
+	// Each repetition points at its own value, not at the one decoded.
+	data := []byte("{\"host\":\"a\",\n\"host\":\"b\",\n\"host\":\"c\",\"port\":1}")
+	_, err := schema.ParseJSON[Listener](data, schema.RejectDuplicateKeys())
+	ve := mustValidationErrors(t, err)
+	if len(ve) != 2 {
+		t.Fatalf("expected two duplicates, got %v", ve)
+	}
+	for i, want := range []string{`"b"`, `"c"`} {
+		if ve[i].Offset != bytes.Index(data, []byte(want)) || ve[i].Line != i+2 || ve[i].Column != 8 {
+			t.Errorf("expected duplicate %d at %s, got %+v", i, want, ve[i])
+		}
+	}
+}
+
+func TestRejectDuplicateKeys_Render(t *testing.T) {
+	data := []byte("{\n  \"host\": \"a\",\n  \"host\": \"b\",\n  \"port\": 1\n}")
+	_, err := schema.ParseJSON[Listener](data, schema.RejectDuplicateKeys())
+	got := mustValidationErrors(t, err).Render(data, schema.RenderOptions{})
+	want := `error: field "host": duplicate key "host"
+ --> 3:11
+  |
+3 |   "host": "b",
+  |           ^^^ duplicateKeys
+  = hint: remove the repeated keys, so that the object has one value for the field
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,141 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var enumerType = reflect.TypeOf((*Enumer)(nil)).Elem()
+
+// Enumer is implemented by named types whose valid values are a closed set
+// of constants, so that every field of that type gets an `enum` without
+// repeating it in tags.
+//
+//	type Status string
+//
+//	const (
+//		StatusActive  Status = "active"
+//		StatusRetired Status = "retired"
+//	)
+//
+//	func (Status) EnumValues() []any { return []any{StatusActive, StatusRetired} }
+//
+// String-, number- and bool-backed types are supported, as are types whose custom
+// marshaller produces strings or numbers; values are compared on their JSON
+// form.
+type Enumer interface {
+	EnumValues() []any
+}
+
+var (
+	enumsMu sync.RWMutex
+	enums   = map[reflect.Type][]any{}
+)
+
+// RegisterEnum declares the allowed values of T. It is the counterpart of
+// [Enumer] for types you cannot add methods to, or that prefer to keep the
+// list next to the constants:
+//
+//	schema.RegisterEnum(StatusActive, StatusRetired)
+func RegisterEnum[T any](values ...T) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	list := make([]any, len(values))
+	for i, v := range values {
+		list[i] = v
+	}
+	enumsMu.Lock()
+	enums[t] = list
+	enumsMu.Unlock()
+	forgetResolved()
+}
+
+// enumValues returns the declared values of t, if any.
+func enumValues(t reflect.Type) ([]any, bool) {
+	enumsMu.RLock()
+	values, ok := enums[t]
+	enumsMu.RUnlock()
+	if ok {
+		return values, true
+	}
+	if implements(t, enumerType) {
+		return interfaceValue(reflect.New(t).Elem(), enumerType).(Enumer).EnumValues(), true
+	}
+	return nil, false
+}
+
+// enumSchema adds the enum declared by type t (through Enumer or
+// RegisterEnum) to its base schema. Field tags can narrow it further.
+func enumSchema(t reflect.Type, fs FieldSchema) (FieldSchema, error) {
+	values, ok := enumValues(t)
+	if !ok {
+		return fs, nil
+	}
+	var err error
+
+	switch fs.Type {
+	case "string":
+		enum := make([]string, 0, len(values))
+		for _, v := range values {
+			rv := jsonForm(v)
+			if rv.Kind() != reflect.String {
+				return fs, fmt.Errorf("goschema: type %s: enum value %v is not a string", t, v)
+			}
+			enum = append(enum, rv.String())
+		}
+		sc := StringConstraints{}
+		if fs.String != nil {
+			sc = *fs.String
+		}
+		if sc.Enum, err = intersectEnum(sc.Enum, enum); err != nil {
+			return fs, fmt.Errorf("goschema: type %s: %w", t, err)
+		}
+		fs.String = &sc
+	case "integer", "number":
+		enum := make([]float64, 0, len(values))
+		for _, v := range values {
+			n, ok := numberOf(jsonForm(v))
+			if !ok {
+				return fs, fmt.Errorf("goschema: type %s: enum value %v is not a number", t, v)
+			}
+			enum = append(enum, n.float())
+		}
+		nc := NumberConstraints{}
+		if fs.Number != nil {
+			nc = *fs.Number
+		}
+		if nc.Enum, err = intersectEnum(nc.Enum, enum); err != nil {
+			return fs, fmt.Errorf("goschema: type %s: %w", t, err)
+		}
+		fs.Number = &nc
+	case "boolean":
+		enum := make([]bool, 0, len(values))
+		for _, v := range values {
+			rv := jsonForm(v)
+			if rv.Kind() != reflect.Bool {
+				return fs, fmt.Errorf("goschema: type %s: enum value %v is not a boolean", t, v)
+			}
+			enum = append(enum, rv.Bool())
+		}
+		bc := BoolConstraints{}
+		if fs.Bool != nil {
+			bc = *fs.Bool
+		}
+		if bc.Enum, err = intersectEnum(bc.Enum, enum); err != nil {
+			return fs, fmt.Errorf("goschema: type %s: %w", t, err)
+		}
+		fs.Bool = &bc
+	default:
+		return fs, fmt.Errorf("goschema: type %s: enums are only supported on string, number and boolean types", t)
+	}
+	return fs, nil
+}
+
+// jsonForm returns the reflect.Value of v as it appears in JSON.
+func jsonForm(v any) reflect.Value {
+	rv := reflect.ValueOf(v)
+	if w, ok := wireValue(rv, ""); ok {
+		return w
+	}
+	return rv
+}
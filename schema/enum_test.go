@@ -0,0 +1,197 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Status string
+
+const (
+	StatusActive  Status = "active"
+	StatusPending Status = "pending"
+	StatusRetired Status = "retired"
+)
+
+func (Status) EnumValues() []any { return []any{StatusActive, StatusPending, StatusRetired} }
+
+type Priority int
+
+const (
+	PriorityLow Priority = iota + 1
+	PriorityMedium
+	PriorityHigh
+)
+
+type Level string
+
+func init() {
+	schema.RegisterEnum(PriorityLow, PriorityMedium, PriorityHigh)
+	schema.RegisterEnum[Level]("debug", "info", "warn")
+}
+
+type Ticket struct {
+	Status   Status   `json:"status"   schema:"required"`
+	Open     Status   `json:"open"     schema:"enum=active|pending"`
+	Priority Priority `json:"priority"`
+	Levels   []Level  `json:"levels"`
+}
+
+func TestEnum_Discovered(t *testing.T) {
+	assertNoError(t, schema.Validate(Ticket{Status: StatusActive, Priority: PriorityHigh, Levels: []Level{"info"}}))
+
+	ve := mustValidationErrors(t, schema.Validate(Ticket{Status: "deleted", Priority: 7, Levels: []Level{"trace"}}))
+	assertHasField(t, ve, "status")
+	assertHasField(t, ve, "priority")
+	assertHasField(t, ve, "levels[0]")
+}
+
+func TestEnum_FieldTagNarrows(t *testing.T) {
+	ve := mustValidationErrors(t, schema.Validate(Ticket{Status: StatusActive, Open: StatusRetired}))
+	assertHasField(t, ve, "open")
+}
+
+type Plan string
+
+func (Plan) EnumValues() []any { return []any{"free", "pro"} }
+
+type Subscription struct {
+	Plan Plan `json:"plan" schema:"enum=enterprise"`
+}
+
+// EmptyEnum is a type whose own schema allows no value.
+type EmptyEnum string
+
+func (EmptyEnum) GoSchema() schema.FieldSchema {
+	return schema.FieldSchema{Type: "string", String: &schema.StringConstraints{Enum: []string{}}}
+}
+
+func TestEnum_NoOverlap(t *testing.T) {
+	// A tag enum that shares no value with the type's would reject every
+	// value, so the schema fails to build.
+	err := schema.Validate(Subscription{Plan: "whatever"})
+	if err == nil || !strings.Contains(err.Error(), "shares no value") {
+		t.Errorf("expected a schema error, got %v", err)
+	}
+	if _, err := schema.ParseJSON[Subscription]([]byte(`{"plan":"free"}`)); err == nil {
+		t.Error("expected ParseJSON to fail on the schema")
+	}
+
+	// An empty enum provided by the type rejects every value.
+	ve := mustValidationErrors(t, schema.Validate(struct {
+		E EmptyEnum `json:"e"`
+	}{E: "whatever"}))
+	if len(ve) != 1 || ve[0].Field != "e" || ve[0].Keyword != "enum" {
+		t.Errorf("expected the value to be rejected, got %v", ve)
+	}
+}
+
+type Shade string
+
+type Swatch struct {
+	Shade Shade `json:"shade"`
+}
+
+func TestEnum_RegisteredAfterUse(t *testing.T) {
+	// The schema of Swatch is cached by the first call, and forgotten
+	// when the enum of Shade is registered.
+	assertNoError(t, schema.Validate(Swatch{Shade: "mauve"}))
+	schema.RegisterEnum[Shade]("light", "dark")
+
+	ve := mustValidationErrors(t, schema.Validate(Swatch{Shade: "mauve"}))
+	assertHasField(t, ve, "shade")
+	_, err := schema.ParseJSON[Swatch]([]byte(`{"shade":"mauve"}`))
+	assertHasField(t, mustValidationErrors(t, err), "shade")
+}
+
+func TestEnum_ToJSONSchema(t *testing.T) {
+	js, err := schema.ToJSONSchema[Ticket]()
+	assertNoError(t, err)
+	props := js["properties"].(map[string]any)
+
+	if enum, _ := props["status"].(map[string]any)["enum"].([]string); len(enum) != 3 {
+		t.Errorf("expected 3 status values, got %v", props["status"])
+	}
+	priority := props["priority"].(map[string]any)
+	if enum, _ := priority["enum"].([]float64); len(enum) != 3 || enum[0] != 1 {
+		t.Errorf("expected typed priority enum [1 2 3], got %v", priority["enum"])
+	}
+}
+
+type BadEnum struct{ X int }
+
+func (BadEnum) EnumValues() []any { return []any{BadEnum{X: 1}} }
+
+type WithBadEnum struct {
+	B BadEnum `json:"b"`
+}
+
+func TestEnum_UnsupportedType(t *testing.T) {
+	if _, err := schema.ToJSONSchema[WithBadEnum](); err == nil {
+		t.Error("expected error for enum on an object type")
+	}
+}
+
+// ---- typed enum/const in tags ----
+
+type TypedEnums struct {
+	Retries int      `json:"retries" schema:"enum=1|2|5"`
+	Ratio   float64  `json:"ratio"   schema:"enum=0.5|1.5"`
+	Flag    bool     `json:"flag"    schema:"enum=true"`
+	Any     any      `json:"any"     schema:"enum=1|\"a\"|true|[1,2]"`
+	Pair    []int    `json:"pair"    schema:"const=[1,2]"`
+	Point   Point    `json:"point"   schema:"enum={\"x\":0,\"y\":0}|{\"x\":1,\"y\":1}"`
+	Tags    []string `json:"tags"    schema:"enum=[\"a\"]|[\"a\",\"b\"],minItems=1"`
+}
+
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+func TestTypedEnum_Valid(t *testing.T) {
+	v := TypedEnums{Retries: 5, Ratio: 1.5, Flag: true, Any: "a", Pair: []int{1, 2}, Point: Point{1, 1}, Tags: []string{"a", "b"}}
+	assertNoError(t, schema.Validate(v))
+
+	v.Any = []any{1.0, 2.0}
+	assertNoError(t, schema.Validate(v))
+}
+
+func TestTypedEnum_Invalid(t *testing.T) {
+	v := TypedEnums{Retries: 3, Ratio: 1, Any: "b", Pair: []int{2, 1}, Point: Point{1, 0}, Tags: []string{"b"}}
+	ve := mustValidationErrors(t, schema.Validate(v))
+	for _, f := range []string{"retries", "ratio", "flag", "any", "pair", "point", "tags"} {
+		assertHasField(t, ve, f)
+	}
+}
+
+func TestTypedEnum_ToJSONSchema(t *testing.T) {
+	js, err := schema.ToJSONSchema[TypedEnums]()
+	assertNoError(t, err)
+	props := js["properties"].(map[string]any)
+
+	if enum, _ := props["retries"].(map[string]any)["enum"].([]float64); len(enum) != 3 || enum[2] != 5 {
+		t.Errorf("expected numeric enum, got %v", props["retries"])
+	}
+	if enum, _ := props["flag"].(map[string]any)["enum"].([]bool); len(enum) != 1 || !enum[0] {
+		t.Errorf("expected boolean enum, got %v", props["flag"])
+	}
+	if enum, _ := props["any"].(map[string]any)["enum"].([]any); len(enum) != 4 || enum[1] != "a" {
+		t.Errorf("expected heterogeneous enum, got %v", props["any"])
+	}
+	if c, _ := props["pair"].(map[string]any)["const"].([]any); len(c) != 2 {
+		t.Errorf("expected array const, got %v", props["pair"])
+	}
+}
+
+type BadNumberEnum struct {
+	N int `json:"n" schema:"enum=one|two"`
+}
+
+func TestTypedEnum_InvalidTag(t *testing.T) {
+	if _, err := schema.ToJSONSchema[BadNumberEnum](); err == nil {
+		t.Error("expected error for non-numeric enum on an int field")
+	}
+}
@@ -1,19 +1,42 @@
 package schema
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"strings"
 )
 
 // ValidationError represents a single field-level validation failure.
+//
+// Errors found in JSON input also locate the value in the source: its byte
+// Offset, and the Line and Column (in bytes) it starts at, both 1-based.
+// Problems with missing fields point at the enclosing object, and syntax
+// errors at the offending byte. Line is 0 when the position is unknown, as
+// with [Validate], which only sees Go values.
 type ValidationError struct {
 	Field   string // JSON field path (e.g. "address.street")
 	Message string // Human-readable reason
 	Value   any    // The value that failed validation
+	Keyword string // The schema keyword that failed (e.g. "minLength"), if any
+
+	File   string // source file, set by ParseJSONFile
+	Line   int    // 1-based line of the value, or 0 if unknown
+	Column int    // 1-based column of the value, in bytes
+	Offset int    // byte offset of the value
 }
 
+// Error formats the error, prefixed with "file:line:column: " when it comes
+// from a file.
 func (e ValidationError) Error() string {
-	return fmt.Sprintf("field %q: %s (got %v)", e.Field, e.Message, e.Value)
+	msg := fmt.Sprintf("field %q: %s (got %v)", e.Field, e.Message, e.Value)
+	switch {
+	case e.File != "" && e.Line > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, msg)
+	case e.File != "":
+		return e.File + ": " + msg
+	}
+	return msg
 }
 
 // ValidationErrors is a collection of ValidationError returned when one or
@@ -38,3 +61,38 @@ func (ve ValidationErrors) Has(field string) bool {
 	}
 	return false
 }
+
+// locateErrors sets the position in data of the ValidationErrors in err,
+// the JSON input they were found in. The values are looked up by path, so
+// that errors found in Go values after decoding are located too. Errors
+// located where they were found, like duplicate keys, are left alone.
+func locateErrors(err error, data []byte) error {
+	ve, ok := err.(ValidationErrors)
+	if !ok {
+		return err
+	}
+	tree, perr := parseJSONTree(data)
+	var syntaxErr *jsonSyntaxError
+	for i := range ve {
+		switch {
+		case ve[i].Line > 0:
+			// Located already, where it was found.
+		case perr == nil:
+			ve[i].setOffset(data, tree.lookup(ve[i].Field).offset)
+		case errors.As(perr, &syntaxErr):
+			// The only error found in malformed input is the syntax
+			// error.
+			ve[i].setOffset(data, syntaxErr.offset)
+		}
+	}
+	return ve
+}
+
+// setOffset sets the position of e to the byte offset in data.
+func (e *ValidationError) setOffset(data []byte, offset int) {
+	offset = min(offset, len(data))
+	before := data[:offset]
+	e.Offset = offset
+	e.Line = bytes.Count(before, []byte{'\n'}) + 1
+	e.Column = offset - (bytes.LastIndexByte(before, '\n') + 1) + 1
+}
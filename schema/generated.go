@@ -0,0 +1,69 @@
+package schema
+
+import "reflect"
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// Validator is implemented by types with a validator generated by
+// `goschema gen-validators`. [Validate] calls ValidateSchema instead of
+// walking the value with reflection; the generated code reports exactly the
+// same errors.
+type Validator interface {
+	ValidateSchema() error
+}
+
+// DefaultApplier is implemented by types with a default applier generated
+// by `goschema gen-validators`. [ParseJSON] calls ApplySchemaDefaults to
+// fill in `default=` values instead of walking the value with reflection.
+type DefaultApplier interface {
+	ApplySchemaDefaults()
+}
+
+// Reflective makes [Validate] and [ParseJSON] ignore generated
+// ValidateSchema and ApplySchemaDefaults methods and always use the
+// reflective engine. It is mostly useful to check generated code against
+// it in tests.
+func Reflective() Option {
+	return func(o *options) {
+		o.reflective = true
+	}
+}
+
+// generatedValidator returns the generated validator of the value held in
+// rv, if its type has one. Values are copied so that pointer receivers can
+// be called.
+func generatedValidator(rv reflect.Value) (Validator, bool) {
+	if !reflect.PointerTo(rv.Type()).Implements(validatorType) {
+		return nil, false
+	}
+	p := reflect.New(rv.Type())
+	p.Elem().Set(rv)
+	return p.Interface().(Validator), true
+}
+
+// MatchFormat reports whether s is valid for the named `format` keyword
+// ("email", "uuid", ...). Unknown formats match anything, as in
+// validation. Generated validators use it so that formats are defined in a
+// single place.
+func MatchFormat(format, s string) bool {
+	re, ok := formatPatterns[format]
+	return !ok || re.MatchString(s)
+}
+
+// NumberValue returns the number n as the Value of a ValidationError: a
+// float64, or n itself for integers that a float64 can't hold exactly.
+// Generated validators use it so that their errors carry the same values as
+// those of validation.
+func NumberValue[N int64 | uint64 | float64](n N) any {
+	switch v := any(n).(type) {
+	case int64:
+		if v >= -1<<53 && v <= 1<<53 {
+			return float64(v)
+		}
+	case uint64:
+		if v <= 1<<53 {
+			return float64(v)
+		}
+	}
+	return n
+}
@@ -0,0 +1,63 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+// Handwritten stands in for a type with generated methods: its validator
+// reports a marker error and its default applier sets a marker value.
+type Handwritten struct {
+	Name string `json:"name" schema:"required,default=reflective"`
+}
+
+func (h *Handwritten) ValidateSchema() error {
+	if h.Name == "generated" {
+		return nil
+	}
+	return schema.ValidationErrors{{Field: "name", Message: "generated", Value: h.Name}}
+}
+
+func (h *Handwritten) ApplySchemaDefaults() {
+	if h.Name == "" {
+		h.Name = "generated"
+	}
+}
+
+func TestGenerated_ValidateDispatches(t *testing.T) {
+	for _, v := range []any{Handwritten{}, &Handwritten{}} {
+		ve := mustValidationErrors(t, schema.Validate(v))
+		if len(ve) != 1 || ve[0].Message != "generated" {
+			t.Errorf("%T: expected the generated validator to run, got %v", v, ve)
+		}
+	}
+
+	ve := mustValidationErrors(t, schema.Validate(Handwritten{}, schema.Reflective()))
+	if len(ve) != 1 || ve[0].Message != "field is required" {
+		t.Errorf("expected the reflective engine to run, got %v", ve)
+	}
+}
+
+func TestGenerated_ParseJSONDispatches(t *testing.T) {
+	h, err := schema.ParseJSON[Handwritten]([]byte(`{}`))
+	assertNoError(t, err)
+	if h.Name != "generated" {
+		t.Errorf("expected the generated default applier to run, got %q", h.Name)
+	}
+
+	h, err = schema.ParseJSON[Handwritten]([]byte(`{}`), schema.Reflective())
+	assertNoError(t, err)
+	if h.Name != "reflective" {
+		t.Errorf("expected the tag default to be applied, got %q", h.Name)
+	}
+}
+
+func TestMatchFormat(t *testing.T) {
+	if !schema.MatchFormat("email", "a@b.co") || schema.MatchFormat("email", "nope") {
+		t.Error("email format mismatch")
+	}
+	if !schema.MatchFormat("no-such-format", "anything") {
+		t.Error("unknown formats should match anything")
+	}
+}
@@ -0,0 +1,527 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// jsonKind is the kind of a jsonNode.
+type jsonKind uint8
+
+const (
+	jsonNull jsonKind = iota
+	jsonBool
+	jsonNumber
+	jsonString
+	jsonArray
+	jsonObject
+)
+
+// jsonNode is a parsed JSON value. Unlike the values produced by
+// encoding/json, it keeps object members in source order (duplicates
+// included) together with their byte offsets, so that documents can be
+// inspected against a schema and re-encoded without reordering keys.
+type jsonNode struct {
+	kind    jsonKind
+	offset  int          // byte offset of the value in the source
+	raw     []byte       // literal text of null, booleans, numbers and strings
+	str     string       // decoded value of strings
+	members []jsonMember // object members, in source order
+	items   []*jsonNode  // array items
+}
+
+// jsonMember is a key/value pair of a JSON object.
+type jsonMember struct {
+	key    string
+	offset int // byte offset of the key in the source
+	value  *jsonNode
+}
+
+// jsonSyntaxError reports malformed JSON at a byte offset.
+type jsonSyntaxError struct {
+	msg    string
+	offset int
+}
+
+func (e *jsonSyntaxError) Error() string { return e.msg }
+
+// maxJSONDepth mirrors the nesting limit of encoding/json.
+const maxJSONDepth = 10000
+
+// parseJSONTree parses the first JSON value in data. Like json.Decoder, it
+// ignores anything after that value.
+func parseJSONTree(data []byte) (*jsonNode, error) {
+	p := &jsonParser{data: data}
+	p.skipSpace()
+	return p.value()
+}
+
+// jsonParser is a JSON tokenizer over a byte slice. Objects and arrays are
+// walked with callbacks, so that the same code builds trees, skips values
+// and drives the schema-aware decoder.
+type jsonParser struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (p *jsonParser) errorf(format string, args ...any) error {
+	return &jsonSyntaxError{msg: fmt.Sprintf(format, args...), offset: p.pos}
+}
+
+func (p *jsonParser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the first byte of the value at the current position.
+func (p *jsonParser) peek() (byte, error) {
+	if p.pos >= len(p.data) {
+		return 0, p.errorf("unexpected end of JSON input")
+	}
+	return p.data[p.pos], nil
+}
+
+func (p *jsonParser) value() (*jsonNode, error) {
+	c, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+	start := p.pos
+	switch {
+	case c == '{':
+		n := &jsonNode{kind: jsonObject, offset: start}
+		err := p.object(func(key string, offset int) error {
+			v, err := p.value()
+			if err != nil {
+				return err
+			}
+			n.members = append(n.members, jsonMember{key: key, offset: offset, value: v})
+			return nil
+		})
+		return n, err
+	case c == '[':
+		n := &jsonNode{kind: jsonArray, offset: start}
+		err := p.array(func(int) error {
+			v, err := p.value()
+			if err != nil {
+				return err
+			}
+			n.items = append(n.items, v)
+			return nil
+		})
+		return n, err
+	case c == '"':
+		s, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{kind: jsonString, offset: start, raw: p.data[start:p.pos], str: s}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		if err := p.number(); err != nil {
+			return nil, err
+		}
+		return &jsonNode{kind: jsonNumber, offset: start, raw: p.data[start:p.pos]}, nil
+	case c == 't' || c == 'f' || c == 'n':
+		kind, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{kind: kind, offset: start, raw: p.data[start:p.pos]}, nil
+	default:
+		return nil, p.errorf("invalid character %s looking for beginning of value", quoteChar(c))
+	}
+}
+
+// skip parses the value at the current position without keeping it.
+func (p *jsonParser) skip() error {
+	c, err := p.peek()
+	if err != nil {
+		return err
+	}
+	switch {
+	case c == '{':
+		return p.object(func(string, int) error { return p.skip() })
+	case c == '[':
+		return p.array(func(int) error { return p.skip() })
+	case c == '"':
+		_, err := p.string()
+		return err
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.number()
+	case c == 't' || c == 'f' || c == 'n':
+		_, err := p.literal()
+		return err
+	default:
+		return p.errorf("invalid character %s looking for beginning of value", quoteChar(c))
+	}
+}
+
+// literal parses true, false or null.
+func (p *jsonParser) literal() (jsonKind, error) {
+	lit, kind := "null", jsonNull
+	switch p.data[p.pos] {
+	case 't':
+		lit, kind = "true", jsonBool
+	case 'f':
+		lit, kind = "false", jsonBool
+	}
+	for i := 0; i < len(lit); i++ {
+		if p.pos >= len(p.data) {
+			return kind, p.errorf("unexpected end of JSON input")
+		}
+		if p.data[p.pos] != lit[i] {
+			return kind, p.errorf("invalid character %s in literal %s (expecting %s)", quoteChar(p.data[p.pos]), lit, quoteChar(lit[i]))
+		}
+		p.pos++
+	}
+	return kind, nil
+}
+
+// object parses the object at the current position. member is called for
+// each key, with the parser at the start of the value, and must consume it.
+func (p *jsonParser) object(member func(key string, offset int) error) error {
+	if p.depth++; p.depth > maxJSONDepth {
+		return p.errorf("exceeded max depth")
+	}
+	defer func() { p.depth-- }()
+	p.pos++ // '{'
+	p.skipSpace()
+	if p.pos < len(p.data) && p.data[p.pos] == '}' {
+		p.pos++
+		return nil
+	}
+	for {
+		if p.pos >= len(p.data) {
+			return p.errorf("unexpected end of JSON input")
+		}
+		if p.data[p.pos] != '"' {
+			return p.errorf("invalid character %s looking for beginning of object key string", quoteChar(p.data[p.pos]))
+		}
+		keyOffset := p.pos
+		key, err := p.string()
+		if err != nil {
+			return err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return p.errorf("unexpected end of JSON input")
+		}
+		if p.data[p.pos] != ':' {
+			return p.errorf("invalid character %s after object key", quoteChar(p.data[p.pos]))
+		}
+		p.pos++
+		p.skipSpace()
+		if err := member(key, keyOffset); err != nil {
+			return err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return p.errorf("unexpected end of JSON input")
+		}
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+			p.skipSpace()
+		case '}':
+			p.pos++
+			return nil
+		default:
+			return p.errorf("invalid character %s after object key:value pair", quoteChar(p.data[p.pos]))
+		}
+	}
+}
+
+// array parses the array at the current position. item is called for each
+// element, with the parser at its start, and must consume it.
+func (p *jsonParser) array(item func(i int) error) error {
+	if p.depth++; p.depth > maxJSONDepth {
+		return p.errorf("exceeded max depth")
+	}
+	defer func() { p.depth-- }()
+	p.pos++ // '['
+	p.skipSpace()
+	if p.pos < len(p.data) && p.data[p.pos] == ']' {
+		p.pos++
+		return nil
+	}
+	for i := 0; ; i++ {
+		if err := item(i); err != nil {
+			return err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return p.errorf("unexpected end of JSON input")
+		}
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+			p.skipSpace()
+		case ']':
+			p.pos++
+			return nil
+		default:
+			return p.errorf("invalid character %s after array element", quoteChar(p.data[p.pos]))
+		}
+	}
+}
+
+// string parses a JSON string starting at the opening quote and returns its
+// decoded value.
+func (p *jsonParser) string() (string, error) {
+	p.pos++ // opening quote
+	start := p.pos
+	// Fast path: no escapes.
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == '"' {
+			s := string(p.data[start:p.pos])
+			p.pos++
+			return s, nil
+		}
+		if c == '\\' {
+			break
+		}
+		if c < 0x20 {
+			return "", p.errorf("invalid character %s in string literal", quoteChar(c))
+		}
+		p.pos++
+	}
+
+	var b strings.Builder
+	b.Write(p.data[start:p.pos])
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c < 0x20:
+			return "", p.errorf("invalid character %s in string literal", quoteChar(c))
+		case c != '\\':
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+		// Escape sequence.
+		p.pos++
+		if p.pos >= len(p.data) {
+			break
+		}
+		switch e := p.data[p.pos]; e {
+		case '"', '\\', '/':
+			b.WriteByte(e)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			r, ok := p.hex4(p.pos + 1)
+			if !ok {
+				return "", p.errorf("invalid character in \\u hexadecimal character escape")
+			}
+			p.pos += 4
+			if utf16.IsSurrogate(r) {
+				if r2, ok := p.hex4(p.pos + 3); ok && p.pos+2 < len(p.data) && p.data[p.pos+1] == '\\' && p.data[p.pos+2] == 'u' {
+					if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+						r = dec
+						p.pos += 6
+					} else {
+						r = utf8.RuneError
+					}
+				} else {
+					r = utf8.RuneError
+				}
+			}
+			b.WriteRune(r)
+		default:
+			return "", p.errorf("invalid escape sequence `\\%c` in string", e)
+		}
+		p.pos++
+	}
+	return "", p.errorf("unexpected end of JSON input")
+}
+
+// hex4 decodes the four hex digits at data[i:i+4].
+func (p *jsonParser) hex4(i int) (rune, bool) {
+	if i+4 > len(p.data) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(string(p.data[i:i+4]), 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return rune(n), true
+}
+
+// number validates a JSON number literal.
+func (p *jsonParser) number() error {
+	if p.data[p.pos] == '-' {
+		p.pos++
+	}
+	digits := func() int {
+		n := 0
+		for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+			p.pos++
+			n++
+		}
+		return n
+	}
+	if p.pos >= len(p.data) {
+		return p.errorf("unexpected end of JSON input")
+	}
+	if p.data[p.pos] == '0' {
+		p.pos++
+	} else if digits() == 0 {
+		return p.errorf("invalid character %s in numeric literal", quoteChar(p.data[p.pos]))
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '.' {
+		p.pos++
+		if digits() == 0 {
+			return p.errorf("invalid character after decimal point in numeric literal")
+		}
+	}
+	if p.pos < len(p.data) && (p.data[p.pos] == 'e' || p.data[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.data) && (p.data[p.pos] == '+' || p.data[p.pos] == '-') {
+			p.pos++
+		}
+		if digits() == 0 {
+			return p.errorf("invalid character in exponent of numeric literal")
+		}
+	}
+	return nil
+}
+
+// quoteChar formats c the way encoding/json does in syntax errors.
+func quoteChar(c byte) string {
+	if c == '\'' {
+		return `'\''`
+	}
+	if c == '"' {
+		return `'"'`
+	}
+	s := strconv.Quote(string(c))
+	return "'" + s[1:len(s)-1] + "'"
+}
+
+// member returns the last member with the given key, which is the one
+// encoding/json keeps when a key is duplicated.
+func (n *jsonNode) member(key string) (*jsonNode, bool) {
+	for i := len(n.members) - 1; i >= 0; i-- {
+		if n.members[i].key == key {
+			return n.members[i].value, true
+		}
+	}
+	return nil, false
+}
+
+// lookup returns the value at path, a path of a ValidationError, or its
+// innermost ancestor in n if the value is missing. Keys match the names of
+// a path like encoding/json matches fields, exactly or else
+// case-insensitively.
+func (n *jsonNode) lookup(path string) *jsonNode {
+	for path != "" {
+		next, rest, ok := n.child(path)
+		if !ok {
+			break
+		}
+		n, path = next, rest
+	}
+	return n
+}
+
+// child returns the item or member of n that path starts with, and the
+// rest of path.
+func (n *jsonNode) child(path string) (*jsonNode, string, bool) {
+	switch n.kind {
+	case jsonArray:
+		index, rest, ok := strings.Cut(path, "]")
+		i, err := strconv.Atoi(strings.TrimPrefix(index, "["))
+		if !ok || path[0] != '[' || err != nil || i < 0 || i >= len(n.items) {
+			return nil, "", false
+		}
+		return n.items[i], strings.TrimPrefix(rest, "."), true
+	case jsonObject:
+		// Keys may contain the separators of paths, so the longest key
+		// the path starts with wins. Like encoding/json, the last of
+		// duplicated keys is used.
+		var best *jsonMember
+		exact := false
+		for i := len(n.members) - 1; i >= 0; i-- {
+			m := &n.members[i]
+			k := len(m.key)
+			if k > len(path) || (k < len(path) && path[k] != '.' && path[k] != '[') {
+				continue
+			}
+			switch {
+			case path[:k] == m.key:
+				if !exact || best == nil || k > len(best.key) {
+					best, exact = m, true
+				}
+			case !exact && strings.EqualFold(path[:k], m.key):
+				if best == nil || k > len(best.key) {
+					best = m
+				}
+			}
+		}
+		if best == nil {
+			return nil, "", false
+		}
+		return best.value, strings.TrimPrefix(path[len(best.key):], "."), true
+	}
+	return nil, "", false
+}
+
+// encode appends the JSON encoding of n to buf, preserving member order.
+func (n *jsonNode) encode(buf *bytes.Buffer) {
+	switch n.kind {
+	case jsonObject:
+		buf.WriteByte('{')
+		for i, m := range n.members {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(m.key)
+			buf.Write(key)
+			buf.WriteByte(':')
+			m.value.encode(buf)
+		}
+		buf.WriteByte('}')
+	case jsonArray:
+		buf.WriteByte('[')
+		for i, item := range n.items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			item.encode(buf)
+		}
+		buf.WriteByte(']')
+	default:
+		buf.Write(n.raw)
+	}
+}
+
+// bytes returns the JSON encoding of n.
+func (n *jsonNode) bytes() []byte {
+	var buf bytes.Buffer
+	n.encode(&buf)
+	return buf.Bytes()
+}
@@ -0,0 +1,393 @@
+package schema
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/twoojoo/goschema/internal/taglint"
+)
+
+func init() {
+	taglint.Tag = func(t reflect.Type, tag string) error {
+		return lintErrors(lintFieldTag(t, tag))
+	}
+	taglint.StructTag = func(tag string, fields []string) error {
+		return lintErrors(lintStructTag(tag, fields))
+	}
+}
+
+// LintError is a problem found in the `schema` tags of a type: an unknown
+// keyword, a keyword that does not apply to the field's Go type, an invalid
+// regular expression, ...
+type LintError struct {
+	Struct  string // Go type declaring the tag (e.g. "main.User")
+	Field   string // Go field name; empty for struct-level and type-level tags
+	Key     string // offending keyword, if any
+	Message string // Human-readable reason
+}
+
+func (e LintError) Error() string {
+	loc := e.Struct
+	if e.Field != "" {
+		loc += "." + e.Field
+	}
+	if e.Key != "" {
+		return fmt.Sprintf("%s: %s: %s", loc, e.Key, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", loc, e.Message)
+}
+
+// LintErrors is the collection of problems returned by [Lint]. It implements
+// the error interface.
+type LintErrors []LintError
+
+func (le LintErrors) Error() string {
+	msgs := make([]string, len(le))
+	for i, e := range le {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Lint checks the `schema` tags of T and of every struct reachable from it.
+// It reports unknown keywords (with a suggestion for likely typos), keywords
+// that do not apply to the field's Go type (`minLength` on an int), invalid
+// regular expressions, unknown formats, malformed `dependentRequired`
+// rules and Optional or Nullable fields without the `omitzero` JSON option.
+// It returns nil or a LintErrors, and is meant to be called from tests:
+//
+//	if err := schema.Lint[User](); err != nil {
+//		t.Fatal(err)
+//	}
+func Lint[T any]() error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return lintErrors(lintType(t, make(map[reflect.Type]bool)))
+}
+
+// lintErrors returns errs as an error, or nil if there are none.
+func lintErrors(errs LintErrors) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Keywords accepted in field tags, by the schema type they apply to.
+var (
+	commonKeywords = []string{
+		"required", "default", "title", "description", "examples", "deprecated",
+		"readOnly", "writeOnly", "nullable", "enum", "const", "not", "anyOf", "oneOf", "allOf",
+	}
+	stringKeywords = []string{"minLength", "maxLength", "pattern", "format"}
+	numberKeywords = []string{"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf"}
+	timeKeywords   = []string{"after", "before", "minAge", "maxAge"}
+	arrayKeywords  = []string{"minItems", "maxItems", "uniqueItems"}
+	mapKeywords    = []string{"minProperties", "maxProperties"}
+	structKeywords = []string{"title", "description", "additionalProperties"}
+
+	// subSchemaKeywords are understood in `items:` rules and in the
+	// sub-schemas of not/anyOf/oneOf/allOf.
+	subSchemaKeywords = slices.Concat(stringKeywords, numberKeywords, []string{"enum", "const"})
+
+	allKeywords = slices.Concat(commonKeywords, stringKeywords, numberKeywords, timeKeywords, arrayKeywords, mapKeywords)
+)
+
+// lintType lints the struct types reachable from t.
+func lintType(t reflect.Type, seen map[reflect.Type]bool) LintErrors {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if vt, _, ok := wrappedType(t); ok {
+		return lintType(vt, seen)
+	}
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+
+	var errs LintErrors
+	if implements(t, schemaTaggerType) {
+		tag := interfaceValue(reflect.New(t).Elem(), schemaTaggerType).(SchemaTagger).SchemaTag()
+		base, err := goTypeSchema(t)
+		if err == nil {
+			base, err = enumSchema(t, base)
+		}
+		if err != nil {
+			return append(errs, LintError{Struct: t.String(), Message: err.Error()})
+		}
+		errs = append(errs, locate(lintTag(base, t, tag), t, "")...)
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return append(errs, lintType(t.Elem(), seen)...)
+	case reflect.Struct:
+		if t == timeType || isMarshaler(t) {
+			return errs
+		}
+	default:
+		return errs
+	}
+
+	var fields []string
+	for f := range structFields(t) {
+		if f.Name != "_" {
+			fields = append(fields, jsonFieldName(f))
+			fields = append(fields, promotedNames(f, map[reflect.Type]bool{})...)
+		}
+	}
+	for f := range structFields(t) {
+		if f.Name == "_" {
+			errs = append(errs, locate(lintStructTag(f.Tag.Get("schema"), fields), t, "")...)
+			continue
+		}
+		errs = append(errs, locate(lintFieldTag(f.Type, f.Tag.Get("schema")), t, f.Name)...)
+		if _, _, ok := wrappedType(f.Type); ok && !hasJSONOption(f, "omitzero") {
+			errs = append(errs, LintError{
+				Struct:  t.String(),
+				Field:   f.Name,
+				Message: "Optional and Nullable fields need the omitzero JSON option, or they marshal as null when unset",
+			})
+		}
+		errs = append(errs, lintType(f.Type, seen)...)
+	}
+	return errs
+}
+
+// locate sets the struct and field of errs.
+func locate(errs LintErrors, t reflect.Type, field string) LintErrors {
+	for i := range errs {
+		errs[i].Struct, errs[i].Field = t.String(), field
+	}
+	return errs
+}
+
+// lintFieldTag checks the `schema` tag of a field of type t, and returns
+// findings without a location. Through taglint.Tag, it lets goschema-vet,
+// which rebuilds field types from source, apply the same rules as the
+// runtime. A nil t stands for a type whose schema cannot be known
+// statically; only the checks that do not depend on it are run.
+func lintFieldTag(t reflect.Type, tag string) LintErrors {
+	if t == nil {
+		return lintTag(FieldSchema{}, nil, tag)
+	}
+	base, err := reflectTypeToSchema(t)
+	if err != nil {
+		return LintErrors{{Message: err.Error()}}
+	}
+	return lintTag(base, t, tag)
+}
+
+// structFields yields the fields of struct t that take part in its schema:
+// the `_` sentinel and the exported fields not skipped with `json:"-"`.
+func structFields(t reflect.Type) iter.Seq[reflect.StructField] {
+	return func(yield func(reflect.StructField) bool) {
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if f.Name != "_" && (!f.IsExported() || jsonFieldName(f) == "-") {
+				continue
+			}
+			if !yield(f) {
+				return
+			}
+		}
+	}
+}
+
+// lintTag checks the tag of a field (or of a SchemaTagger type) of type t
+// whose base schema is fs.
+func lintTag(fs FieldSchema, t reflect.Type, tag string) LintErrors {
+	var errs LintErrors
+	report := func(key, format string, args ...any) {
+		errs = append(errs, LintError{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	opts, err := parseTagOptions(tag)
+	if err != nil {
+		report("", "%v", err)
+		return errs
+	}
+	applicable := slices.Clone(commonKeywords)
+	switch {
+	case fs.Type == "string":
+		applicable = append(applicable, stringKeywords...)
+		if fs.Time != nil {
+			applicable = append(applicable, timeKeywords...)
+		}
+	case fs.Type == "integer" || fs.Type == "number":
+		applicable = append(applicable, numberKeywords...)
+	case fs.Type == "array":
+		applicable = append(applicable, arrayKeywords...)
+	case fs.Map != nil:
+		applicable = append(applicable, mapKeywords...)
+	}
+
+	for _, key := range sortedKeys(opts) {
+		if rule, ok := strings.CutPrefix(key, "items:"); ok {
+			if t != nil && fs.Type != "array" {
+				report(key, "items rules only apply to slices and arrays, not %s fields", fs.Type)
+				continue
+			}
+			errs = append(errs, lintSubSchema(key, rule+"="+opts[key])...)
+			continue
+		}
+		switch {
+		case !slices.Contains(allKeywords, key):
+			report(key, "unknown keyword%s", suggestKeyword(key, allKeywords))
+			continue
+		case t != nil && !slices.Contains(applicable, key):
+			report(key, "does not apply to %s fields (Go type %s)", fs.Type, t)
+			continue
+		}
+		switch key {
+		case "not", "anyOf", "oneOf", "allOf":
+			for _, sub := range splitTagValue(opts[key], ';') {
+				errs = append(errs, lintSubSchema(key, sub)...)
+			}
+		default:
+			if msg := lintValue(key, opts); msg != "" {
+				report(key, "%s", msg)
+			}
+		}
+	}
+	// Building the schema catches the rest, like bad numbers and defaults.
+	// Its error is left out when a keyword already explains it.
+	if t != nil && len(errs) == 0 {
+		if _, err := applyTag(fs, t, tag); err != nil {
+			report("", "%v", err)
+		}
+	}
+	return errs
+}
+
+// lintSubSchema checks a sub-schema of an `items:` rule or a composition
+// keyword, reporting problems under key.
+func lintSubSchema(key, raw string) LintErrors {
+	var errs LintErrors
+	report := func(format string, args ...any) {
+		errs = append(errs, LintError{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+	opts, err := parseTagOptions(raw)
+	if err != nil {
+		report("%v", err)
+		return errs
+	}
+	for _, k := range sortedKeys(opts) {
+		if !slices.Contains(subSchemaKeywords, k) {
+			report("unknown sub-schema keyword %q%s", k, suggestKeyword(k, subSchemaKeywords))
+			continue
+		}
+		if msg := lintValue(k, opts); msg != "" {
+			report("%s: %s", k, msg)
+		}
+	}
+	return errs
+}
+
+// lintValue checks the values of keywords that are only interpreted at
+// validation time.
+func lintValue(key string, opts tagOptions) string {
+	v, _ := opts.get(key)
+	switch key {
+	case "pattern":
+		v, _ = opts.pattern(key)
+		if _, err := regexp.Compile(v); err != nil {
+			return fmt.Sprintf("invalid regular expression: %v", err)
+		}
+	case "format":
+		if _, ok := formatPatterns[v]; !ok {
+			return fmt.Sprintf("unknown format %q (known: %s)", v, strings.Join(sortedKeys(formatPatterns), ", "))
+		}
+	}
+	return ""
+}
+
+// lintStructTag checks the struct-level tag of a `_` sentinel field, given
+// the JSON names of the struct's fields, and returns findings without a
+// location.
+func lintStructTag(tag string, fields []string) LintErrors {
+	var errs LintErrors
+	report := func(key, format string, args ...any) {
+		errs = append(errs, LintError{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	opts, err := parseTagOptions(tag)
+	if err != nil {
+		report("", "%v", err)
+		return errs
+	}
+	for _, key := range sortedKeys(opts) {
+		if source, ok := strings.CutPrefix(key, "dependentRequired:"); ok {
+			if !slices.Contains(fields, source) {
+				report(key, "unknown field %q", source)
+			}
+			for _, target := range splitTagList(opts[key]) {
+				switch {
+				case target == "" || target == "true":
+					report(key, "missing target fields (want dependentRequired:%s=a|b)", source)
+				case !slices.Contains(fields, target):
+					report(key, "unknown target field %q", target)
+				}
+			}
+			continue
+		}
+		if !slices.Contains(structKeywords, key) {
+			report(key, "unknown struct-level keyword%s", suggestKeyword(key, structKeywords))
+			continue
+		}
+		if v, _ := opts.get(key); key == "additionalProperties" && v != "true" && v != "false" {
+			report(key, "must be true or false, got %q", v)
+		}
+	}
+	return errs
+}
+
+// suggestKeyword returns a " (did you mean X?)" hint for a likely typo of
+// one of the known keywords, or "".
+func suggestKeyword(key string, known []string) string {
+	best, bestDist := "", 3
+	for _, k := range known {
+		if d := editDistance(strings.ToLower(key), strings.ToLower(k)); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// sortedKeys returns the keys of m in order, for deterministic reports.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
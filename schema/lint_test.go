@@ -0,0 +1,109 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Sloppy struct {
+	_       any      `schema:"title=Sloppy,dependentRequired:card=billing|missing,strict=true"`
+	Name    string   `json:"name"    schema:"minLenght=3"`
+	Age     int      `json:"age"     schema:"minLength=1"`
+	Email   string   `json:"email"   schema:"format=e-mail"`
+	Tags    []string `json:"tags"    schema:"items:minLenght=2"`
+	Alt     string   `json:"alt"     schema:"anyOf=minLength=2;maxLenght=3"`
+	Billing string   `json:"billing"`
+	Inner   Inner    `json:"inner"`
+}
+
+type Inner struct {
+	Count int `json:"count" schema:"uniqueItems"`
+}
+
+func TestLint_CleanTypes(t *testing.T) {
+	for name, lint := range map[string]func() error{
+		"User":     schema.Lint[User],
+		"Account":  schema.Lint[Account],
+		"Team":     schema.Lint[Team],
+		"Quoted":   schema.Lint[Quoted],
+		"DepDoc":   schema.Lint[DepDoc],
+		"Job":      schema.Lint[Job],
+		"Invoice":  schema.Lint[Invoice],
+		"Pipeline": schema.Lint[Pipeline],
+		"Ticket":   schema.Lint[*Ticket],
+	} {
+		if err := lint(); err != nil {
+			t.Errorf("%s: unexpected lint errors: %v", name, err)
+		}
+	}
+}
+
+func TestLint_Findings(t *testing.T) {
+	err := schema.Lint[Sloppy]()
+	le, ok := err.(schema.LintErrors)
+	if !ok {
+		t.Fatalf("expected LintErrors, got %T: %v", err, err)
+	}
+
+	want := []string{
+		`schema_test.Sloppy: dependentRequired:card: unknown field "card"`,
+		`schema_test.Sloppy: dependentRequired:card: unknown target field "missing"`,
+		`schema_test.Sloppy: strict: unknown struct-level keyword`,
+		`schema_test.Sloppy.Name: minLenght: unknown keyword (did you mean "minLength"?)`,
+		`schema_test.Sloppy.Age: minLength: does not apply to integer fields (Go type int)`,
+		`schema_test.Sloppy.Email: format: unknown format "e-mail"`,
+		`schema_test.Sloppy.Tags: items:minLenght: unknown sub-schema keyword "minLenght" (did you mean "minLength"?)`,
+		`schema_test.Sloppy.Alt: anyOf: unknown sub-schema keyword "maxLenght"`,
+		`schema_test.Inner.Count: uniqueItems: does not apply to integer fields`,
+	}
+	msg := le.Error()
+	for _, w := range want {
+		if !strings.Contains(msg, w) {
+			t.Errorf("missing finding %q in:\n%s", w, strings.ReplaceAll(msg, "; ", "\n"))
+		}
+	}
+	if len(le) != len(want) {
+		t.Errorf("expected %d findings, got %d:\n%s", len(want), len(le), strings.ReplaceAll(msg, "; ", "\n"))
+	}
+	if le[3].Struct != "schema_test.Sloppy" || le[3].Field != "Name" || le[3].Key != "minLenght" {
+		t.Errorf("unexpected location: %+v", le[3])
+	}
+}
+
+func TestLint_MalformedTag(t *testing.T) {
+	err := schema.Lint[UnterminatedTag]()
+	if err == nil || !strings.Contains(err.Error(), "schema_test.UnterminatedTag.V: malformed tag") {
+		t.Errorf("expected malformed tag finding, got %v", err)
+	}
+}
+
+func TestLint_InvalidPattern(t *testing.T) {
+	err := schema.Lint[BadPattern]()
+	if err == nil || !strings.Contains(err.Error(), "schema_test.BadPattern.V: pattern: invalid regular expression") {
+		t.Errorf("expected an invalid pattern finding, got %v", err)
+	}
+}
+
+func TestCompile_Strict(t *testing.T) {
+	// Without Strict, tag problems that don't break the schema are tolerated.
+	if _, err := schema.Compile[Sloppy](); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := schema.Compile[Sloppy](schema.Strict()); err == nil {
+		t.Fatal("expected strict compile to fail")
+	}
+
+	s, err := schema.Compile[Team](schema.Strict(), schema.WithReadOnly(schema.DropReadOnly))
+	assertNoError(t, err)
+	team, err := s.ParseJSON([]byte(`{"id":"t1","name":"core"}`))
+	assertNoError(t, err)
+	if team.ID != "" {
+		t.Errorf("expected compiled options to apply, got id %q", team.ID)
+	}
+	mustValidationErrors(t, s.Validate(Team{}))
+	if s.JSONSchema()["type"] != "object" {
+		t.Errorf("unexpected JSON schema: %v", s.JSONSchema())
+	}
+}
@@ -0,0 +1,197 @@
+package schema
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonTyperType       = reflect.TypeOf((*JSONTyper)(nil)).Elem()
+)
+
+// JSONTyper is implemented by json.Marshaler types to declare the JSON
+// Schema type of the value their MarshalJSON method produces ("string",
+// "number", "integer", "boolean", "array" or "object"). Without it, such
+// types are described as "any", since their Go layout says nothing about
+// their JSON form.
+//
+//	func (Money) JSONType() string { return "string" }
+type JSONTyper interface {
+	JSONType() string
+}
+
+var (
+	jsonTypesMu sync.RWMutex
+	jsonTypes   = map[reflect.Type]string{}
+)
+
+// RegisterJSONType declares the JSON Schema type produced by T's custom
+// marshaller. It is the counterpart of [JSONTyper] for types you cannot add
+// methods to, such as big.Int. It panics if jsonType is not a JSON Schema
+// primitive type.
+//
+//	schema.RegisterJSONType[big.Int]("integer")
+func RegisterJSONType[T any](jsonType string) {
+	if !isJSONType(jsonType) {
+		panic(fmt.Sprintf("goschema: RegisterJSONType: invalid JSON type %q", jsonType))
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	jsonTypesMu.Lock()
+	jsonTypes[t] = jsonType
+	jsonTypesMu.Unlock()
+	forgetResolved()
+}
+
+// isJSONType reports whether s is a JSON Schema primitive type.
+func isJSONType(s string) bool {
+	switch s {
+	case "string", "number", "integer", "boolean", "array", "object":
+		return true
+	}
+	return false
+}
+
+// implements reports whether t or *t implements iface, mirroring how
+// encoding/json finds marshal methods on addressable values.
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || (t.Kind() != reflect.Ptr && reflect.PointerTo(t).Implements(iface))
+}
+
+// isMarshaler reports whether encoding/json would use a custom marshal method
+// for values of type t.
+func isMarshaler(t reflect.Type) bool {
+	return implements(t, jsonMarshalerType) || implements(t, textMarshalerType)
+}
+
+// marshalerSchema returns the base schema of a type that encoding/json
+// marshals through MarshalJSON or MarshalText. The boolean is false for
+// ordinary types.
+func marshalerSchema(t reflect.Type) (FieldSchema, bool) {
+	jsonTypesMu.RLock()
+	registered, ok := jsonTypes[t]
+	jsonTypesMu.RUnlock()
+
+	var typ string
+	switch {
+	case ok:
+		typ = registered
+	case implements(t, jsonTyperType):
+		typ = interfaceValue(reflect.New(t).Elem(), jsonTyperType).(JSONTyper).JSONType()
+		if !isJSONType(typ) {
+			typ = "any"
+		}
+	case implements(t, jsonMarshalerType):
+		typ = "any"
+	case implements(t, textMarshalerType):
+		typ = "string"
+	default:
+		return FieldSchema{}, false
+	}
+
+	fs := FieldSchema{Type: typ}
+	switch typ {
+	case "string":
+		fs.String = &StringConstraints{}
+	case "integer", "number":
+		fs.Number = &NumberConstraints{}
+	case "boolean":
+		fs.Bool = &BoolConstraints{}
+	case "array":
+		fs.Array = &ArrayConstraints{}
+	}
+	return fs, true
+}
+
+// interfaceValue returns v (or its address) as an iface value, copying v
+// into a new variable when a pointer receiver is needed but v is not
+// addressable.
+func interfaceValue(v reflect.Value, iface reflect.Type) any {
+	if v.Type().Implements(iface) {
+		return v.Interface()
+	}
+	if !v.CanAddr() {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		return p.Interface()
+	}
+	return v.Addr().Interface()
+}
+
+// marshaler returns the json.Marshaler or encoding.TextMarshaler that
+// encoding/json would marshal v with, or nil. Like encoding/json, it only
+// uses pointer-receiver methods when v is addressable.
+func marshaler(v reflect.Value) any {
+	for _, iface := range []reflect.Type{jsonMarshalerType, textMarshalerType} {
+		switch {
+		case v.Type().Implements(iface):
+			return v.Interface()
+		case v.CanAddr() && reflect.PointerTo(v.Type()).Implements(iface):
+			return v.Addr().Interface()
+		}
+	}
+	return nil
+}
+
+// wireValue converts a value with a custom marshaller into the value it
+// takes in JSON, so that constraints apply to what clients actually see
+// (e.g. `pattern` on the text form of a netip.Addr). The boolean is false
+// when v is marshalled from its Go layout, or its marshaller fails.
+//
+// Zero values of string-typed marshalers are reported as "", consistent
+// with how `required` treats other zero values.
+func wireValue(v reflect.Value, jsonType string) (reflect.Value, bool) {
+	if !v.IsValid() || !v.CanInterface() {
+		return v, false
+	}
+	m := marshaler(v)
+	if m == nil {
+		return v, false
+	}
+	if jsonType == "string" && v.IsZero() {
+		return reflect.ValueOf(""), true
+	}
+
+	if m, ok := m.(json.Marshaler); ok {
+		raw, err := m.MarshalJSON()
+		if err != nil {
+			return v, false
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var x any
+		if err := dec.Decode(&x); err != nil || x == nil {
+			return v, false
+		}
+		if n, ok := x.(json.Number); ok {
+			return numberValue(n), true
+		}
+		return reflect.ValueOf(x), true
+	}
+
+	text, err := m.(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return v, false
+	}
+	return reflect.ValueOf(string(text)), true
+}
+
+// numberValue converts a JSON number literal to an int64, uint64 or float64
+// value, preferring the exact integer representations.
+func numberValue(n json.Number) reflect.Value {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return reflect.ValueOf(i)
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return reflect.ValueOf(u)
+	}
+	f, _ := strconv.ParseFloat(string(n), 64)
+	return reflect.ValueOf(f)
+}
@@ -0,0 +1,157 @@
+package schema_test
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+// UUID marshals as text through a value receiver.
+type UUID [16]byte
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])), nil
+}
+
+// Money marshals as a JSON string and declares it.
+type Money struct {
+	Cents    int64
+	Currency string
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"%d.%02d %s"`, m.Cents/100, m.Cents%100, m.Currency)), nil
+}
+
+func (Money) JSONType() string { return "string" }
+
+// Opaque marshals as JSON without declaring its type.
+type Opaque struct {
+	Inner string `schema:"required"`
+}
+
+func (Opaque) MarshalJSON() ([]byte, error) { return []byte(`{}`), nil }
+
+func init() {
+	schema.RegisterJSONType[big.Int]("integer")
+}
+
+type Host struct {
+	Addr   netip.Addr `json:"addr"   schema:"required,pattern=^10\\."`
+	ID     UUID       `json:"id"     schema:"format=uuid"`
+	Price  Money      `json:"price"  schema:"pattern=EUR$"`
+	Count  *big.Int   `json:"count"  schema:"maximum=1000"`
+	Opaque Opaque     `json:"opaque"`
+}
+
+func TestMarshaler_ToJSONSchema(t *testing.T) {
+	js, err := schema.ToJSONSchema[Host]()
+	assertNoError(t, err)
+	props := js["properties"].(map[string]any)
+
+	for name, want := range map[string]string{"addr": "string", "id": "string", "price": "string", "count": "integer"} {
+		got := props[name].(map[string]any)
+		if got["type"] != want {
+			t.Errorf("%s: expected type %q, got %v", name, want, got)
+		}
+		if _, ok := got["properties"]; ok {
+			t.Errorf("%s: Go layout must not be described", name)
+		}
+	}
+	if _, ok := props["opaque"].(map[string]any)["type"]; ok {
+		t.Errorf("undeclared json.Marshaler should have no type, got %v", props["opaque"])
+	}
+}
+
+func TestMarshaler_ValidatesTextForm(t *testing.T) {
+	valid := Host{
+		Addr:  netip.MustParseAddr("10.0.0.1"),
+		ID:    UUID{0xde, 0xad, 0xbe, 0xef, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+		Price: Money{Cents: 1250, Currency: "EUR"},
+		Count: big.NewInt(10),
+	}
+	assertNoError(t, schema.Validate(valid))
+
+	invalid := Host{
+		Addr:  netip.MustParseAddr("192.168.1.1"),
+		Price: Money{Cents: 1250, Currency: "USD"},
+		Count: big.NewInt(5000),
+	}
+	ve := mustValidationErrors(t, schema.Validate(invalid))
+	assertHasField(t, ve, "addr")
+	assertHasField(t, ve, "price")
+	assertHasField(t, ve, "count")
+	if ve.Has("opaque") {
+		t.Error("constraints on the Go layout of a json.Marshaler must not be validated")
+	}
+}
+
+func TestMarshaler_ZeroIsEmpty(t *testing.T) {
+	ve := mustValidationErrors(t, schema.Validate(Host{}))
+	assertHasField(t, ve, "addr")
+	if ve.Has("id") {
+		t.Errorf("zero optional UUID should skip format checks: %v", ve)
+	}
+}
+
+// Badge marshals as text through a pointer receiver.
+type Badge struct{ N int }
+
+// badgeMarshals counts the calls to Badge.MarshalText.
+var badgeMarshals int
+
+func (c *Badge) MarshalText() ([]byte, error) {
+	badgeMarshals++
+	return []byte(fmt.Sprintf("T%d", c.N)), nil
+}
+
+type Badged struct {
+	Code Badge `json:"code" schema:"pattern=^T[0-9]$"`
+	Free Badge `json:"free"`
+}
+
+func TestMarshaler_PointerReceiver(t *testing.T) {
+	// Like encoding/json, the method is only used on addressable values.
+	v := Badged{Code: Badge{N: 42}}
+	ve := mustValidationErrors(t, schema.Validate(&v))
+	assertHasField(t, ve, "code")
+	assertNoError(t, schema.Validate(v))
+
+	_, err := schema.ParseJSON[Badged]([]byte(`{"code":{"N":42}}`))
+	ve = mustValidationErrors(t, err)
+	assertHasField(t, ve, "code")
+}
+
+func TestMarshaler_OnlyRunForConstraints(t *testing.T) {
+	badgeMarshals = 0
+	assertNoError(t, schema.Validate(&Badged{Code: Badge{N: 1}}))
+	if badgeMarshals != 1 {
+		t.Errorf("expected one MarshalText call, for the constrained field; got %d", badgeMarshals)
+	}
+}
+
+type WithAddrDefault struct {
+	Addr netip.Addr `json:"addr" schema:"default=127.0.0.1"`
+}
+
+func TestMarshaler_TextDefault(t *testing.T) {
+	v, err := schema.ParseJSON[WithAddrDefault]([]byte(`{}`))
+	assertNoError(t, err)
+	if v.Addr.String() != "127.0.0.1" {
+		t.Errorf("expected default address, got %v", v.Addr)
+	}
+}
+
+func TestRegisterJSONType_Invalid(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(fmt.Sprint(r), "invalid JSON type") {
+			t.Errorf("expected panic for invalid JSON type, got %v", r)
+		}
+	}()
+	schema.RegisterJSONType[Opaque]("decimal")
+}
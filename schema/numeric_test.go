@@ -0,0 +1,112 @@
+package schema_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+// ---- unsigned integers ----
+
+type Unsigned struct {
+	Port   uint16  `json:"port"   schema:"minimum=1,maximum=65535"`
+	Count  uint32  `json:"count"  schema:"maximum=10,default=3"`
+	Big    uint64  `json:"big"    schema:"maximum=9007199254740992"`
+	Step   uint    `json:"step"   schema:"multipleOf=5"`
+	Ptr    uintptr `json:"ptr"`
+	Signed int64   `json:"signed" schema:"maximum=9007199254740992"`
+}
+
+func TestUnsigned_Valid(t *testing.T) {
+	s := Unsigned{Port: 8080, Count: 10, Big: 1 << 53, Step: 15, Signed: 1 << 53}
+	assertNoError(t, schema.Validate(s))
+}
+
+func TestUnsigned_Bounds(t *testing.T) {
+	ve := mustValidationErrors(t, schema.Validate(Unsigned{Port: 0, Count: 11}))
+	assertHasField(t, ve, "port")
+	assertHasField(t, ve, "count")
+}
+
+func TestUnsigned_NoPrecisionLoss(t *testing.T) {
+	// 2^53+1 rounds to 2^53 as a float64, which would wrongly satisfy
+	// maximum=2^53.
+	ve := mustValidationErrors(t, schema.Validate(Unsigned{Port: 1, Big: 1<<53 + 1, Signed: 1<<53 + 1}))
+	assertHasField(t, ve, "big")
+	assertHasField(t, ve, "signed")
+
+	if err := schema.Validate(Unsigned{Port: 1, Big: math.MaxUint64}); err == nil {
+		t.Error("expected MaxUint64 to exceed maximum")
+	}
+}
+
+func TestUnsigned_ErrorValues(t *testing.T) {
+	// Values are float64 when they fit, and keep their integer type when a
+	// float64 would round them.
+	ve := mustValidationErrors(t, schema.Validate(Unsigned{Port: 1, Count: 11, Big: 1<<53 + 1}))
+	for _, e := range ve {
+		var want any
+		switch e.Field {
+		case "count":
+			want = 11.0
+		case "big":
+			want = uint64(1<<53 + 1)
+		default:
+			continue
+		}
+		if e.Value != want {
+			t.Errorf("%s: expected value %#v, got %#v", e.Field, want, e.Value)
+		}
+	}
+}
+
+func TestUnsigned_MultipleOf(t *testing.T) {
+	ve := mustValidationErrors(t, schema.Validate(Unsigned{Port: 1, Step: 7}))
+	assertHasField(t, ve, "step")
+}
+
+func TestUnsigned_Default(t *testing.T) {
+	s, err := schema.ParseJSON[Unsigned]([]byte(`{"port":80}`))
+	assertNoError(t, err)
+	if s.Count != 3 {
+		t.Errorf("expected count=3 from default, got %d", s.Count)
+	}
+}
+
+func TestUnsigned_ParseNegative(t *testing.T) {
+	_, err := schema.ParseJSON[Unsigned]([]byte(`{"port":-1}`))
+	ve := mustValidationErrors(t, err)
+	assertHasField(t, ve, "port")
+}
+
+func TestUnsigned_ToJSONSchema(t *testing.T) {
+	js, err := schema.ToJSONSchema[Unsigned]()
+	assertNoError(t, err)
+	props := js["properties"].(map[string]any)
+
+	port := props["port"].(map[string]any)
+	if port["type"] != "integer" || port["minimum"] != 1.0 {
+		t.Errorf("unexpected schema for port: %v", port)
+	}
+	count := props["count"].(map[string]any)
+	if count["type"] != "integer" || count["minimum"] != 0.0 {
+		t.Errorf("expected implicit minimum 0 for count, got %v", count)
+	}
+	ptr := props["ptr"].(map[string]any)
+	if ptr["type"] != "integer" {
+		t.Errorf("expected uintptr to be an integer, got %v", ptr)
+	}
+}
+
+// ---- signed integer edge cases ----
+
+type MinInt struct {
+	N int64 `json:"n" schema:"minimum=-9223372036854775808,multipleOf=2"`
+}
+
+func TestInt64_MinValue(t *testing.T) {
+	assertNoError(t, schema.Validate(MinInt{N: math.MinInt64}))
+	ve := mustValidationErrors(t, schema.Validate(MinInt{N: -3}))
+	assertHasField(t, ve, "n")
+}
@@ -0,0 +1,192 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Optional is a field that may be missing from the JSON input. Unlike a
+// pointer, it tells a missing key apart from any value, zero included: Set
+// is true only if the key was present. JSON null is rejected.
+//
+// The schema of an Optional is that of T: the tag constraints apply to
+// Value, and only when the key is present. `required` makes the key itself
+// mandatory. Tag the field with the `omitzero` JSON option, so that an
+// unset Optional is left out when marshalling: MarshalJSON can only encode
+// it as null, which UnmarshalJSON rejects. [Lint] reports fields without it.
+//
+//	type UserPatch struct {
+//		Name schema.Optional[string] `json:"name,omitzero" schema:"minLength=1"`
+//		Age  schema.Optional[int]    `json:"age,omitzero"  schema:"minimum=0"`
+//	}
+type Optional[T any] struct {
+	Value T
+	Set   bool
+}
+
+// NewOptional returns a set Optional holding v.
+func NewOptional[T any](v T) Optional[T] {
+	return Optional[T]{Value: v, Set: true}
+}
+
+// Get returns the value and whether it is set.
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.Set
+}
+
+// IsZero reports whether o is unset, which makes `omitzero` omit it.
+func (o Optional[T]) IsZero() bool {
+	return !o.Set
+}
+
+// MarshalJSON encodes the value, or null if o is unset. Only `omitzero`
+// leaves an unset Optional out.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// UnmarshalJSON decodes a value and marks o as set. It rejects null.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return &json.UnmarshalTypeError{Value: "null", Type: reflect.TypeOf((*T)(nil)).Elem()}
+	}
+	if err := json.Unmarshal(data, &o.Value); err != nil {
+		return err
+	}
+	o.Set = true
+	return nil
+}
+
+func (o Optional[T]) state() (set, null bool) { return o.Set, false }
+func (Optional[T]) valueType() (t reflect.Type, null bool) {
+	return reflect.TypeOf((*T)(nil)).Elem(), false
+}
+
+// Nullable is a field that may be missing, null, or hold a value, as needed
+// for PATCH semantics where null clears a field and a missing key leaves it
+// alone: Set tells whether the key was present and Null whether it was null.
+//
+// The schema of a Nullable is that of T with `nullable`: the tag constraints
+// apply to Value, and only when a value is present. `required` makes the key
+// itself mandatory, though it may be null. Tag the field with the
+// `omitzero` JSON option, so that an unset Nullable is left out when
+// marshalling instead of coming back as null. [Lint] reports fields
+// without it.
+//
+//	type UserPatch struct {
+//		Email schema.Nullable[string] `json:"email,omitzero" schema:"format=email"`
+//	}
+type Nullable[T any] struct {
+	Value T
+	Set   bool
+	Null  bool
+}
+
+// NewNullable returns a set, non-null Nullable holding v.
+func NewNullable[T any](v T) Nullable[T] {
+	return Nullable[T]{Value: v, Set: true}
+}
+
+// Null returns a set Nullable holding null.
+func Null[T any]() Nullable[T] {
+	return Nullable[T]{Set: true, Null: true}
+}
+
+// Get returns the value and whether it is set and not null.
+func (n Nullable[T]) Get() (T, bool) {
+	return n.Value, n.Set && !n.Null
+}
+
+// IsZero reports whether n is unset, which makes `omitzero` omit it.
+func (n Nullable[T]) IsZero() bool {
+	return !n.Set
+}
+
+// MarshalJSON encodes the value, or null if n is unset or null. Only
+// `omitzero` leaves an unset Nullable out.
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.Set || n.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Value)
+}
+
+// UnmarshalJSON decodes a value or null and marks n as set.
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	var zero T
+	n.Value, n.Null = zero, string(data) == "null"
+	if !n.Null {
+		if err := json.Unmarshal(data, &n.Value); err != nil {
+			return err
+		}
+	}
+	n.Set = true
+	return nil
+}
+
+func (n Nullable[T]) state() (set, null bool) { return n.Set, n.Null }
+func (Nullable[T]) valueType() (t reflect.Type, null bool) {
+	return reflect.TypeOf((*T)(nil)).Elem(), true
+}
+
+// wrapper is implemented by Optional and Nullable, whose Value is their
+// first field.
+type wrapper interface {
+	state() (set, null bool)
+	valueType() (t reflect.Type, null bool)
+}
+
+var wrapperType = reflect.TypeOf((*wrapper)(nil)).Elem()
+
+// wrappedType returns the value type of an Optional or Nullable type and
+// whether it is nullable. The last boolean is false for other types.
+func wrappedType(t reflect.Type) (reflect.Type, bool, bool) {
+	if t.Kind() != reflect.Struct || !t.Implements(wrapperType) {
+		return nil, false, false
+	}
+	vt, null := reflect.Zero(t).Interface().(wrapper).valueType()
+	return vt, null, true
+}
+
+// unwrap returns the Value of an Optional or Nullable v, and whether it is
+// set and null. The last boolean is false for other values.
+func unwrap(v reflect.Value) (value reflect.Value, set, null, ok bool) {
+	if v.Kind() != reflect.Struct || !v.Type().Implements(wrapperType) || !v.CanInterface() {
+		return v, false, false, false
+	}
+	set, null = v.Interface().(wrapper).state()
+	return v.Field(0), set, null, true
+}
+
+// hasWrapper reports whether values of type t may hold an Optional or
+// Nullable. encoding/json reports the errors returned by their
+// UnmarshalJSON methods without the path of the value.
+func hasWrapper(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if _, _, ok := wrappedType(t); ok {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return hasWrapper(t.Elem(), seen)
+	case reflect.Struct:
+		if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+			return false
+		}
+		for i := range t.NumField() {
+			if f := t.Field(i); (f.IsExported() || f.Anonymous) && hasWrapper(f.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type ProfilePatch struct {
+	Name    schema.Optional[string]        `json:"name,omitzero"    schema:"minLength=2"`
+	Age     schema.Optional[int]           `json:"age,omitzero"     schema:"minimum=18"`
+	Email   schema.Nullable[string]        `json:"email,omitzero"   schema:"format=email"`
+	Token   schema.Optional[string]        `json:"token,omitzero"   schema:"required"`
+	Timeout schema.Optional[time.Duration] `json:"timeout,omitzero" schema:"maximum=1m,default=30s"`
+	Home    schema.Nullable[Place]         `json:"home,omitzero"`
+}
+
+func TestOptional_States(t *testing.T) {
+	p, err := schema.ParseJSON[ProfilePatch]([]byte(`{"token":"t","email":null,"age":21}`))
+	assertNoError(t, err)
+	if p.Name.Set {
+		t.Error("a missing key should leave Name unset")
+	}
+	if age, ok := p.Age.Get(); !ok || age != 21 {
+		t.Errorf("unexpected age: %v, %v", age, ok)
+	}
+	if !p.Email.Set || !p.Email.Null {
+		t.Errorf("expected an explicit null email, got %+v", p.Email)
+	}
+	if _, ok := p.Email.Get(); ok {
+		t.Error("Get should report a null email as missing")
+	}
+	if p.Home.Set {
+		t.Error("a missing key should leave Home unset")
+	}
+}
+
+func TestOptional_ConstraintsApplyWhenPresent(t *testing.T) {
+	// Missing keys skip the constraints of their value.
+	_, err := schema.ParseJSON[ProfilePatch]([]byte(`{"token":"t"}`))
+	assertNoError(t, err)
+
+	// Supplied values are checked, zero values included.
+	_, err = schema.ParseJSON[ProfilePatch]([]byte(`{"token":"","name":"","age":0,"email":"nope","home":{"zip":"1"}}`))
+	ve := mustValidationErrors(t, err)
+	assertHasField(t, ve, "name")
+	assertHasField(t, ve, "age")
+	assertHasField(t, ve, "email")
+	assertHasField(t, ve, "home.city")
+	assertHasField(t, ve, "home.zip")
+	for _, e := range ve {
+		if e.Field == "token" {
+			t.Errorf("an empty token is present and should satisfy required: %v", e)
+		}
+	}
+}
+
+func TestOptional_Required(t *testing.T) {
+	_, err := schema.ParseJSON[ProfilePatch]([]byte(`{}`))
+	ve := mustValidationErrors(t, err)
+	if len(ve) != 1 || ve[0].Field != "token" || ve[0].Message != "field is required" {
+		t.Errorf("expected the missing token to be reported, got %v", ve)
+	}
+}
+
+func TestOptional_RejectsNull(t *testing.T) {
+	for _, decode := range []func([]byte) error{
+		func(b []byte) error { _, err := schema.ParseJSON[ProfilePatch](b); return err },
+		func(b []byte) error { _, err := schema.DecodeJSON[ProfilePatch](b); return err },
+	} {
+		ve := mustValidationErrors(t, decode([]byte(`{"token":"t","name":null}`)))
+		if len(ve) != 1 || ve[0].Field != "name" || ve[0].Message != "expected type string" {
+			t.Errorf("expected null to be rejected for an Optional, got %v", ve)
+		}
+	}
+}
+
+func TestOptional_Defaults(t *testing.T) {
+	p, err := schema.ParseJSON[ProfilePatch]([]byte(`{"token":"t"}`))
+	assertNoError(t, err)
+	if d, ok := p.Timeout.Get(); !ok || d != 30*time.Second {
+		t.Errorf("expected the default timeout, got %+v", p.Timeout)
+	}
+
+	p, err = schema.ParseJSON[ProfilePatch]([]byte(`{"token":"t","timeout":0}`))
+	assertNoError(t, err)
+	if d, ok := p.Timeout.Get(); !ok || d != 0 {
+		t.Errorf("an explicit zero should be kept, got %+v", p.Timeout)
+	}
+
+	_, err = schema.ParseJSON[ProfilePatch]([]byte(`{"token":"t","timeout":120000000000}`))
+	assertHasField(t, mustValidationErrors(t, err), "timeout")
+}
+
+func TestOptional_Marshal(t *testing.T) {
+	p := ProfilePatch{
+		Name:  schema.NewOptional("ann"),
+		Email: schema.Null[string](),
+		Home:  schema.NewNullable(Place{City: "Rome"}),
+	}
+	b, err := json.Marshal(p)
+	assertNoError(t, err)
+	want := `{"name":"ann","email":null,"home":{"city":"Rome","zip":""}}`
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
+
+func TestOptional_JSONSchema(t *testing.T) {
+	m, err := schema.ToJSONSchema[ProfilePatch]()
+	assertNoError(t, err)
+	props := m["properties"].(map[string]any)
+	if name := props["name"].(map[string]any); name["type"] != "string" || name["minLength"] != 2 {
+		t.Errorf("unexpected schema for name: %v", name)
+	}
+	if email := props["email"].(map[string]any); email["nullable"] != true || email["format"] != "email" {
+		t.Errorf("unexpected schema for email: %v", email)
+	}
+	if home := props["home"].(map[string]any); home["type"] != "object" || home["nullable"] != true {
+		t.Errorf("unexpected schema for home: %v", home)
+	}
+	if req, _ := m["required"].([]string); len(req) != 1 || req[0] != "token" {
+		t.Errorf("only token should be required, got %v", m["required"])
+	}
+}
+
+func TestOptional_ErrorPaths(t *testing.T) {
+	_, err := schema.ParseJSON[ProfilePatch]([]byte(`{"token":"t","age":"old","home":{"city":1}}`))
+	ve := mustValidationErrors(t, err)
+	assertHasField(t, ve, "age")
+	assertHasField(t, ve, "home.city")
+}
+
+func TestOptional_LintAndCheck(t *testing.T) {
+	assertNoError(t, schema.Lint[ProfilePatch]())
+	assertNoError(t, schema.Check[ProfilePatch]())
+}
+
+type Reminder struct {
+	Note schema.Optional[string] `json:"note,omitzero"`
+	Due  schema.Nullable[int]    `json:"due,omitzero"`
+}
+
+func TestOptional_RoundTrip(t *testing.T) {
+	for _, r := range []Reminder{
+		{},
+		{Note: schema.NewOptional("")},
+		{Due: schema.Null[int]()},
+		{Due: schema.NewNullable(0)},
+		{Note: schema.NewOptional("call"), Due: schema.NewNullable(3)},
+	} {
+		b, err := json.Marshal(r)
+		assertNoError(t, err)
+		var viaJSON Reminder
+		assertNoError(t, json.Unmarshal(b, &viaJSON))
+		viaSchema, err := schema.ParseJSON[Reminder](b)
+		assertNoError(t, err)
+		if viaJSON != r || viaSchema != r {
+			t.Errorf("%+v marshals as %s, which decodes as %+v and parses as %+v", r, b, viaJSON, viaSchema)
+		}
+	}
+}
+
+func TestOptional_LintOmitzero(t *testing.T) {
+	type Bare struct {
+		Note schema.Optional[string] `json:"note"`
+		Due  schema.Nullable[int]    `json:"due,omitempty"`
+	}
+	le, ok := schema.Lint[Bare]().(schema.LintErrors)
+	if !ok || len(le) != 2 || le[0].Field != "Note" || le[1].Field != "Due" {
+		t.Errorf("expected both fields to need omitzero, got %v", le)
+	}
+}
@@ -0,0 +1,84 @@
+package schema
+
+import "time"
+
+// Option configures the behaviour of [Validate], [ParseJSON] and the other
+// entry-points that accept options.
+type Option func(*options)
+
+// options is the resolved configuration for a single call.
+type options struct {
+	// now returns the current time for relative temporal constraints
+	// (`before=now`, `maxAge=18y`, ...).
+	now func() time.Time
+
+	// readOnly is the policy for input values of readOnly fields.
+	readOnly ReadOnlyPolicy
+
+	// strict makes Compile fail on Lint and Check findings.
+	strict bool
+
+	// reflective disables generated validators and default appliers.
+	reflective bool
+
+	// presence makes decoding tell present keys from zero values.
+	presence bool
+
+	// partial makes decoding leave the keys missing from objects alone,
+	// for ParseJSONPartial.
+	partial bool
+
+	// pruneInvalid makes PruneJSON remove optional fields that fail
+	// validation.
+	pruneInvalid bool
+
+	// rejectDuplicates makes decoding reject objects that repeat a key.
+	rejectDuplicates bool
+}
+
+// newOptions applies opts on top of the defaults.
+func newOptions(opts []Option) *options {
+	o := &options{now: time.Now}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithClock sets the clock used to evaluate relative temporal constraints
+// such as `before=now` or `maxAge=120y`. It defaults to time.Now and is
+// mostly useful to make validation deterministic in tests.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// Strict makes [Compile] fail when [Lint] or [Check] report problems in the
+// compiled type, instead of silently ignoring unknown or misapplied keywords
+// and unsatisfiable constraints. It has no effect on validation.
+func Strict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// Presence makes [ParseJSON], [ValidateJSON] and [DecodeJSON] follow the
+// JSON Schema notion of presence instead of treating zero values as absent:
+//
+//   - `required` is satisfied by any value for the key, including 0, false,
+//     "", [], {} and null, and a missing key is reported as required;
+//   - `default=` values fill in missing keys only, so explicit zeros are kept;
+//   - the constraints of a field apply to any value supplied for it, zero or
+//     not, and not at all when its key is missing;
+//   - `dependentRequired` looks at which keys are present.
+//
+// ParseJSON decodes with [DecodeJSON] in this mode, since only the decoder
+// sees which keys were present. Values that DecodeJSON hands to
+// encoding/json are handled as without Presence. It has no effect on
+// [Validate], which only sees Go values.
+func Presence() Option {
+	return func(o *options) {
+		o.presence = true
+	}
+}
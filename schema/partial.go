@@ -0,0 +1,60 @@
+package schema
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Paths is a set of JSON paths, written like the Field of a
+// [ValidationError]: "name", "address.city", "labels.env".
+type Paths map[string]struct{}
+
+// Has reports whether path is in the set.
+func (p Paths) Has(path string) bool {
+	_, ok := p[path]
+	return ok
+}
+
+// Sorted returns the paths in lexical order.
+func (p Paths) Sorted() []string {
+	paths := make([]string, 0, len(p))
+	for path := range p {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// ParseJSONPartial decodes a partial update, such as the body of a PATCH
+// request, into a value of type T and validates only what it contains:
+// keys missing from an object are left alone, so neither `required` nor
+// `default=` applies to them, while every value present is checked against
+// its constraints, zero values included, as with [Presence]. Objects are
+// partial at any depth; arrays replace the whole value, so their items are
+// checked in full.
+//
+// The returned Paths hold the path of every member present in the input
+// objects, the parents of nested members included, so that handlers can
+// apply exactly those changes. Paths are given with the JSON names of the
+// fields, and do not go into arrays.
+//
+//	patch, present, err := schema.ParseJSONPartial[User](body)
+//	if present.Has("address.city") {
+//		user.Address.City = patch.Address.City
+//	}
+func ParseJSONPartial[T any](data []byte, opts ...Option) (T, Paths, error) {
+	r, err := resolve(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		var zero T
+		return zero, nil, err
+	}
+	v, paths, err := decodePaths[T](r.plan, data, partialOptions(opts))
+	return v, paths, locateErrors(err, data)
+}
+
+// partialOptions resolves the options of a ParseJSONPartial call.
+func partialOptions(opts []Option) *options {
+	o := newOptions(opts)
+	o.presence, o.partial = true, true
+	return o
+}
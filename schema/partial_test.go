@@ -0,0 +1,91 @@
+package schema_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Subscriber struct {
+	_ struct{} `schema:"dependentRequired:phone=country"`
+
+	ID      string            `json:"id"      schema:"readOnly"`
+	Name    string            `json:"name"    schema:"required,minLength=2"`
+	Age     int               `json:"age"     schema:"required,minimum=18"`
+	Plan    string            `json:"plan"    schema:"enum=free|pro,default=free"`
+	Phone   string            `json:"phone"`
+	Country string            `json:"country"`
+	Address *Place            `json:"address" schema:"required"`
+	Labels  map[string]Parcel `json:"labels"`
+	Items   []Parcel          `json:"items"`
+}
+
+func TestParseJSONPartial_OnlyPresentFields(t *testing.T) {
+	a, present, err := schema.ParseJSONPartial[Subscriber]([]byte(`{"age":30,"address":{"zip":"12345"}}`))
+	assertNoError(t, err)
+	if a.Age != 30 || a.Address == nil || a.Address.Zip != "12345" {
+		t.Errorf("unexpected value: %+v", a)
+	}
+	if a.Plan != "" || a.Address.City != "" {
+		t.Errorf("defaults should not be applied to missing keys: %+v", a)
+	}
+	if got, want := present.Sorted(), []string{"address", "address.zip", "age"}; !slices.Equal(got, want) {
+		t.Errorf("got paths %v, want %v", got, want)
+	}
+	if !present.Has("address.zip") || present.Has("name") {
+		t.Error("Has disagrees with the paths")
+	}
+}
+
+func TestParseJSONPartial_PresentValuesAreChecked(t *testing.T) {
+	_, _, err := schema.ParseJSONPartial[Subscriber]([]byte(`{"name":"","age":0,"plan":"gold","address":{"zip":"x"},"labels":{"a":{"qty":0}}}`))
+	ve := mustValidationErrors(t, err)
+	for _, f := range []string{"name", "age", "plan", "address.zip", "labels.a.qty"} {
+		assertHasField(t, ve, f)
+	}
+	if len(ve) != 5 {
+		t.Errorf("expected 5 errors, got %v", ve)
+	}
+}
+
+func TestParseJSONPartial_ArrayItemsAreComplete(t *testing.T) {
+	a, present, err := schema.ParseJSONPartial[Subscriber]([]byte(`{"items":[{"sku":"abc"},{"qty":2}]}`))
+	ve := mustValidationErrors(t, err)
+	if len(ve) != 1 || ve[0].Field != "items[1].sku" || ve[0].Message != "field is required" {
+		t.Errorf("expected the missing sku of the second item, got %v", ve)
+	}
+	if a.Items[0].Qty != 1 {
+		t.Errorf("expected the default qty in the first item, got %+v", a.Items[0])
+	}
+	if got := present.Sorted(); !slices.Equal(got, []string{"items"}) {
+		t.Errorf("paths should not go into arrays, got %v", got)
+	}
+}
+
+func TestParseJSONPartial_Keys(t *testing.T) {
+	_, present, err := schema.ParseJSONPartial[Subscriber]([]byte(`{"NAME":"ann","labels":{"env":{"qty":2}},"phone":"1"}`))
+	// dependentRequired, like required, is not checked in a partial update.
+	assertNoError(t, err)
+	if got, want := present.Sorted(), []string{"labels", "labels.env", "labels.env.qty", "name", "phone"}; !slices.Equal(got, want) {
+		t.Errorf("got paths %v, want %v", got, want)
+	}
+
+	_, present, err = schema.ParseJSONPartial[Subscriber]([]byte(`{"id":"x","name":"ann"}`))
+	ve := mustValidationErrors(t, err)
+	if len(ve) != 1 || ve[0].Message != "field is read-only" {
+		t.Errorf("expected the read-only id to be rejected, got %v", ve)
+	}
+	if present.Has("id") {
+		t.Error("a rejected read-only field should not be reported as present")
+	}
+}
+
+func TestParseJSONPartial_Compiled(t *testing.T) {
+	s := schema.MustCompile[Subscriber]()
+	a, present, err := s.ParseJSONPartial([]byte(`{"name":"bob"}`))
+	assertNoError(t, err)
+	if a.Name != "bob" || !present.Has("name") || len(present) != 1 {
+		t.Errorf("unexpected result: %+v %v", a, present)
+	}
+}
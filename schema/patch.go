@@ -0,0 +1,811 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7386) to the JSON form of
+// current and parses the result with [ParseJSON], so that the patched value
+// is checked, and filled in with defaults, like any other input. A merge
+// patch mirrors the document, so the paths of the errors are the same in
+// both. Its keys match struct fields case-insensitively, like ParseJSON's.
+//
+// The readOnly values of current are kept as they are, while the patch is
+// subject to the [WithReadOnly] policy: by default, a patch that sets or
+// removes a readOnly field is rejected, also when it replaces or removes an
+// object holding one. With DropReadOnly, such values are restored, unless
+// the object holding them is removed. On error, current is returned
+// unchanged.
+//
+//	user, err = schema.ApplyMergePatch(user, body)
+func ApplyMergePatch[T any](current T, patch []byte, opts ...Option) (T, error) {
+	r, err := resolve(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return current, err
+	}
+	o := newOptions(opts)
+	if patch, err = checkReadOnly(patch, r.fs, o); err != nil {
+		return current, err
+	}
+	p, err := decodePatchValue(patch)
+	if err != nil {
+		return current, err
+	}
+	doc, err := patchTarget(current)
+	if err != nil {
+		return current, err
+	}
+
+	before := copyPatchValue(doc)
+	doc = mergePatch(doc, p, r.fs)
+	if errs := keepReadOnly(before, doc, r.fs, "", o); errs != nil {
+		return current, errs
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return current, err
+	}
+	v, err := parse[T](data, patchedOptions(opts))
+	if err != nil {
+		return current, err
+	}
+	return v, nil
+}
+
+// ApplyJSONPatch applies a JSON Patch (RFC 6902) to the JSON form of current
+// and parses the result with [ParseJSON], so that the patched value is
+// checked, and filled in with defaults, like any other input. The
+// operations add, remove, replace, move, copy and test are supported, and
+// are applied atomically: if one fails, none is.
+//
+// Errors point into the patch: an operation that cannot be applied is
+// reported at "[i].op", "[i].path", "[i].from" or "[i].value", and a
+// constraint violation at the operation that last changed the offending
+// value, e.g. "[2].value.city" for the city of an address added by the
+// third operation, or "[0].path" for a required field it removed.
+// Violations that no operation explains keep the path of the document.
+//
+// The readOnly values of current are kept as they are, while the patch is
+// subject to the [WithReadOnly] policy: by default, an operation that
+// changes a readOnly field is rejected, also when it replaces or removes an
+// object holding one, the document root included. With DropReadOnly, an
+// operation on a readOnly field is skipped, and values changed through
+// their object are restored, unless the object is removed. On error,
+// current is returned unchanged.
+//
+//	user, err = schema.ApplyJSONPatch(user, body)
+func ApplyJSONPatch[T any](current T, patch []byte, opts ...Option) (T, error) {
+	r, err := resolve(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return current, err
+	}
+	fs := r.fs
+	ops, err := parseJSONPatch(patch)
+	if err != nil {
+		return current, err
+	}
+	doc, err := patchTarget(current)
+	if err != nil {
+		return current, err
+	}
+
+	o := newOptions(opts)
+	before := copyPatchValue(doc)
+	var applied []patchOp
+	for _, op := range ops {
+		skip, err := op.checkReadOnly(fs, o)
+		if err != nil {
+			return current, err
+		}
+		if skip {
+			continue
+		}
+		if doc, err = op.apply(doc); err != nil {
+			return current, err
+		}
+		applied = append(applied, op)
+	}
+	if errs := keepReadOnly(before, doc, fs, "", o); errs != nil {
+		return current, traceToOps(errs, applied)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return current, err
+	}
+	v, err := parse[T](data, patchedOptions(opts))
+	if err != nil {
+		var ve ValidationErrors
+		if errors.As(err, &ve) {
+			err = traceToOps(ve, applied)
+		}
+		return current, err
+	}
+	return v, nil
+}
+
+// patchedOptions returns the options to parse a patched document with. Its
+// readOnly values come from current, since the patch is checked already.
+func patchedOptions(opts []Option) []Option {
+	return append(slices.Clip(opts), WithReadOnly(AllowReadOnly))
+}
+
+// patchTarget returns the JSON form of current as a tree of maps, slices and
+// json.Number values. Unset Optional and Nullable fields are left out of
+// it, as if tagged `omitzero`, and the members collected by
+// additionalProperties fields are put back into their objects.
+func patchTarget(current any) (any, error) {
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, fmt.Errorf("goschema: marshalling the value to patch: %w", err)
+	}
+	doc, err := decodePatchValue(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := fixTarget(doc, reflect.ValueOf(current)); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// fixTarget makes doc, the JSON form of v, parse back into v. The members
+// of the unset Optional and Nullable fields of v, which marshal as null,
+// are removed: parsed back, an Optional would reject the null and a
+// Nullable would become null. The entries of additionalProperties fields,
+// which encoding/json skips, are added as members of their object.
+func fixTarget(doc any, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if value, set, _, ok := unwrap(v); ok {
+		if set {
+			return fixTarget(doc, value)
+		}
+		return nil
+	}
+	if isMarshaler(v.Type()) {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return nil
+		}
+		for i := range v.NumField() {
+			f := v.Type().Field(i)
+			switch {
+			case f.Anonymous && f.Tag.Get("json") == "":
+				// Promoted fields are members of the same object.
+				if err := fixTarget(obj, v.Field(i)); err != nil {
+					return err
+				}
+				continue
+			case !f.IsExported():
+				continue
+			}
+			if ok, _ := isExtraField(f); ok {
+				if err := addExtras(obj, v.Field(i)); err != nil {
+					return err
+				}
+				continue
+			}
+			name := jsonFieldName(f)
+			member, ok := obj[name]
+			if !ok {
+				continue
+			}
+			if _, set, _, ok := unwrap(v.Field(i)); ok && !set {
+				delete(obj, name)
+				continue
+			}
+			if err := fixTarget(member, v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := doc.([]any)
+		if !ok || len(items) != v.Len() {
+			return nil
+		}
+		for i, item := range items {
+			if err := fixTarget(item, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		obj, ok := doc.(map[string]any)
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		for iter := v.MapRange(); iter.Next(); {
+			if member, ok := obj[iter.Key().String()]; ok {
+				if err := fixTarget(member, iter.Value()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addExtras adds the entries of extra, an additionalProperties field, to
+// obj, the JSON object they were collected from.
+func addExtras(obj map[string]any, extra reflect.Value) error {
+	if extra.Len() == 0 {
+		return nil
+	}
+	data, err := json.Marshal(extra.Interface())
+	if err != nil {
+		return fmt.Errorf("goschema: marshalling the value to patch: %w", err)
+	}
+	members, err := decodePatchValue(data)
+	if err != nil {
+		return err
+	}
+	for k, member := range members.(map[string]any) {
+		// Members are only collected when they match no field; should one
+		// have the name of a field anyway, the field wins.
+		if _, ok := obj[k]; !ok {
+			obj[k] = member
+		}
+	}
+	return nil
+}
+
+// decodePatchValue decodes a JSON document into a tree of maps, slices and
+// json.Number values.
+func decodePatchValue(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, wrapUnmarshalError(err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, ValidationErrors{{Field: "", Message: "invalid JSON: unexpected data after the top-level value"}}
+	}
+	return v, nil
+}
+
+// mergePatch applies the merge patch p to target, which fs describes,
+// following RFC 7386. Like ParseJSON, it matches the keys of struct fields
+// case-insensitively: they are mapped onto the JSON name of their field,
+// and a key that matches the name exactly wins over the others.
+func mergePatch(target, p any, fs FieldSchema) any {
+	members, ok := p.(map[string]any)
+	if !ok {
+		return p
+	}
+	obj, ok := target.(map[string]any)
+	if !ok {
+		obj = map[string]any{}
+	}
+	for _, k := range sortedKeys(members) {
+		v := members[k]
+		name, sub := k, FieldSchema{}
+		switch {
+		case fs.Nested != nil:
+			if n, field, ok := lookupField(fs.Nested, k); ok {
+				if _, exact := members[n]; exact && n != k {
+					continue
+				}
+				name, sub = n, field
+			}
+		case fs.Map != nil && fs.Map.Values != nil:
+			sub = *fs.Map.Values
+		}
+		if v == nil {
+			delete(obj, name)
+			continue
+		}
+		obj[name] = mergePatch(obj[name], v, sub)
+	}
+	return obj
+}
+
+// patchOp is an operation of a JSON Patch.
+type patchOp struct {
+	index    int
+	op       string
+	path     []string
+	from     []string
+	value    any
+	hasValue bool
+
+	// target and source are the paths of path and from in the document,
+	// written like the Field of a ValidationError, once the operation is
+	// applied.
+	target, source string
+}
+
+// parseJSONPatch decodes and checks the operations of a JSON Patch.
+func parseJSONPatch(data []byte) ([]patchOp, error) {
+	v, err := decodePatchValue(data)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := v.([]any)
+	if !ok {
+		return nil, ValidationErrors{{Field: "", Message: "invalid JSON Patch: expected an array of operations"}}
+	}
+
+	ops := make([]patchOp, len(list))
+	for i, item := range list {
+		fail := func(member, format string, args ...any) error {
+			return ValidationErrors{{
+				Field:   fmt.Sprintf("[%d]%s", i, member),
+				Message: fmt.Sprintf(format, args...),
+			}}
+		}
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, fail("", "operation must be an object")
+		}
+		op := patchOp{index: i}
+		if op.op, ok = m["op"].(string); !ok {
+			return nil, fail(".op", "op must be a string")
+		}
+		switch op.op {
+		case "add", "remove", "replace", "move", "copy", "test":
+		default:
+			return nil, fail(".op", "unknown operation %q", op.op)
+		}
+
+		pointer, ok := m["path"].(string)
+		if !ok {
+			return nil, fail(".path", "path must be a string")
+		}
+		if op.path, err = parsePointer(pointer); err != nil {
+			return nil, fail(".path", "%v", err)
+		}
+		if op.op == "move" || op.op == "copy" {
+			pointer, ok := m["from"].(string)
+			if !ok {
+				return nil, fail(".from", "from must be a string")
+			}
+			if op.from, err = parsePointer(pointer); err != nil {
+				return nil, fail(".from", "%v", err)
+			}
+			if op.op == "move" && len(op.from) < len(op.path) && slices.Equal(op.from, op.path[:len(op.from)]) {
+				return nil, fail(".from", "cannot move a value into one of its children")
+			}
+		}
+		op.value, op.hasValue = m["value"]
+		if !op.hasValue && (op.op == "add" || op.op == "replace" || op.op == "test") {
+			return nil, fail(".value", "%s requires a value", op.op)
+		}
+		ops[i] = op
+	}
+	return ops, nil
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped tokens.
+func parsePointer(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("JSON pointer %q must start with /", s)
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, tok := range tokens {
+		tokens[i] = pointerUnescaper.Replace(tok)
+	}
+	return tokens, nil
+}
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// checkReadOnly applies the readOnly policy to op. It reports whether the
+// operation is to be skipped, or the errors under RejectReadOnly.
+func (op *patchOp) checkReadOnly(fs FieldSchema, o *options) (bool, error) {
+	if o.readOnly == AllowReadOnly || op.op == "test" {
+		return false, nil
+	}
+	member := ".path"
+	sub, readOnly, ok := schemaAt(fs, op.path)
+	if !readOnly && op.op == "move" {
+		member = ".from"
+		_, readOnly, _ = schemaAt(fs, op.from)
+	}
+	if readOnly {
+		if o.readOnly == DropReadOnly {
+			return true, nil
+		}
+		return false, ValidationErrors{{Field: fmt.Sprintf("[%d]%s", op.index, member), Message: "field is read-only", Keyword: "readOnly"}}
+	}
+	if !ok || !op.hasValue || op.op == "remove" {
+		return false, nil
+	}
+
+	// The value may set readOnly fields below the path.
+	data, err := json.Marshal(op.value)
+	if err != nil {
+		return false, err
+	}
+	kept, err := checkReadOnly(data, sub, o)
+	if err != nil {
+		var ve ValidationErrors
+		if errors.As(err, &ve) {
+			for i := range ve {
+				ve[i].Field = subPath(fmt.Sprintf("[%d].value", op.index), ve[i].Field)
+			}
+		}
+		return false, err
+	}
+	if !bytes.Equal(kept, data) {
+		if op.value, err = decodePatchValue(kept); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// keepReadOnly applies the readOnly policy to the values of readOnly fields
+// that a patch changed through an object holding them, by comparing before,
+// the JSON form of current, with after, the patched document at path,
+// which fs describes. Under RejectReadOnly it returns an error for each
+// changed value; under DropReadOnly it restores them in after, as long as
+// their object is still there.
+func keepReadOnly(before, after any, fs FieldSchema, path string, o *options) ValidationErrors {
+	if o.readOnly == AllowReadOnly || !schemaHas(fs, isReadOnly) {
+		return nil
+	}
+	var errs ValidationErrors
+	switch {
+	case fs.Nested != nil:
+		b, _ := before.(map[string]any)
+		a, _ := after.(map[string]any)
+		keys := sortedKeys(b)
+		for _, key := range sortedKeys(a) {
+			if _, ok := b[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		for _, key := range keys {
+			name, field, ok := lookupField(fs.Nested, key)
+			if !ok {
+				continue
+			}
+			bv, inBefore := b[key]
+			av, inAfter := a[key]
+			if !field.ReadOnly {
+				errs = append(errs, keepReadOnly(bv, av, field, fieldPath(path, name), o)...)
+				continue
+			}
+			if inBefore == inAfter && jsonEqual(bv, av) {
+				continue
+			}
+			switch {
+			case o.readOnly == RejectReadOnly:
+				errs = append(errs, ValidationError{Field: fieldPath(path, name), Message: "field is read-only", Keyword: "readOnly"})
+			case a == nil:
+				// The object is gone, and its readOnly values with it.
+			case inBefore:
+				a[key] = bv
+			default:
+				delete(a, key)
+			}
+		}
+	case fs.Map != nil && fs.Map.Values != nil:
+		b, _ := before.(map[string]any)
+		a, _ := after.(map[string]any)
+		for _, key := range sortedKeys(b) {
+			errs = append(errs, keepReadOnly(b[key], a[key], *fs.Map.Values, fieldPath(path, key), o)...)
+		}
+	case fs.Array != nil && fs.Array.Items != nil:
+		b, _ := before.([]any)
+		a, _ := after.([]any)
+		for i, item := range b {
+			var patched any
+			if i < len(a) {
+				patched = a[i]
+			}
+			errs = append(errs, keepReadOnly(item, patched, *fs.Array.Items, fmt.Sprintf("%s[%d]", path, i), o)...)
+		}
+	}
+	return errs
+}
+
+// schemaAt returns the schema of the value at the JSON Pointer tokens and
+// whether it is, or is inside, a readOnly field. The last boolean is false
+// if the schema does not say what is there.
+func schemaAt(fs FieldSchema, tokens []string) (FieldSchema, bool, bool) {
+	readOnly := false
+	for _, tok := range tokens {
+		switch {
+		case fs.Nested != nil:
+			_, field, ok := lookupField(fs.Nested, tok)
+			if !ok {
+				return fs, readOnly, false
+			}
+			fs = field
+		case fs.Map != nil && fs.Map.Values != nil:
+			fs = *fs.Map.Values
+		case fs.Array != nil && fs.Array.Items != nil:
+			fs = *fs.Array.Items
+		default:
+			return fs, readOnly, false
+		}
+		readOnly = readOnly || fs.ReadOnly
+	}
+	return fs, readOnly, true
+}
+
+// apply applies op to doc and returns the new document.
+func (op *patchOp) apply(doc any) (any, error) {
+	fail := func(member string, err error) error {
+		return ValidationErrors{{Field: fmt.Sprintf("[%d]%s", op.index, member), Message: err.Error()}}
+	}
+	op.target = documentPath(doc, op.path)
+
+	var err error
+	switch op.op {
+	case "add", "replace", "remove":
+		if doc, err = patchAt(doc, op.path, op.op, op.value); err != nil {
+			return nil, fail(".path", err)
+		}
+	case "move", "copy":
+		v, err := valueAt(doc, op.from)
+		if err != nil {
+			return nil, fail(".from", err)
+		}
+		if op.op == "move" {
+			op.source = documentPath(doc, op.from)
+			if doc, err = patchAt(doc, op.from, "remove", nil); err != nil {
+				return nil, fail(".from", err)
+			}
+			op.target = documentPath(doc, op.path)
+		} else {
+			v = copyPatchValue(v)
+		}
+		if doc, err = patchAt(doc, op.path, "add", v); err != nil {
+			return nil, fail(".path", err)
+		}
+	case "test":
+		v, err := valueAt(doc, op.path)
+		if err != nil {
+			return nil, fail(".path", err)
+		}
+		if !jsonEqual(v, op.value) {
+			return nil, ValidationErrors{{
+				Field:   fmt.Sprintf("[%d].value", op.index),
+				Message: "test failed: the value differs",
+				Value:   v,
+			}}
+		}
+	}
+	return doc, nil
+}
+
+// patchAt adds, replaces or removes the value at the JSON Pointer tokens of
+// node, and returns the new node.
+func patchAt(node any, tokens []string, op string, value any) (any, error) {
+	if len(tokens) == 0 {
+		if op == "remove" {
+			return nil, errors.New("cannot remove the whole document")
+		}
+		return value, nil
+	}
+	tok, rest := tokens[0], tokens[1:]
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[tok]
+		switch {
+		case !ok && (len(rest) > 0 || op != "add"):
+			return nil, fmt.Errorf("member %q does not exist", tok)
+		case len(rest) > 0:
+			c, err := patchAt(child, rest, op, value)
+			if err != nil {
+				return nil, err
+			}
+			n[tok] = c
+		case op == "remove":
+			delete(n, tok)
+		default:
+			n[tok] = value
+		}
+		return n, nil
+	case []any:
+		if tok == "-" && len(rest) == 0 && op == "add" {
+			return append(n, value), nil
+		}
+		limit := len(n)
+		if len(rest) == 0 && op == "add" {
+			limit++
+		}
+		i, err := arrayIndex(tok, limit)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case len(rest) > 0:
+			c, err := patchAt(n[i], rest, op, value)
+			if err != nil {
+				return nil, err
+			}
+			n[i] = c
+		case op == "add":
+			return slices.Insert(n, i, value), nil
+		case op == "remove":
+			return slices.Delete(n, i, i+1), nil
+		default:
+			n[i] = value
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("cannot %s %q: the parent is not an object or array", op, tok)
+}
+
+// valueAt returns the value at the JSON Pointer tokens of node.
+func valueAt(node any, tokens []string) (any, error) {
+	for _, tok := range tokens {
+		switch n := node.(type) {
+		case map[string]any:
+			child, ok := n[tok]
+			if !ok {
+				return nil, fmt.Errorf("member %q does not exist", tok)
+			}
+			node = child
+		case []any:
+			i, err := arrayIndex(tok, len(n))
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("member %q does not exist", tok)
+		}
+	}
+	return node, nil
+}
+
+// arrayIndex parses an array index token, which must be below limit.
+func arrayIndex(tok string, limit int) (int, error) {
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || (len(tok) > 1 && tok[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	if i >= limit {
+		return 0, fmt.Errorf("array index %d is out of range", i)
+	}
+	return i, nil
+}
+
+// documentPath converts JSON Pointer tokens into a path written like the
+// Field of a ValidationError, using doc to tell array indices from keys.
+func documentPath(doc any, tokens []string) string {
+	path := ""
+	for _, tok := range tokens {
+		switch n := doc.(type) {
+		case []any:
+			if tok == "-" {
+				tok = strconv.Itoa(len(n))
+			}
+			path = fmt.Sprintf("%s[%s]", path, tok)
+			if i, err := strconv.Atoi(tok); err == nil && i >= 0 && i < len(n) {
+				doc = n[i]
+			} else {
+				doc = nil
+			}
+		case map[string]any:
+			path = fieldPath(path, tok)
+			doc = n[tok]
+		default:
+			path = fieldPath(path, tok)
+			doc = nil
+		}
+	}
+	return path
+}
+
+// copyPatchValue returns a deep copy of a decoded JSON value.
+func copyPatchValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = copyPatchValue(e)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, e := range v {
+			s[i] = copyPatchValue(e)
+		}
+		return s
+	}
+	return v
+}
+
+// jsonEqual reports whether two decoded JSON values are equal, numbers
+// being compared by their value as RFC 6902 requires.
+func jsonEqual(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, okA := new(big.Rat).SetString(string(a))
+		y, okB := new(big.Rat).SetString(string(b))
+		return okA && okB && x.Cmp(y) == 0
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			w, ok := b[k]
+			if !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		return ok && slices.EqualFunc(a, b, jsonEqual)
+	}
+	return a == b
+}
+
+// traceToOps rewrites the paths of errs found in a patched document to point
+// at the operations that last changed the values in error.
+func traceToOps(errs ValidationErrors, ops []patchOp) ValidationErrors {
+	for i, e := range errs {
+		for _, op := range slices.Backward(ops) {
+			if op.op == "test" {
+				continue
+			}
+			if (op.op == "add" || op.op == "replace") && within(e.Field, op.target) {
+				rest := strings.TrimPrefix(strings.TrimPrefix(e.Field, op.target), ".")
+				errs[i].Field = subPath(fmt.Sprintf("[%d].value", op.index), rest)
+				break
+			}
+			if within(e.Field, op.target) || within(op.target, e.Field) {
+				errs[i].Field = fmt.Sprintf("[%d].path", op.index)
+				break
+			}
+			if op.op == "move" && (within(e.Field, op.source) || within(op.source, e.Field)) {
+				errs[i].Field = fmt.Sprintf("[%d].from", op.index)
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// within reports whether path is prefix or lies below it.
+func within(path, prefix string) bool {
+	rest, ok := strings.CutPrefix(path, prefix)
+	return ok && (prefix == "" || isSubPath(rest))
+}
+
+// isSubPath reports whether rest, what follows a path in a longer one,
+// starts a child path.
+func isSubPath(rest string) bool {
+	return rest == "" || rest[0] == '.' || rest[0] == '['
+}
+
+// subPath joins a path and a path relative to it.
+func subPath(path, rel string) string {
+	if rel == "" || rel[0] == '[' {
+		return path + rel
+	}
+	return path + "." + rel
+}
@@ -0,0 +1,330 @@
+package schema_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Contact struct {
+	ID      string   `json:"id"      schema:"readOnly"`
+	Name    string   `json:"name"    schema:"required,minLength=2"`
+	Age     int      `json:"age"     schema:"minimum=18"`
+	Tags    []string `json:"tags"    schema:"maxItems=3,items:minLength=2"`
+	Address *Place   `json:"address"`
+	Plan    string   `json:"plan"    schema:"enum=free|pro,default=free"`
+}
+
+var ann = Contact{ID: "c1", Name: "Ann", Age: 30, Tags: []string{"vip"}, Plan: "pro"}
+
+func TestApplyMergePatch(t *testing.T) {
+	got, err := schema.ApplyMergePatch(ann, []byte(`{"age":31,"tags":null,"address":{"city":"Rome"}}`))
+	assertNoError(t, err)
+	want := Contact{ID: "c1", Name: "Ann", Age: 31, Address: &Place{City: "Rome", Zip: "00000"}, Plan: "pro"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// Removing a field brings back its default.
+	got, err = schema.ApplyMergePatch(ann, []byte(`{"plan":null}`))
+	assertNoError(t, err)
+	if got.Plan != "free" {
+		t.Errorf("expected the default plan, got %q", got.Plan)
+	}
+}
+
+func TestApplyMergePatch_KeyCase(t *testing.T) {
+	// Keys match fields case-insensitively, as in ParseJSON.
+	got, err := schema.ApplyMergePatch(ann, []byte(`{"Name":"Bea","ADDRESS":{"City":"Rome"},"Tags":null}`))
+	assertNoError(t, err)
+	want := Contact{ID: "c1", Name: "Bea", Age: 30, Address: &Place{City: "Rome", Zip: "00000"}, Plan: "pro"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// The exact name wins.
+	got, err = schema.ApplyMergePatch(ann, []byte(`{"NAME":"Cy","name":"Dee"}`))
+	assertNoError(t, err)
+	if got.Name != "Dee" {
+		t.Errorf("expected the exact key to win, got %q", got.Name)
+	}
+
+	_, err = schema.ApplyMergePatch(ann, []byte(`{"Id":"c2"}`))
+	assertHasField(t, mustValidationErrors(t, err), "id")
+}
+
+func TestApplyMergePatch_Invalid(t *testing.T) {
+	got, err := schema.ApplyMergePatch(ann, []byte(`{"name":null,"age":12,"address":{"zip":"x"}}`))
+	ve := mustValidationErrors(t, err)
+	assertHasField(t, ve, "name")
+	assertHasField(t, ve, "age")
+	assertHasField(t, ve, "address.city")
+	assertHasField(t, ve, "address.zip")
+	if !reflect.DeepEqual(got, ann) {
+		t.Errorf("current should be returned unchanged on error, got %+v", got)
+	}
+
+	_, err = schema.ApplyMergePatch(ann, []byte(`{"age":`))
+	mustValidationErrors(t, err)
+}
+
+func TestApplyMergePatch_ReadOnly(t *testing.T) {
+	_, err := schema.ApplyMergePatch(ann, []byte(`{"id":"c2"}`))
+	ve := mustValidationErrors(t, err)
+	if len(ve) != 1 || ve[0].Field != "id" || ve[0].Message != "field is read-only" {
+		t.Errorf("expected the id to be rejected, got %v", ve)
+	}
+
+	got, err := schema.ApplyMergePatch(ann, []byte(`{"id":"c2","age":40}`), schema.WithReadOnly(schema.DropReadOnly))
+	assertNoError(t, err)
+	if got.ID != "c1" || got.Age != 40 {
+		t.Errorf("expected the id to be kept and the age patched, got %+v", got)
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	patch := `[
+		{"op":"test","path":"/name","value":"Ann"},
+		{"op":"replace","path":"/age","value":31},
+		{"op":"add","path":"/tags/-","value":"new"},
+		{"op":"add","path":"/tags/0","value":"top"},
+		{"op":"add","path":"/address","value":{"city":"Oslo"}},
+		{"op":"copy","from":"/address/city","path":"/address/zip"},
+		{"op":"move","from":"/address/zip","path":"/plan"},
+		{"op":"replace","path":"/plan","value":"free"},
+		{"op":"remove","path":"/tags/1"}
+	]`
+	got, err := schema.ApplyJSONPatch(ann, []byte(patch))
+	assertNoError(t, err)
+	want := Contact{ID: "c1", Name: "Ann", Age: 31, Tags: []string{"top", "new"}, Address: &Place{City: "Oslo", Zip: "00000"}, Plan: "free"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestApplyJSONPatch_ErrorsPointIntoThePatch(t *testing.T) {
+	for name, tc := range map[string]struct {
+		patch, field, message string
+	}{
+		"constraint in a value": {
+			patch: `[{"op":"replace","path":"/age","value":31},{"op":"add","path":"/address","value":{"city":"Oslo","zip":"x"}}]`,
+			field: "[1].value.zip", message: `must match pattern "^[0-9]{5}$"`,
+		},
+		"replaced value": {
+			patch: `[{"op":"replace","path":"/age","value":3}]`,
+			field: "[0].value", message: "must be >= 18",
+		},
+		"removed required field": {
+			patch: `[{"op":"remove","path":"/name"}]`,
+			field: "[0].path", message: "field is required",
+		},
+		"array item": {
+			patch: `[{"op":"add","path":"/tags/-","value":"x"}]`,
+			field: "[0].value", message: "must be at least 2 characters long",
+		},
+		"array length": {
+			patch: `[{"op":"add","path":"/tags/-","value":"ab"},{"op":"add","path":"/tags/-","value":"cd"},{"op":"add","path":"/tags/0","value":"ef"}]`,
+			field: "[2].path", message: "must have at most 3 items",
+		},
+		"failed test": {
+			patch: `[{"op":"replace","path":"/age","value":40},{"op":"test","path":"/age","value":4e1},{"op":"test","path":"/name","value":"Bob"}]`,
+			field: "[2].value", message: "test failed: the value differs",
+		},
+		"null parent": {
+			patch: `[{"op":"replace","path":"/address/city","value":"Oslo"}]`,
+			field: "[0].path", message: `cannot replace "city": the parent is not an object or array`,
+		},
+		"index out of range": {
+			patch: `[{"op":"remove","path":"/tags/3"}]`,
+			field: "[0].path", message: "array index 3 is out of range",
+		},
+		"unknown op": {
+			patch: `[{"op":"merge","path":"/age"}]`,
+			field: "[0].op", message: `unknown operation "merge"`,
+		},
+		"missing value": {
+			patch: `[{"op":"add","path":"/age"}]`,
+			field: "[0].value", message: "add requires a value",
+		},
+		"read-only field": {
+			patch: `[{"op":"replace","path":"/id","value":"c2"}]`,
+			field: "[0].path", message: "field is read-only",
+		},
+		"read-only field in a value": {
+			patch: `[{"op":"replace","path":"","value":{"id":"c2","name":"Bob"}}]`,
+			field: "[0].value.id", message: "field is read-only",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := schema.ApplyJSONPatch(ann, []byte(tc.patch))
+			ve := mustValidationErrors(t, err)
+			if len(ve) != 1 || ve[0].Field != tc.field || !strings.HasPrefix(ve[0].Message, tc.message) {
+				t.Errorf("got %v, want %s: %s", ve, tc.field, tc.message)
+			}
+			if !reflect.DeepEqual(got, ann) {
+				t.Errorf("current should be returned unchanged on error, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestApplyJSONPatch_Move(t *testing.T) {
+	_, err := schema.ApplyJSONPatch(ann, []byte(`[{"op":"move","from":"/name","path":"/plan"}]`))
+	ve := mustValidationErrors(t, err)
+	if len(ve) != 2 {
+		t.Errorf("expected 2 errors, got %v", ve)
+	}
+	// The source of a move is traced to "from", its target to "path".
+	assertHasField(t, ve, "[0].from")
+	assertHasField(t, ve, "[0].path")
+}
+
+func TestApplyJSONPatch_DropReadOnly(t *testing.T) {
+	patch := `[{"op":"replace","path":"/id","value":"c2"},{"op":"replace","path":"","value":{"id":"c3","name":"Bob","age":20}}]`
+	got, err := schema.ApplyJSONPatch(ann, []byte(patch), schema.WithReadOnly(schema.DropReadOnly))
+	assertNoError(t, err)
+	// The root is replaced, but the id of ann is kept.
+	if got.ID != "c1" || got.Name != "Bob" {
+		t.Errorf("expected the read-only values of the patch to be dropped, got %+v", got)
+	}
+}
+
+type Ledger struct {
+	ID      string            `json:"id"      schema:"readOnly"`
+	Count   int               `json:"count"`
+	Owner   *Stamp            `json:"owner"`
+	Entries []Stamp           `json:"entries"`
+	ByName  map[string]Stamp  `json:"by_name"`
+	Notes   map[string]string `json:"notes"`
+}
+
+type Stamp struct {
+	By   string `json:"by"   schema:"readOnly"`
+	Note string `json:"note"`
+}
+
+var ledger = Ledger{
+	ID:      "x",
+	Count:   1,
+	Owner:   &Stamp{By: "ann"},
+	Entries: []Stamp{{By: "bob"}},
+	ByName:  map[string]Stamp{"a": {By: "cid"}},
+}
+
+func TestApplyPatch_ReadOnlyAncestors(t *testing.T) {
+	for patch, want := range map[string]string{
+		`[{"op":"replace","path":"/owner","value":{"note":"n"}}]`:   "[0].value.by",
+		`[{"op":"remove","path":"/owner"}]`:                         "[0].path",
+		`[{"op":"replace","path":"/entries","value":[]}]`:           "[0].value[0].by",
+		`[{"op":"remove","path":"/by_name/a"}]`:                     "[0].path",
+		`[{"op":"move","from":"/owner","path":"/notes"}]`:           "[0].from",
+		`[{"op":"replace","path":"/entries/0","value":{"by":"x"}}]`: "[0].value.by",
+	} {
+		got, err := schema.ApplyJSONPatch(ledger, []byte(patch))
+		ve := mustValidationErrors(t, err)
+		if len(ve) != 1 || ve[0].Field != want || ve[0].Message != "field is read-only" {
+			t.Errorf("%s: got %v, want a read-only error at %s", patch, ve, want)
+		}
+		if !reflect.DeepEqual(got, ledger) {
+			t.Errorf("%s: current should be returned unchanged, got %+v", patch, got)
+		}
+	}
+
+	// Replacing the root drops every readOnly value.
+	_, err := schema.ApplyJSONPatch(ledger, []byte(`[{"op":"add","path":"","value":{"count":3}}]`))
+	ve := mustValidationErrors(t, err)
+	for _, f := range []string{"[0].value.id", "[0].value.owner.by", "[0].value.entries[0].by", "[0].value.by_name.a.by"} {
+		assertHasField(t, ve, f)
+	}
+
+	for patch, want := range map[string]string{
+		`{"owner":null}`:         "owner.by",
+		`{"owner":{"by":null}}`:  "owner.by",
+		`{"entries":[]}`:         "entries[0].by",
+		`{"by_name":{"a":null}}`: "by_name.a.by",
+		`[]`:                     "id",
+	} {
+		_, err = schema.ApplyMergePatch(ledger, []byte(patch))
+		if ve := mustValidationErrors(t, err); !ve.Has(want) {
+			t.Errorf("%s: got %v, want a read-only error at %s", patch, ve, want)
+		}
+	}
+
+	// Changes that leave the readOnly values alone are fine.
+	got, err := schema.ApplyMergePatch(ledger, []byte(`{"owner":{"note":"n"}}`))
+	assertNoError(t, err)
+	if got.Owner.Note != "n" {
+		t.Errorf("expected the note to be patched, got %+v", got.Owner)
+	}
+}
+
+func TestApplyPatch_ReadOnlyAncestorsDropped(t *testing.T) {
+	drop := schema.WithReadOnly(schema.DropReadOnly)
+	got, err := schema.ApplyJSONPatch(ledger, []byte(`[{"op":"add","path":"","value":{"count":3,"owner":{"note":"n"}}}]`), drop)
+	assertNoError(t, err)
+	if got.ID != "x" || got.Count != 3 || got.Owner == nil || got.Owner.By != "ann" || got.Owner.Note != "n" {
+		t.Errorf("expected the readOnly values to be restored, got %+v %+v", got, got.Owner)
+	}
+
+	// A removed object takes its readOnly values with it.
+	got, err = schema.ApplyMergePatch(ledger, []byte(`{"owner":null,"entries":[]}`), drop)
+	assertNoError(t, err)
+	if got.ID != "x" || got.Owner != nil || len(got.Entries) != 0 {
+		t.Errorf("expected the owner and entries to be removed, got %+v", got)
+	}
+}
+
+func TestApplyPatch_UnsetWrappers(t *testing.T) {
+	// Without omitzero, unset wrappers marshal as null.
+	type Alias struct {
+		Nick schema.Optional[string] `json:"nick"`
+		Due  schema.Nullable[int]    `json:"due"`
+	}
+	type Rec struct {
+		Name  string                  `json:"name"`
+		Nick  schema.Optional[string] `json:"nick"`
+		Email schema.Nullable[string] `json:"email"`
+		Prev  []Alias                 `json:"prev"`
+	}
+	rec := Rec{Name: "bob", Prev: []Alias{{Nick: schema.NewOptional("r")}}}
+
+	got, err := schema.ApplyMergePatch(rec, []byte(`{"name":"alice"}`))
+	assertNoError(t, err)
+	want := Rec{Name: "alice", Prev: rec.Prev}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	got, err = schema.ApplyJSONPatch(rec, []byte(`[{"op":"add","path":"/email","value":null}]`))
+	assertNoError(t, err)
+	want = Rec{Name: "bob", Email: schema.Null[string](), Prev: rec.Prev}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestApplyPatch_AdditionalProperties(t *testing.T) {
+	e := Event{Type: "click", Extra: map[string]any{"x": 1.0}, Meta: Tagged{Labels: map[string]string{"env": "prod"}}}
+
+	// Collected members are part of the document, at any depth.
+	got, err := schema.ApplyMergePatch(e, []byte(`{"type":"tap"}`))
+	assertNoError(t, err)
+	want := Event{Type: "tap", Extra: map[string]any{"x": 1.0}, Meta: Tagged{Labels: map[string]string{"env": "prod"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	got, err = schema.ApplyMergePatch(e, []byte(`{"x":null,"y":2,"meta":{"env":"dev"}}`))
+	assertNoError(t, err)
+	if len(got.Extra) != 1 || got.Extra["y"] != 2.0 || got.Meta.Labels["env"] != "dev" {
+		t.Errorf("expected the patched extras, got %+v", got)
+	}
+
+	got, err = schema.ApplyJSONPatch(e, []byte(`[{"op":"replace","path":"/type","value":"tap"},{"op":"add","path":"/y","value":"b"}]`))
+	assertNoError(t, err)
+	if len(got.Extra) != 2 || got.Extra["x"] != 1.0 || got.Extra["y"] != "b" || got.Meta.Labels["env"] != "prod" {
+		t.Errorf("expected the extras to be kept, got %+v", got)
+	}
+}
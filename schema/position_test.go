@@ -0,0 +1,135 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Listener struct {
+	Host string `json:"host" schema:"required"`
+	Port int    `json:"port" schema:"minimum=1,maximum=65535"`
+}
+
+type Deployment struct {
+	Name      string              `json:"name"      schema:"minLength=3"`
+	Listeners []Listener          `json:"listeners"`
+	Labels    map[string]Listener `json:"labels"`
+}
+
+const deployment = `{
+  "name": "ab",
+  "listeners": [
+    {"host": "a", "port": 80},
+    {"port": 70000}
+  ],
+  "labels": {"a.b": {"HOST": "x", "port": 0}}
+}`
+
+// position returns the line and column of the first occurrence of s in
+// deployment.
+func position(s string) (int, int) {
+	before := deployment[:strings.Index(deployment, s)]
+	return strings.Count(before, "\n") + 1, len(before) - strings.LastIndex(before, "\n")
+}
+
+func TestPositions(t *testing.T) {
+	for name, parse := range map[string]func([]byte) error{
+		"ParseJSON":  func(b []byte) error { _, err := schema.ParseJSON[Deployment](b); return err },
+		"DecodeJSON": func(b []byte) error { _, err := schema.DecodeJSON[Deployment](b); return err },
+		"Compiled":   func(b []byte) error { _, err := schema.MustCompile[Deployment]().ParseJSON(b); return err },
+	} {
+		ve := mustValidationErrors(t, parse([]byte(deployment)))
+		want := map[string]string{
+			"name":              `"ab"`,
+			"listeners[1].port": "70000",
+			// A missing field points at its object.
+			"listeners[1].host": `{"port": 70000}`,
+			"labels.a.b.port":   "0}}",
+		}
+		if len(ve) != len(want) {
+			t.Errorf("%s: expected %d errors, got %v", name, len(want), ve)
+		}
+		for _, e := range ve {
+			line, col := position(want[e.Field])
+			if e.Line != line || e.Column != col || deployment[e.Offset:e.Offset+len(want[e.Field])] != want[e.Field] {
+				t.Errorf("%s: %s at %d:%d (offset %d), want %d:%d", name, e.Field, e.Line, e.Column, e.Offset, line, col)
+			}
+		}
+	}
+}
+
+func TestPositions_TypeMismatch(t *testing.T) {
+	data := []byte("{\n  \"name\": 12,\n  \"listeners\": [{\"host\": \"h\", \"port\": \"80\"}]\n}")
+	_, err := schema.ParseJSON[Deployment](data)
+	ve := mustValidationErrors(t, err)
+	if len(ve) != 2 {
+		t.Fatalf("expected 2 errors, got %v", ve)
+	}
+	if ve[0].Field != "name" || ve[0].Line != 2 || ve[0].Column != 11 {
+		t.Errorf("unexpected position for name: %+v", ve[0])
+	}
+	if ve[1].Field != "listeners[0].port" || ve[1].Line != 3 || ve[1].Column != 39 {
+		t.Errorf("unexpected position for the port: %+v", ve[1])
+	}
+}
+
+func TestPositions_Syntax(t *testing.T) {
+	for in, want := range map[string][3]int{
+		"{\n  \"name\": x}": {2, 11, 12},
+		"{\n  \"name\":":    {2, 10, 11},
+	} {
+		for _, parse := range []func([]byte) error{
+			func(b []byte) error { _, err := schema.ParseJSON[Deployment](b); return err },
+			func(b []byte) error { _, err := schema.DecodeJSON[Deployment](b); return err },
+		} {
+			ve := mustValidationErrors(t, parse([]byte(in)))
+			if len(ve) != 1 || [3]int{ve[0].Line, ve[0].Column, ve[0].Offset} != want {
+				t.Errorf("%q: got %+v, want line, column and offset %v", in, ve, want)
+			}
+		}
+	}
+}
+
+func TestPositions_Validate(t *testing.T) {
+	ve := mustValidationErrors(t, schema.Validate(Deployment{Name: "ab"}))
+	if ve[0].Line != 0 || ve[0].Error() != `field "name": must be at least 3 characters long (got 2) (got ab)` {
+		t.Errorf("Go values have no position: %+v", ve[0])
+	}
+}
+
+func TestPositions_MarshalJSON(t *testing.T) {
+	_, err := schema.ParseJSON[Deployment]([]byte(`{"name":"ab"}`))
+	b, merr := json.Marshal(mustValidationErrors(t, err))
+	assertNoError(t, merr)
+	want := `[{"field":"name","message":"must be at least 3 characters long (got 2)","value":"ab","keyword":"minLength","line":1,"column":9,"offset":8}]`
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
+
+func TestParseJSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deploy.json")
+	if err := os.WriteFile(path, []byte(deployment), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := schema.ParseJSONFile[Deployment](path)
+	ve := mustValidationErrors(t, err)
+	if want := path + `:2:11: field "name": must be at least 3 characters long (got 2) (got ab)`; ve[0].Error() != want {
+		t.Errorf("got %q, want %q", ve[0].Error(), want)
+	}
+
+	_, err = schema.MustCompile[Deployment]().ParseJSONFile(path)
+	if ve := mustValidationErrors(t, err); ve[0].File != path {
+		t.Errorf("expected the file to be set, got %+v", ve[0])
+	}
+
+	_, err = schema.ParseJSONFile[Deployment](filepath.Join(t.TempDir(), "missing.json"))
+	if !os.IsNotExist(err) {
+		t.Errorf("expected a not-exist error, got %v", err)
+	}
+}
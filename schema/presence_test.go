@@ -0,0 +1,114 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Counter struct {
+	_ struct{} `schema:"dependentRequired:limit=unit"`
+
+	Count  int               `json:"count"  schema:"required,minimum=0"`
+	Active bool              `json:"active" schema:"required"`
+	Name   string            `json:"name"   schema:"required"`
+	Label  string            `json:"label"  schema:"minLength=2"`
+	Limit  int               `json:"limit"  schema:"minimum=1,default=10"`
+	Unit   string            `json:"unit"   schema:"default=items"`
+	Tags   []string          `json:"tags"   schema:"required"`
+	Extra  map[string]string `json:"extra"  schema:"required"`
+	Owner  *Place            `json:"owner"`
+	Home   Place             `json:"home"`
+}
+
+func TestPresence_RequiredMeansPresent(t *testing.T) {
+	in := []byte(`{"count":0,"active":false,"name":"","tags":[],"extra":{}}`)
+	c, err := schema.ParseJSON[Counter](in, schema.Presence())
+	assertNoError(t, err)
+	if c.Limit != 10 || c.Unit != "items" {
+		t.Errorf("expected defaults for the missing keys, got %+v", c)
+	}
+
+	// Without Presence, the zero values count as missing.
+	ve := mustValidationErrors(t, schema.ValidateJSON[Counter](in))
+	assertHasField(t, ve, "name")
+	assertHasField(t, ve, "tags")
+	assertHasField(t, ve, "extra")
+}
+
+func TestPresence_MissingKeys(t *testing.T) {
+	_, err := schema.ParseJSON[Counter]([]byte(`{"name":"x","tags":["a"]}`), schema.Presence())
+	ve := mustValidationErrors(t, err)
+	want := map[string]bool{"count": true, "active": true, "extra": true}
+	for _, e := range ve {
+		if !want[e.Field] || e.Message != "field is required" {
+			t.Errorf("unexpected error: %v", e)
+		}
+		delete(want, e.Field)
+	}
+	for f := range want {
+		t.Errorf("missing required error for %q", f)
+	}
+}
+
+func TestPresence_ExplicitZeros(t *testing.T) {
+	in := []byte(`{"count":0,"active":true,"name":"a","tags":[],"extra":{},"label":"","limit":0,"unit":""}`)
+	_, err := schema.ParseJSON[Counter](in, schema.Presence())
+	ve := mustValidationErrors(t, err)
+	// The explicit zeros keep their value and are checked like any other.
+	assertHasField(t, ve, "label")
+	assertHasField(t, ve, "limit")
+	if len(ve) != 2 {
+		t.Errorf("expected 2 errors, got %v", ve)
+	}
+
+	c, err := schema.ParseJSON[Counter]([]byte(`{"count":0,"active":true,"name":"a","tags":[],"extra":{},"unit":""}`), schema.Presence())
+	assertNoError(t, err)
+	if c.Unit != "" {
+		t.Errorf("the default replaced an explicit empty string: %q", c.Unit)
+	}
+}
+
+func TestPresence_DependentRequired(t *testing.T) {
+	base := `"count":1,"active":true,"name":"a","tags":[],"extra":{}`
+	_, err := schema.ParseJSON[Counter]([]byte(`{`+base+`,"limit":5}`), schema.Presence())
+	ve := mustValidationErrors(t, err)
+	if len(ve) != 1 || ve[0].Message != `field "unit" is required because "limit" is present` {
+		t.Errorf("unexpected errors: %v", ve)
+	}
+
+	// An explicit empty unit is present.
+	_, err = schema.ParseJSON[Counter]([]byte(`{`+base+`,"limit":5,"unit":""}`), schema.Presence())
+	assertNoError(t, err)
+}
+
+func TestPresence_Nested(t *testing.T) {
+	base := `"count":1,"active":true,"name":"a","tags":[],"extra":{}`
+	c, err := schema.ParseJSON[Counter]([]byte(`{`+base+`}`), schema.Presence())
+	assertNoError(t, err)
+	if c.Home.Zip != "00000" {
+		t.Errorf("expected the defaults below a missing key, got %+v", c.Home)
+	}
+
+	_, err = schema.ParseJSON[Counter]([]byte(`{`+base+`,"home":{},"owner":{"zip":""}}`), schema.Presence())
+	ve := mustValidationErrors(t, err)
+	assertHasField(t, ve, "home.city")
+	assertHasField(t, ve, "owner.city")
+	assertHasField(t, ve, "owner.zip")
+
+	_, err = schema.ParseJSON[Counter]([]byte(`{`+base+`,"owner":null}`), schema.Presence())
+	assertNoError(t, err)
+}
+
+func TestPresence_EntryPoints(t *testing.T) {
+	in := []byte(`{"count":0,"active":false,"name":"","tags":[],"extra":{}}`)
+	assertNoError(t, schema.ValidateJSON[Counter](in, schema.Presence()))
+	_, err := schema.DecodeJSON[Counter](in, schema.Presence())
+	assertNoError(t, err)
+	_, err = schema.MustCompile[Counter](schema.Presence()).ParseJSON(in)
+	assertNoError(t, err)
+
+	// Validate only sees Go values and ignores Presence.
+	ve := mustValidationErrors(t, schema.Validate(Counter{}, schema.Presence()))
+	assertHasField(t, ve, "name")
+}
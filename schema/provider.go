@@ -0,0 +1,81 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var (
+	schemaProviderType = reflect.TypeOf((*SchemaProvider)(nil)).Elem()
+	schemaTaggerType   = reflect.TypeOf((*SchemaTagger)(nil)).Elem()
+)
+
+// SchemaProvider is implemented by types that describe their own schema, so
+// that constraints shared by every use of a domain type (a currency code, a
+// percentage, ...) are declared once on the type instead of in each struct
+// tag. The returned schema is the base for every field of that type; field
+// tags can still tighten it.
+//
+// GoSchema is called on the zero value. A zero Type is filled in from the Go
+// type.
+type SchemaProvider interface {
+	GoSchema() FieldSchema
+}
+
+// SchemaTagger is a lighter alternative to [SchemaProvider]: the type returns
+// a `schema` tag string that is applied to every field of that type, before
+// the field's own tag.
+//
+//	type Currency string
+//
+//	func (Currency) SchemaTag() string { return "pattern=^[A-Z]{3}$" }
+type SchemaTagger interface {
+	SchemaTag() string
+}
+
+// providedSchema returns the schema a type declares for itself through
+// SchemaProvider or SchemaTagger, using base (the schema derived from its Go
+// type) as a starting point. Types implementing neither return base as is.
+func providedSchema(t reflect.Type, base FieldSchema) (FieldSchema, error) {
+	switch {
+	case implements(t, schemaProviderType):
+		fs := interfaceValue(reflect.New(t).Elem(), schemaProviderType).(SchemaProvider).GoSchema()
+		if fs.Type == "" {
+			fs.Type = base.Type
+		}
+		if fs.Type != base.Type {
+			return fs, nil
+		}
+		// Keep the structure found by reflect unless the type overrides it.
+		if fs.String == nil {
+			fs.String = base.String
+		}
+		if fs.Number == nil {
+			fs.Number = base.Number
+		}
+		if fs.Bool == nil {
+			fs.Bool = base.Bool
+		}
+		if fs.Array == nil {
+			fs.Array = base.Array
+		}
+		if fs.Map == nil {
+			fs.Map = base.Map
+		}
+		if fs.Nested == nil {
+			fs.Nested = base.Nested
+		}
+		if fs.Time == nil {
+			fs.Time = base.Time
+		}
+		return fs, nil
+	case implements(t, schemaTaggerType):
+		tag := interfaceValue(reflect.New(t).Elem(), schemaTaggerType).(SchemaTagger).SchemaTag()
+		fs, err := applyTag(base, t, tag)
+		if err != nil {
+			return fs, fmt.Errorf("goschema: type %s: %w", t, err)
+		}
+		return fs, nil
+	}
+	return base, nil
+}
@@ -0,0 +1,136 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Currency string
+
+func (Currency) SchemaTag() string { return "pattern=^[A-Z]{3}$,minLength=3,maxLength=3" }
+
+type CountryCode string
+
+func (CountryCode) SchemaTag() string { return "enum=IT|FR|DE|ES" }
+
+type Percent float64
+
+func (Percent) GoSchema() schema.FieldSchema {
+	lo, hi := 0.0, 100.0
+	return schema.FieldSchema{Number: &schema.NumberConstraints{Minimum: &lo, Maximum: &hi}}
+}
+
+type Invoice struct {
+	Currency Currency      `json:"currency" schema:"required"`
+	Country  CountryCode   `json:"country"  schema:"enum=IT|FR|US"`
+	Discount Percent       `json:"discount" schema:"maximum=50"`
+	Markup   Percent       `json:"markup"   schema:"maximum=500"`
+	Accepted []Currency    `json:"accepted"`
+	Regions  []CountryCode `json:"regions"`
+}
+
+func TestProvider_TagAppliedEverywhere(t *testing.T) {
+	valid := Invoice{Currency: "EUR", Country: "IT", Discount: 10, Accepted: []Currency{"USD"}, Regions: []CountryCode{"DE"}}
+	assertNoError(t, schema.Validate(valid))
+
+	ve := mustValidationErrors(t, schema.Validate(Invoice{Currency: "eur", Accepted: []Currency{"US"}, Regions: []CountryCode{"XX"}}))
+	assertHasField(t, ve, "currency")
+	assertHasField(t, ve, "accepted[0]")
+	assertHasField(t, ve, "regions[0]")
+}
+
+func TestProvider_FieldTagTightens(t *testing.T) {
+	// The field enum is intersected with the type's: US is not a CountryCode.
+	ve := mustValidationErrors(t, schema.Validate(Invoice{Currency: "EUR", Country: "US"}))
+	assertHasField(t, ve, "country")
+
+	// maximum=50 tightens the type's maximum of 100.
+	ve = mustValidationErrors(t, schema.Validate(Invoice{Currency: "EUR", Discount: 60}))
+	assertHasField(t, ve, "discount")
+
+	// maximum=500 cannot loosen the type's maximum of 100.
+	ve = mustValidationErrors(t, schema.Validate(Invoice{Currency: "EUR", Markup: 200}))
+	assertHasField(t, ve, "markup")
+
+	// The type's minimum still applies.
+	ve = mustValidationErrors(t, schema.Validate(Invoice{Currency: "EUR", Discount: -1}))
+	assertHasField(t, ve, "discount")
+}
+
+type Payment struct {
+	Loose Currency `json:"loose" schema:"pattern=^.*$"`
+	Euro  Currency `json:"euro"  schema:"pattern=^E"`
+}
+
+func TestProvider_FieldPatternAdds(t *testing.T) {
+	assertNoError(t, schema.Validate(Payment{Loose: "USD", Euro: "EUR"}))
+
+	// A field pattern can't loosen the type's, nor replace it.
+	ve := mustValidationErrors(t, schema.Validate(Payment{Loose: "lowercase", Euro: "USD"}))
+	assertHasField(t, ve, "loose")
+	assertHasField(t, ve, "euro")
+
+	js, err := schema.ToJSONSchema[Payment]()
+	assertNoError(t, err)
+	euro := js["properties"].(map[string]any)["euro"].(map[string]any)
+	if euro["pattern"] != "^[A-Z]{3}$" || euro["allOf"] == nil {
+		t.Errorf("expected both patterns, got %v", euro)
+	}
+}
+
+type Code string
+
+func (Code) SchemaTag() string { return "const=one,format=email,required" }
+
+type (
+	OtherConst struct {
+		C Code `schema:"const=two"`
+	}
+	OtherFormat struct {
+		C Code `schema:"format=uuid"`
+	}
+	OptionalCode struct {
+		C Code `schema:"required=false"`
+	}
+	RestatedCode struct {
+		C Code `schema:"const=one,format=email,required"`
+	}
+)
+
+func TestProvider_Contradictions(t *testing.T) {
+	for name, tc := range map[string]struct {
+		fn   func() (map[string]any, error)
+		want string
+	}{
+		"const":    {schema.ToJSONSchema[OtherConst], `const "two" conflicts with the const "one" of the type`},
+		"format":   {schema.ToJSONSchema[OtherFormat], "format uuid conflicts with the format email of the type"},
+		"required": {schema.ToJSONSchema[OptionalCode], "required=false: type schema_test.Code is required by its own schema"},
+	} {
+		if _, err := tc.fn(); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected %q, got %v", name, tc.want, err)
+		}
+	}
+	_, err := schema.ToJSONSchema[RestatedCode]()
+	assertNoError(t, err)
+}
+
+func TestProvider_ToJSONSchema(t *testing.T) {
+	js, err := schema.ToJSONSchema[Invoice]()
+	assertNoError(t, err)
+	props := js["properties"].(map[string]any)
+
+	currency := props["currency"].(map[string]any)
+	if currency["pattern"] != "^[A-Z]{3}$" || currency["type"] != "string" {
+		t.Errorf("expected type-level pattern, got %v", currency)
+	}
+	country := props["country"].(map[string]any)
+	if enum, _ := country["enum"].([]string); len(enum) != 2 {
+		t.Errorf("expected intersected enum [IT FR], got %v", country["enum"])
+	}
+	discount := props["discount"].(map[string]any)
+	if discount["type"] != "number" || discount["minimum"] != 0.0 || discount["maximum"] != 50.0 {
+		t.Errorf("unexpected discount schema: %v", discount)
+	}
+}
@@ -0,0 +1,232 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Pruned is a member that [PruneJSON] removed from its input.
+type Pruned struct {
+	Field  string           // path of the member, like the Field of a ValidationError
+	Reason string           // `unknown field "x"`, or the first problem found in the value
+	Value  json.RawMessage  // the removed value
+	Errors ValidationErrors // with PruneInvalid, every problem found in the value
+}
+
+// PruneReport lists the members removed by [PruneJSON], in the order they
+// were removed.
+type PruneReport []Pruned
+
+// PruneInvalid makes [PruneJSON] also remove the members of optional fields
+// whose value fails validation.
+func PruneInvalid() Option {
+	return func(o *options) {
+		o.pruneInvalid = true
+	}
+}
+
+// PruneJSON strips the JSON input down to what the schema of T declares,
+// e.g. before forwarding a payload to a system that rejects unknown
+// properties. Object members that match no field of their struct are
+// removed at any depth, and the cleaned JSON is returned with a report of
+// what was removed. Keys are matched like [ParseJSON] matches them, and
+// objects with a field collecting additional properties keep theirs.
+// Values that ParseJSON hands to encoding/json are kept as they are.
+//
+// With [PruneInvalid], the members of fields that are not `required` are
+// removed as well when their value fails validation, the value of a field
+// that contains an invalid value included: the input is decoded like
+// [DecodeJSON] does, and each problem removes the innermost optional field
+// around it, until no problem is left that can be removed this way. The
+// result is not validated otherwise; problems with required fields remain.
+//
+// Member order is preserved. The input is returned as is if nothing was
+// removed, and re-encoded without insignificant whitespace otherwise.
+//
+//	clean, report, err := schema.PruneJSON[Order](body, schema.PruneInvalid())
+func PruneJSON[T any](data []byte, opts ...Option) ([]byte, PruneReport, error) {
+	r, err := resolve(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, nil, err
+	}
+	return pruneJSON[T](r.plan, data, newOptions(opts))
+}
+
+// pruneJSON prunes data with a compiled plan.
+func pruneJSON[T any](plan *decodePlan, data []byte, o *options) ([]byte, PruneReport, error) {
+	tree, err := parseJSONTree(data)
+	if err != nil {
+		return nil, nil, syntaxErrors(err)
+	}
+
+	var report PruneReport
+	pruneUnknown(tree, plan, "", &report)
+	for o.pruneInvalid {
+		_, err := decodeJSON[T](plan, tree.bytes(), o)
+		ve, ok := err.(ValidationErrors)
+		if !ok {
+			if err != nil {
+				return nil, nil, err
+			}
+			break
+		}
+		if !pruneInvalid(tree, plan, ve, &report) {
+			break
+		}
+	}
+
+	if len(report) == 0 {
+		return data, nil, nil
+	}
+	return tree.bytes(), report, nil
+}
+
+// pruneUnknown removes the members of the objects in n that the decoder
+// would ignore when decoding n with p.
+func pruneUnknown(n *jsonNode, p *decodePlan, path string, report *PruneReport) {
+	for p.kind == planPtr {
+		p = p.elem
+	}
+	switch {
+	case n.kind == jsonObject && p.kind == planStruct:
+		kept := n.members[:0]
+		for _, m := range n.members {
+			i, ok := p.field(m.key)
+			if !ok && p.extra == nil {
+				*report = append(*report, Pruned{
+					Field:  fieldPath(path, m.key),
+					Reason: fmt.Sprintf("unknown field %q", m.key),
+					Value:  m.value.bytes(),
+				})
+				continue
+			}
+			if ok {
+				pruneUnknown(m.value, p.fields[i].plan, fieldPath(path, p.fields[i].name), report)
+			}
+			kept = append(kept, m)
+		}
+		n.members = kept
+	case n.kind == jsonObject && p.kind == planMap:
+		for _, m := range n.members {
+			pruneUnknown(m.value, p.elem, fieldPath(path, m.key), report)
+		}
+	case n.kind == jsonArray && (p.kind == planSlice || p.kind == planArray):
+		for i, item := range n.items {
+			pruneUnknown(item, p.elem, fmt.Sprintf("%s[%d]", path, i), report)
+		}
+	}
+}
+
+// prunable is an object member that PruneInvalid may remove: the value of
+// an optional field, or an additional property.
+type prunable struct {
+	object *jsonNode
+	match  func(key string) bool // whether a key of object is the member's
+}
+
+// pruneInvalid removes, for each of errs, the innermost optional field
+// around it, and reports whether anything was removed.
+func pruneInvalid(tree *jsonNode, p *decodePlan, errs ValidationErrors, report *PruneReport) bool {
+	members := make(map[string]prunable)
+	indexPrunable(tree, p, "", members)
+
+	var order []string
+	found := make(map[string]ValidationErrors)
+	for _, e := range errs {
+		for _, prefix := range pathPrefixes(e.Field) {
+			if _, ok := members[prefix]; ok {
+				if found[prefix] == nil {
+					order = append(order, prefix)
+				}
+				found[prefix] = append(found[prefix], e)
+				break
+			}
+		}
+	}
+
+	// A member inside another one that is removed goes with it.
+	var removed []string
+	for _, path := range order {
+		outer := path
+		for _, prefix := range pathPrefixes(path)[1:] {
+			if _, ok := found[prefix]; ok {
+				outer = prefix
+			}
+		}
+		if outer != path {
+			found[outer] = append(found[outer], found[path]...)
+			continue
+		}
+		removed = append(removed, path)
+	}
+
+	for _, path := range removed {
+		m := members[path]
+		var value json.RawMessage
+		kept := m.object.members[:0]
+		for _, member := range m.object.members {
+			if m.match(member.key) {
+				value = member.value.bytes()
+				continue
+			}
+			kept = append(kept, member)
+		}
+		m.object.members = kept
+		errs := found[path]
+		reason := errs[0].Message
+		if errs[0].Field != path {
+			reason = fmt.Sprintf("field %q: %s", errs[0].Field, reason)
+		}
+		*report = append(*report, Pruned{Field: path, Reason: reason, Value: value, Errors: errs})
+	}
+	return len(removed) > 0
+}
+
+// indexPrunable adds the members of n that PruneInvalid may remove to
+// members, by path.
+func indexPrunable(n *jsonNode, p *decodePlan, path string, members map[string]prunable) {
+	for p.kind == planPtr {
+		p = p.elem
+	}
+	switch {
+	case n.kind == jsonObject && p.kind == planStruct:
+		for _, m := range n.members {
+			i, ok := p.field(m.key)
+			if !ok {
+				key := m.key
+				members[fieldPath(path, key)] = prunable{object: n, match: func(k string) bool { return k == key }}
+				continue
+			}
+			f := p.fields[i]
+			fp := fieldPath(path, f.name)
+			if !f.plan.fs.Required {
+				members[fp] = prunable{object: n, match: func(k string) bool {
+					j, ok := p.field(k)
+					return ok && j == i
+				}}
+			}
+			indexPrunable(m.value, f.plan, fp, members)
+		}
+	case n.kind == jsonObject && p.kind == planMap:
+		for _, m := range n.members {
+			indexPrunable(m.value, p.elem, fieldPath(path, m.key), members)
+		}
+	case n.kind == jsonArray && (p.kind == planSlice || p.kind == planArray):
+		for i, item := range n.items {
+			indexPrunable(item, p.elem, fmt.Sprintf("%s[%d]", path, i), members)
+		}
+	}
+}
+
+// pathPrefixes returns path followed by the paths of its ancestors,
+// innermost first: "a.b[0]", "a.b", "a".
+func pathPrefixes(path string) []string {
+	prefixes := []string{path}
+	for i := len(path) - 1; i > 0; i-- {
+		if path[i] == '.' || path[i] == '[' {
+			prefixes = append(prefixes, path[:i])
+		}
+	}
+	return prefixes
+}
@@ -0,0 +1,104 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Delivery struct {
+	Ref     string            `json:"ref"     schema:"required,minLength=3"`
+	Note    string            `json:"note"    schema:"maxLength=5"`
+	Parcels []Parcel          `json:"parcels"`
+	To      *Place            `json:"to"`
+	Tags    map[string]Parcel `json:"tags"`
+	Meta    Event             `json:"meta"`
+	Stamp   Stamped           `json:"stamp"`
+}
+
+func TestPruneJSON_Unknown(t *testing.T) {
+	in := `{"ref":"abc","x":1,"parcels":[{"sku":"abc","y":[1, 2]}],"to":{"CITY":"Rome","z":null},` +
+		`"tags":{"a":{"sku":"abc","w":true}},"meta":{"type":"t","kept":1},"stamp":{"city":"Oslo","at":"now"}}`
+	out, report, err := schema.PruneJSON[Delivery]([]byte(in))
+	assertNoError(t, err)
+	want := `{"ref":"abc","parcels":[{"sku":"abc"}],"to":{"CITY":"Rome"},"tags":{"a":{"sku":"abc"}},"meta":{"type":"t","kept":1},"stamp":{"city":"Oslo","at":"now"}}`
+	if string(out) != want {
+		t.Errorf("got  %s\nwant %s", out, want)
+	}
+
+	var got []string
+	for _, p := range report {
+		got = append(got, p.Field+"="+string(p.Value))
+	}
+	wantReport := []string{"x=1", "parcels[0].y=[1,2]", "to.z=null", "tags.a.w=true"}
+	if len(got) != len(wantReport) {
+		t.Fatalf("got report %v, want %v", got, wantReport)
+	}
+	for i := range got {
+		if got[i] != wantReport[i] {
+			t.Errorf("got report %v, want %v", got, wantReport)
+		}
+	}
+	if report[0].Reason != `unknown field "x"` || report[0].Errors != nil {
+		t.Errorf("unexpected entry: %+v", report[0])
+	}
+}
+
+func TestPruneJSON_Unchanged(t *testing.T) {
+	in := []byte(`{ "ref": "abc", "note": "far too long" }`)
+	out, report, err := schema.PruneJSON[Delivery](in)
+	assertNoError(t, err)
+	if string(out) != string(in) || report != nil {
+		t.Errorf("expected the input back untouched, got %s %v", out, report)
+	}
+
+	_, _, err = schema.PruneJSON[Delivery]([]byte(`{"ref":`))
+	mustValidationErrors(t, err)
+}
+
+func TestPruneJSON_Invalid(t *testing.T) {
+	in := `{"ref":"ab","note":"far too long","x":1,"parcels":[{"sku":"a"}],"to":{"zip":"1"},"tags":{"a":{"qty":0}},"meta":{"type":"t"}}`
+	out, report, err := schema.PruneJSON[Delivery]([]byte(in), schema.PruneInvalid())
+	assertNoError(t, err)
+	// The required ref stays, invalid or not.
+	if want := `{"ref":"ab","meta":{"type":"t"}}`; string(out) != want {
+		t.Errorf("got  %s\nwant %s", out, want)
+	}
+
+	byField := map[string]schema.Pruned{}
+	for _, p := range report {
+		byField[p.Field] = p
+	}
+	if len(byField) != 5 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if p := byField["note"]; p.Reason != "must be at most 5 characters long (got 12)" || len(p.Errors) != 1 {
+		t.Errorf("unexpected entry for note: %+v", p)
+	}
+	// Problems deep inside a field remove the whole field.
+	if p := byField["to"]; string(p.Value) != `{"zip":"1"}` || len(p.Errors) != 2 || p.Reason != `field "to.city": field is required` {
+		t.Errorf("unexpected entry for to: %+v", p)
+	}
+	for _, f := range []string{"x", "parcels", "tags"} {
+		if _, ok := byField[f]; !ok {
+			t.Errorf("expected %s to be removed", f)
+		}
+	}
+}
+
+func TestPruneJSON_InvalidExtra(t *testing.T) {
+	out, report, err := schema.PruneJSON[Tagged]([]byte(`{"a":"x","b":1}`), schema.PruneInvalid())
+	assertNoError(t, err)
+	if string(out) != `{"a":"x"}` || len(report) != 1 || report[0].Field != "b" {
+		t.Errorf("expected the additional property that doesn't fit to be removed, got %s %+v", out, report)
+	}
+}
+
+func TestPruneJSON_Compiled(t *testing.T) {
+	s := schema.MustCompile[Delivery](schema.PruneInvalid())
+	out, _, err := s.PruneJSON([]byte(`{"ref":"abc","note":"far too long"}`))
+	assertNoError(t, err)
+	if string(out) != `{"ref":"abc"}` {
+		t.Errorf("got %s", out)
+	}
+}
@@ -0,0 +1,172 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ReadOnlyPolicy controls how [ParseJSON] treats values that the input
+// supplies for `readOnly` fields.
+type ReadOnlyPolicy int
+
+const (
+	// RejectReadOnly reports every supplied readOnly field as a validation
+	// error. This is the default.
+	RejectReadOnly ReadOnlyPolicy = iota
+	// DropReadOnly silently discards supplied readOnly values before
+	// decoding, so the fields keep their zero value (or default).
+	DropReadOnly
+	// AllowReadOnly decodes readOnly fields like any other field, e.g. when
+	// parsing data that the server itself produced.
+	AllowReadOnly
+)
+
+// WithReadOnly sets how [ParseJSON] and [ValidateJSON] treat input values for
+// fields tagged `readOnly`.
+func WithReadOnly(p ReadOnlyPolicy) Option {
+	return func(o *options) {
+		o.readOnly = p
+	}
+}
+
+// MarshalJSON encodes v like json.Marshal but omits the fields tagged
+// `writeOnly` (passwords, secrets, ...) at any depth, so that values parsed
+// from client input can be echoed back safely.
+//
+//	data, err := schema.MarshalJSON(user)
+func MarshalJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || v == nil {
+		return data, err
+	}
+
+	r, err := resolve(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+	if !r.writeOnly {
+		return data, nil
+	}
+
+	tree, err := parseJSONTree(data)
+	if err != nil {
+		return nil, err
+	}
+	matchFields(tree, r.fs, "", isWriteOnly, true)
+	return tree.bytes(), nil
+}
+
+func isReadOnly(fs FieldSchema) bool  { return fs.ReadOnly }
+func isWriteOnly(fs FieldSchema) bool { return fs.WriteOnly }
+
+// checkReadOnly applies the readOnly policy to the JSON input of ParseJSON.
+// It returns the data to decode, which has the readOnly members removed
+// under DropReadOnly, or the validation errors under RejectReadOnly.
+func checkReadOnly(data []byte, fs FieldSchema, o *options) ([]byte, error) {
+	if o.readOnly == AllowReadOnly || !schemaHas(fs, isReadOnly) {
+		return data, nil
+	}
+	tree, err := parseJSONTree(data)
+	if err != nil {
+		// Leave the syntax error to the decoder.
+		return data, nil
+	}
+
+	drop := o.readOnly == DropReadOnly
+	paths := matchFields(tree, fs, "", isReadOnly, drop)
+	if drop {
+		if len(paths) == 0 {
+			return data, nil
+		}
+		return tree.bytes(), nil
+	}
+
+	var errs ValidationErrors
+	for _, p := range paths {
+		errs = append(errs, ValidationError{
+			Field:   p,
+			Message: "field is read-only",
+			Keyword: "readOnly",
+		})
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return data, nil
+}
+
+// matchFields walks the JSON tree n alongside the schema fs and returns the
+// paths of the struct fields, present in the input, whose schema satisfies
+// match. With remove, the matching members are deleted from the tree.
+func matchFields(n *jsonNode, fs FieldSchema, path string, match func(FieldSchema) bool, remove bool) []string {
+	var paths []string
+	switch n.kind {
+	case jsonObject:
+		if fs.Nested != nil {
+			kept := n.members[:0]
+			for _, m := range n.members {
+				name, field, ok := lookupField(fs.Nested, m.key)
+				if !ok {
+					kept = append(kept, m)
+					continue
+				}
+				subPath := fieldPath(path, name)
+				if match(field) {
+					paths = append(paths, subPath)
+					if remove {
+						continue
+					}
+				}
+				kept = append(kept, m)
+				paths = append(paths, matchFields(m.value, field, subPath, match, remove)...)
+			}
+			n.members = kept
+		} else if fs.Map != nil && fs.Map.Values != nil {
+			for _, m := range n.members {
+				paths = append(paths, matchFields(m.value, *fs.Map.Values, fieldPath(path, m.key), match, remove)...)
+			}
+		}
+	case jsonArray:
+		if fs.Array != nil && fs.Array.Items != nil {
+			for i, item := range n.items {
+				itemPath := fmt.Sprintf("%s[%d]", path, i)
+				paths = append(paths, matchFields(item, *fs.Array.Items, itemPath, match, remove)...)
+			}
+		}
+	}
+	return paths
+}
+
+// lookupField finds the field that encoding/json would decode the JSON key
+// into: an exact name match, or else a case-insensitive one.
+func lookupField(obj *ObjectSchema, key string) (string, FieldSchema, bool) {
+	if fs, ok := obj.Fields[key]; ok {
+		return key, fs, true
+	}
+	for name, fs := range obj.Fields {
+		if strings.EqualFold(name, key) {
+			return name, fs, true
+		}
+	}
+	return "", FieldSchema{}, false
+}
+
+// schemaHas reports whether match holds for a field anywhere in fs.
+func schemaHas(fs FieldSchema, match func(FieldSchema) bool) bool {
+	if fs.Nested != nil {
+		for _, field := range fs.Nested.Fields {
+			if match(field) || schemaHas(field, match) {
+				return true
+			}
+		}
+	}
+	if fs.Array != nil && fs.Array.Items != nil && schemaHas(*fs.Array.Items, match) {
+		return true
+	}
+	if fs.Map != nil && fs.Map.Values != nil && schemaHas(*fs.Map.Values, match) {
+		return true
+	}
+	return false
+}
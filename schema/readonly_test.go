@@ -0,0 +1,102 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Member struct {
+	ID     string `json:"id"     schema:"readOnly"`
+	Name   string `json:"name"   schema:"required"`
+	Secret string `json:"secret" schema:"writeOnly"`
+}
+
+type Team struct {
+	ID      string            `json:"id"      schema:"readOnly"`
+	Name    string            `json:"name"    schema:"required"`
+	Token   string            `json:"token"   schema:"writeOnly"`
+	Members []Member          `json:"members"`
+	ByRole  map[string]Member `json:"byRole"`
+	Created string            `json:"created" schema:"readOnly,default=never"`
+}
+
+func TestReadOnly_RejectedByDefault(t *testing.T) {
+	data := []byte(`{"id":"t1","name":"core","members":[{"name":"ann"},{"id":"m2","name":"bob"}],"byRole":{"lead":{"ID":"m3","name":"cy"}}}`)
+	ve := mustValidationErrors(t, schema.ValidateJSON[Team](data))
+	if len(ve) != 3 {
+		t.Fatalf("expected 3 errors, got %d: %v", len(ve), ve)
+	}
+	assertHasField(t, ve, "id")
+	assertHasField(t, ve, "members[1].id")
+	assertHasField(t, ve, "byRole.lead.id")
+	if ve[0].Message != "field is read-only" {
+		t.Errorf("unexpected message: %q", ve[0].Message)
+	}
+}
+
+func TestReadOnly_AbsentIsFine(t *testing.T) {
+	team, err := schema.ParseJSON[Team]([]byte(`{"name":"core","members":[{"name":"ann"}]}`))
+	assertNoError(t, err)
+	if team.Created != "never" {
+		t.Errorf("expected default on readOnly field, got %q", team.Created)
+	}
+}
+
+func TestReadOnly_Drop(t *testing.T) {
+	data := []byte(`{"id":"t1","name":"core","created":"yesterday","members":[{"id":"m1","name":"ann"}]}`)
+	team, err := schema.ParseJSON[Team](data, schema.WithReadOnly(schema.DropReadOnly))
+	assertNoError(t, err)
+	if team.ID != "" || team.Members[0].ID != "" {
+		t.Errorf("expected readOnly values to be dropped: %+v", team)
+	}
+	if team.Created != "never" {
+		t.Errorf("expected default after drop, got %q", team.Created)
+	}
+	if team.Name != "core" || team.Members[0].Name != "ann" {
+		t.Errorf("expected other fields to be decoded: %+v", team)
+	}
+}
+
+func TestReadOnly_Allow(t *testing.T) {
+	team, err := schema.ParseJSON[Team]([]byte(`{"id":"t1","name":"core"}`), schema.WithReadOnly(schema.AllowReadOnly))
+	assertNoError(t, err)
+	if team.ID != "t1" {
+		t.Errorf("expected readOnly value to be kept, got %q", team.ID)
+	}
+}
+
+func TestReadOnly_SyntaxErrorUnchanged(t *testing.T) {
+	ve := mustValidationErrors(t, schema.ValidateJSON[Team]([]byte(`{"id":"t1",`)))
+	if len(ve) != 1 || ve[0].Message == "field is read-only" {
+		t.Errorf("expected a syntax error, got %v", ve)
+	}
+}
+
+func TestMarshalJSON_OmitsWriteOnly(t *testing.T) {
+	team := Team{
+		ID:      "t1",
+		Name:    "core",
+		Token:   "s3cret",
+		Members: []Member{{ID: "m1", Name: "ann", Secret: "x"}},
+		ByRole:  map[string]Member{"lead": {Name: "bob", Secret: "y"}},
+	}
+	data, err := schema.MarshalJSON(team)
+	assertNoError(t, err)
+	want := `{"id":"t1","name":"core","members":[{"id":"m1","name":"ann"}],"byRole":{"lead":{"id":"","name":"bob"}},"created":""}`
+	if string(data) != want {
+		t.Errorf("unexpected JSON:\n got %s\nwant %s", data, want)
+	}
+
+	// Pointers and types without writeOnly fields marshal as usual.
+	data, err = schema.MarshalJSON(&Member{Name: "ann"})
+	assertNoError(t, err)
+	if string(data) != `{"id":"","name":"ann"}` {
+		t.Errorf("unexpected JSON for pointer: %s", data)
+	}
+	data, err = schema.MarshalJSON([]int{1, 2})
+	assertNoError(t, err)
+	if string(data) != `[1,2]` {
+		t.Errorf("unexpected JSON for slice: %s", data)
+	}
+}
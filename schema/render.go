@@ -0,0 +1,168 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// RenderOptions configures [ValidationErrors.Render].
+type RenderOptions struct {
+	Color   bool // highlight with ANSI escape sequences, for terminals
+	Context int  // lines of source shown before and after the value
+}
+
+// hints suggest a fix for each keyword.
+var hints = map[string]string{
+	"required":             "add the field",
+	"dependentRequired":    "add the field, or remove the one that requires it",
+	"type":                 "change the value to the expected type",
+	"additionalProperties": "remove the field, or check the spelling of its name",
+	"readOnly":             "remove the field: it can't be set",
+	"duplicateKeys":        "remove the repeated keys, so that the object has one value for the field",
+	"enum":                 "use one of the allowed values",
+	"const":                "use the expected value",
+	"minLength":            "use a longer string",
+	"maxLength":            "use a shorter string",
+	"pattern":              "change the value to match the pattern",
+	"format":               "check the format of the value",
+	"minimum":              "use a larger number",
+	"exclusiveMinimum":     "use a larger number",
+	"maximum":              "use a smaller number",
+	"exclusiveMaximum":     "use a smaller number",
+	"multipleOf":           "round the number to a multiple of the step",
+	"minItems":             "add items to the array",
+	"maxItems":             "remove items from the array",
+	"uniqueItems":          "remove the duplicate items",
+	"minProperties":        "add properties to the object",
+	"maxProperties":        "remove properties from the object",
+	"after":                "use a later time",
+	"before":               "use an earlier time",
+	"minAge":               "use an earlier time",
+	"maxAge":               "use a more recent time",
+	"not":                  "change the value so that it no longer matches the excluded schema",
+	"anyOf":                "change the value to match one of the alternatives",
+	"oneOf":                "change the value to match exactly one of the alternatives",
+}
+
+// ANSI escape sequences used when RenderOptions.Color is set.
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[1;31m"
+	ansiBlue  = "\x1b[1;34m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// Render formats the errors for a terminal or a CI log, given src, the JSON
+// input they were found in. Each error is printed with the lines of src
+// around its value, a caret under the value, the keyword that failed and a
+// hint on how to fix it:
+//
+//	error: field "port": must be <= 65535 (got 70000)
+//	 --> config.json:3:11
+//	  |
+//	3 |   "port": 70000
+//	  |           ^^^^^ maximum
+//	  = hint: use a smaller number
+//
+// Errors without a position, like those of [Validate], are printed without
+// a snippet. Errors are separated by blank lines.
+func (ve ValidationErrors) Render(src []byte, opts RenderOptions) string {
+	r := renderer{src: src, lines: bytes.Split(src, []byte{'\n'}), opts: opts}
+	var b strings.Builder
+	for i, e := range ve {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		r.render(&b, e)
+	}
+	return b.String()
+}
+
+// renderer renders ValidationErrors against their source.
+type renderer struct {
+	src   []byte
+	lines [][]byte
+	opts  RenderOptions
+}
+
+// paint wraps s in the escape sequence style if colors are enabled.
+func (r *renderer) paint(style, s string) string {
+	if !r.opts.Color || s == "" {
+		return s
+	}
+	return style + s + ansiReset
+}
+
+func (r *renderer) render(b *strings.Builder, e ValidationError) {
+	msg := e.Message
+	if e.Field != "" {
+		msg = fmt.Sprintf("field %q: %s", e.Field, e.Message)
+	}
+	fmt.Fprintf(b, "%s %s\n", r.paint(ansiRed, "error:"), r.paint(ansiBold, msg))
+
+	hint := hints[e.Keyword]
+	if e.Line < 1 || e.Line > len(r.lines) {
+		if e.File != "" {
+			fmt.Fprintf(b, " %s %s\n", r.paint(ansiBlue, "-->"), e.File)
+		}
+		if hint != "" {
+			fmt.Fprintf(b, " %s %s\n", r.paint(ansiBlue, "="), r.paint(ansiCyan, "hint: "+hint))
+		}
+		return
+	}
+
+	location := fmt.Sprintf("%d:%d", e.Line, e.Column)
+	if e.File != "" {
+		location = e.File + ":" + location
+	}
+	first := max(e.Line-r.opts.Context, 1)
+	last := min(e.Line+r.opts.Context, len(r.lines))
+	width := len(fmt.Sprint(last))
+	gutter := strings.Repeat(" ", width)
+
+	fmt.Fprintf(b, "%s%s %s\n", gutter, r.paint(ansiBlue, "-->"), location)
+	fmt.Fprintf(b, "%s %s\n", gutter, r.paint(ansiBlue, "|"))
+	for n := first; n <= last; n++ {
+		line := bytes.TrimSuffix(r.lines[n-1], []byte{'\r'})
+		fmt.Fprintf(b, "%s %s\n", r.paint(ansiBlue, fmt.Sprintf("%*d |", width, n)), strings.TrimRight(string(line), " \t"))
+		if n == e.Line {
+			pad, carets := r.caret(line, e)
+			label := e.Keyword
+			if label != "" {
+				label = " " + label
+			}
+			fmt.Fprintf(b, "%s %s %s%s\n", gutter, r.paint(ansiBlue, "|"), pad, r.paint(ansiRed, carets+label))
+		}
+	}
+	if hint != "" {
+		fmt.Fprintf(b, "%s %s %s\n", gutter, r.paint(ansiBlue, "="), r.paint(ansiCyan, "hint: "+hint))
+	}
+}
+
+// caret returns the padding that aligns the caret with the value of e on
+// line, and the carets underlining the part of the value on that line.
+func (r *renderer) caret(line []byte, e ValidationError) (string, string) {
+	col := min(e.Column-1, len(line))
+	var pad strings.Builder
+	for _, c := range string(line[:col]) {
+		// Keep tabs, so that the caret lines up whatever their width.
+		if c == '\t' {
+			pad.WriteByte('\t')
+		} else {
+			pad.WriteByte(' ')
+		}
+	}
+
+	end := e.Offset + 1
+	p := &jsonParser{data: r.src, pos: e.Offset}
+	if e.Offset < len(r.src) && p.skip() == nil {
+		end = p.pos
+	}
+	// A value spanning several lines is underlined up to the end of the
+	// first one.
+	n := utf8.RuneCount(line[col:min(col+end-e.Offset, len(line))])
+	return pad.String(), strings.Repeat("^", max(n, 1))
+}
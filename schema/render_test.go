@@ -0,0 +1,95 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+func TestRender(t *testing.T) {
+	src := []byte("{\n  \"name\": \"ab\",\n  \"listeners\": [{\"host\": \"h\", \"port\": 70000}]\n}")
+	_, err := schema.ParseJSON[Deployment](src)
+	got := mustValidationErrors(t, err).Render(src, schema.RenderOptions{})
+	want := `error: field "name": must be at least 3 characters long (got 2)
+ --> 2:11
+  |
+2 |   "name": "ab",
+  |           ^^^^ minLength
+  = hint: use a longer string
+
+error: field "listeners[0].port": must be <= 65535 (got 70000)
+ --> 3:39
+  |
+3 |   "listeners": [{"host": "h", "port": 70000}]
+  |                                       ^^^^^ maximum
+  = hint: use a smaller number
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRender_Context(t *testing.T) {
+	src := []byte(deployment)
+	_, err := schema.ParseJSON[Deployment](src)
+	ve := mustValidationErrors(t, err)
+	// A missing field underlines its object, up to the end of the line.
+	got := ve[:1].Render(src, schema.RenderOptions{Context: 1})
+	want := `error: field "name": must be at least 3 characters long (got 2)
+ --> 2:11
+  |
+1 | {
+2 |   "name": "ab",
+  |           ^^^^ minLength
+3 |   "listeners": [
+  = hint: use a longer string
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	for _, e := range ve {
+		if e.Field == "listeners[1].host" {
+			if got := (schema.ValidationErrors{e}).Render(src, schema.RenderOptions{}); !strings.Contains(got, "\n  |     ^^^^^^^^^^^^^^^ required\n") {
+				t.Errorf("expected the object to be underlined, got:\n%s", got)
+			}
+		}
+	}
+}
+
+func TestRender_Tabs(t *testing.T) {
+	src := []byte("{\n\t\"name\":\t\"ab\"\n}")
+	_, err := schema.ParseJSON[Deployment](src)
+	got := mustValidationErrors(t, err).Render(src, schema.RenderOptions{})
+	if !strings.Contains(got, "2 | \t\"name\":\t\"ab\"\n  | \t       \t^^^^ minLength\n") {
+		t.Errorf("expected the caret to keep the tabs, got:\n%s", got)
+	}
+}
+
+func TestRender_NoPosition(t *testing.T) {
+	got := mustValidationErrors(t, schema.Validate(Deployment{Name: "ab"})).Render(nil, schema.RenderOptions{})
+	want := "error: field \"name\": must be at least 3 characters long (got 2)\n = hint: use a longer string\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Syntax errors have no keyword, hence no hint.
+	src := []byte(`{"name": x}`)
+	_, err := schema.ParseJSON[Deployment](src)
+	got = mustValidationErrors(t, err).Render(src, schema.RenderOptions{})
+	if !strings.Contains(got, "1 | {\"name\": x}\n  |          ^\n") || strings.Contains(got, "hint") {
+		t.Errorf("unexpected rendering of a syntax error:\n%s", got)
+	}
+}
+
+func TestRender_Color(t *testing.T) {
+	src := []byte(`{"name":"ab"}`)
+	_, err := schema.ParseJSON[Deployment](src)
+	ve := mustValidationErrors(t, err)
+	if got := ve.Render(src, schema.RenderOptions{Color: true}); !strings.Contains(got, "\x1b[1;31m^^^^ minLength\x1b[0m") {
+		t.Errorf("expected colored carets, got %q", got)
+	}
+	if got := ve.Render(src, schema.RenderOptions{}); strings.Contains(got, "\x1b[") {
+		t.Errorf("expected no escape sequences, got %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// resolved is the schema of a type together with what the entry points
+// derive from it, so that it is worked out once per type.
+type resolved struct {
+	fs        FieldSchema
+	plan      *decodePlan // plan of the type itself, pointers included
+	decodes   bool        // whether ParseJSON hands the type to the decoder
+	readOnly  bool        // whether a field at any depth is readOnly
+	writeOnly bool        // whether a field at any depth is writeOnly
+}
+
+var (
+	// resolvedTypes caches the resolved types by resolvedKey. Types that
+	// fail to resolve are not cached, so that every call returns the
+	// error.
+	resolvedTypes sync.Map
+	// resolvedGen counts the registry changes, so that a type resolved
+	// before one is never returned after it.
+	resolvedGen atomic.Uint64
+)
+
+type resolvedKey struct {
+	t   reflect.Type
+	gen uint64
+}
+
+// resolve returns the resolved schema of t, from the cache if possible.
+func resolve(t reflect.Type) (*resolved, error) {
+	key := resolvedKey{t, resolvedGen.Load()}
+	if r, ok := resolvedTypes.Load(key); ok {
+		return r.(*resolved), nil
+	}
+	r, err := newResolved(t)
+	if err != nil {
+		return nil, err
+	}
+	resolvedTypes.Store(key, r)
+	return r, nil
+}
+
+// newResolved resolves the schema of t afresh.
+func newResolved(t reflect.Type) (*resolved, error) {
+	base := t
+	for base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+	fs, err := reflectTypeToSchema(base)
+	if err != nil {
+		return nil, err
+	}
+	return &resolved{
+		fs:        fs,
+		plan:      buildDecodePlan(t, fs),
+		decodes:   decodes(base, fs),
+		readOnly:  schemaHas(fs, isReadOnly),
+		writeOnly: schemaHas(fs, isWriteOnly),
+	}, nil
+}
+
+// forgetResolved empties the cache. RegisterEnum and RegisterJSONType call
+// it, as the schemas depend on their registries.
+func forgetResolved() {
+	resolvedGen.Add(1)
+	resolvedTypes.Clear()
+}
@@ -19,10 +19,22 @@ type NumberConstraints struct {
 	ExclusiveMin *float64
 	ExclusiveMax *float64
 	MultipleOf   *float64
-	Const        *float64 // exact value the field must equal
+	Enum         []float64 // allowed values
+	Const        *float64  // exact value the field must equal
 	Required     bool
 }
 
+// TimeConstraints holds temporal constraints applicable to time.Time values.
+// Bounds are RFC 3339 timestamps, dates (YYYY-MM-DD) or "now"; ages are
+// human-readable periods such as "18y", "6mo", "90d" or "1h30m".
+type TimeConstraints struct {
+	After    *string // value must be strictly later than this instant
+	Before   *string // value must be strictly earlier than this instant
+	MinAge   *string // value must be at least this long before now
+	MaxAge   *string // value must be at most this long before now
+	Required bool
+}
+
 // ArrayConstraints holds JSON Schema constraints applicable to slice/array values.
 type ArrayConstraints struct {
 	MinItems    *int
@@ -34,7 +46,8 @@ type ArrayConstraints struct {
 
 // BoolConstraints holds JSON Schema constraints applicable to boolean values.
 type BoolConstraints struct {
-	Const    *bool // exact value the field must equal
+	Enum     []bool // allowed values
+	Const    *bool  // exact value the field must equal
 	Required bool
 }
 
@@ -59,6 +72,14 @@ type FieldSchema struct {
 	// field is zero-valued after unmarshal).
 	Default *string
 
+	// Annotations: emitted in the JSON Schema output to document the field.
+	Title       string
+	Description string
+	Examples    []any // typed like the field (strings, numbers, JSON values)
+	Deprecated  bool
+	ReadOnly    bool
+	WriteOnly   bool
+
 	// Exactly one of the constraint sets below will be non-nil, matching Type.
 	String *StringConstraints
 	Number *NumberConstraints
@@ -66,11 +87,21 @@ type FieldSchema struct {
 	Bool   *BoolConstraints
 	Map    *MapConstraints
 
+	// Time holds temporal constraints for time.Time fields, which are
+	// otherwise described as strings with `format: date-time`.
+	Time *TimeConstraints
+
 	// Nested holds the ObjectSchema for embedded struct fields (Type == "object").
 	Nested *ObjectSchema
 
 	Required bool
 
+	// Enum and Const hold JSON values (as decoded by encoding/json) for
+	// fields without typed constraints: arrays, objects and `any`. They are
+	// compared against the JSON form of the field value.
+	Enum  []any
+	Const *any
+
 	// Advanced keywords
 	Nullable bool
 
@@ -90,4 +121,13 @@ type ObjectSchema struct {
 	// Advanced keywords
 	AdditionalProperties *bool               // nil means true (default)
 	DependentRequired    map[string][]string // property dependencies
+
+	// ExtraField is the Go name of the map field, tagged
+	// `schema:"additionalProperties"`, that collects the members matching
+	// no field when decoding. Empty if there is none.
+	ExtraField string
+
+	// promoted holds the JSON names of the fields promoted from embedded
+	// structs, which dependentRequired can name.
+	promoted []string
 }
@@ -1,10 +1,15 @@
 package schema
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 // parseObjectSchema builds an ObjectSchema by inspecting the reflect.Type of a
@@ -26,14 +31,17 @@ func parseObjectSchema(t reflect.Type) (*ObjectSchema, error) {
 		// The blank identifier field `_ any` is a sentinel for struct-level metadata
 		// (title, description). It is not a real field and must not be validated.
 		if f.Name == "_" {
-			opts := parseTagOptions(f.Tag.Get("schema"))
-			if v, ok := opts["title"]; ok {
+			opts, err := parseTagOptions(f.Tag.Get("schema"))
+			if err != nil {
+				return nil, fmt.Errorf("goschema: struct %s: %w", t, err)
+			}
+			if v, ok := opts.get("title"); ok {
 				obj.Title = v
 			}
-			if v, ok := opts["description"]; ok {
+			if v, ok := opts.get("description"); ok {
 				obj.Description = v
 			}
-			if v, ok := opts["additionalProperties"]; ok {
+			if v, ok := opts.get("additionalProperties"); ok {
 				b := v == "true"
 				obj.AdditionalProperties = &b
 			}
@@ -44,7 +52,7 @@ func parseObjectSchema(t reflect.Type) (*ObjectSchema, error) {
 						obj.DependentRequired = make(map[string][]string)
 					}
 					sourceField := strings.TrimPrefix(k, "dependentRequired:")
-					requiredFields := strings.Split(v, "|")
+					requiredFields := splitTagList(v)
 					obj.DependentRequired[sourceField] = requiredFields
 				}
 			}
@@ -56,6 +64,16 @@ func parseObjectSchema(t reflect.Type) (*ObjectSchema, error) {
 			continue
 		}
 
+		if ok, err := isExtraField(f); err != nil {
+			return nil, fmt.Errorf("goschema: field %q: %w", f.Name, err)
+		} else if ok {
+			if obj.ExtraField != "" {
+				return nil, fmt.Errorf("goschema: struct %s: fields %s and %s both collect additional properties", t, obj.ExtraField, f.Name)
+			}
+			obj.ExtraField = f.Name
+			continue
+		}
+
 		// Determine the JSON name.
 		jsonName := jsonFieldName(f)
 		if jsonName == "-" {
@@ -69,8 +87,12 @@ func parseObjectSchema(t reflect.Type) (*ObjectSchema, error) {
 		}
 
 		obj.Fields[jsonName] = fs
+		obj.promoted = append(obj.promoted, promotedNames(f, map[reflect.Type]bool{})...)
 	}
 
+	if obj.ExtraField != "" && obj.AdditionalProperties != nil && !*obj.AdditionalProperties {
+		return nil, fmt.Errorf("goschema: struct %s: field %s collects additional properties, which additionalProperties=false forbids", t, obj.ExtraField)
+	}
 	return obj, nil
 }
 
@@ -88,15 +110,96 @@ func jsonFieldName(f reflect.StructField) string {
 	return parts[0]
 }
 
+// promotes reports whether encoding/json promotes the fields of the struct
+// embedded as f. Like other fields, embedded fields are described by their
+// name in schemas; only dependentRequired sees the promoted fields.
+func promotes(f reflect.StructField) bool {
+	if !f.Anonymous || !f.IsExported() || strings.Split(f.Tag.Get("json"), ",")[0] != "" {
+		return false
+	}
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType && !isMarshaler(t)
+}
+
+// promotedNames returns the JSON names of the fields that encoding/json
+// promotes from the embedded field f, at any depth. seen holds the types
+// being walked.
+func promotedNames(f reflect.StructField, seen map[reflect.Type]bool) []string {
+	if !promotes(f) {
+		return nil
+	}
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	var names []string
+	for g := range structFields(t) {
+		if g.Name != "_" {
+			names = append(names, jsonFieldName(g))
+			names = append(names, promotedNames(g, seen)...)
+		}
+	}
+	return names
+}
+
+// hasJSONOption reports whether the json tag of f has option opt, like
+// omitempty.
+func hasJSONOption(f reflect.StructField, opt string) bool {
+	_, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return slices.Contains(strings.Split(opts, ","), opt)
+}
+
 // reflectTypeToSchema converts a reflect.Type to a base FieldSchema without
-// applying any tag-based constraints (other than recursion into structs/slices).
+// applying any field tag constraints (other than recursion into
+// structs/slices). Types implementing Enumer, SchemaProvider or
+// SchemaTagger contribute their own constraints. Optional and Nullable
+// have the schema of their value.
 func reflectTypeToSchema(t reflect.Type) (FieldSchema, error) {
 	for t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
+	if vt, null, ok := wrappedType(t); ok {
+		fs, err := reflectTypeToSchema(vt)
+		fs.Nullable = fs.Nullable || null
+		return fs, err
+	}
+
+	fs, err := goTypeSchema(t)
+	if err != nil {
+		return fs, err
+	}
+	if fs, err = enumSchema(t, fs); err != nil {
+		return fs, err
+	}
+	return providedSchema(t, fs)
+}
 
+// goTypeSchema derives the schema of a non-pointer type from its Go kind.
+func goTypeSchema(t reflect.Type) (FieldSchema, error) {
 	fs := FieldSchema{}
 
+	// Well-known struct types that encoding/json marshals as strings.
+	if t == timeType {
+		format := "date-time"
+		fs.Type = "string"
+		fs.String = &StringConstraints{Format: &format}
+		fs.Time = &TimeConstraints{}
+		return fs, nil
+	}
+
+	// Types with custom marshallers are described by their JSON form, not by
+	// their Go layout.
+	if ms, ok := marshalerSchema(t); ok {
+		return ms, nil
+	}
+
 	switch t.Kind() {
 	case reflect.String:
 		fs.Type = "string"
@@ -104,6 +207,9 @@ func reflectTypeToSchema(t reflect.Type) (FieldSchema, error) {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		fs.Type = "integer"
 		fs.Number = &NumberConstraints{}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		fs.Type = "integer"
+		fs.Number = unsignedConstraints(&NumberConstraints{})
 	case reflect.Float32, reflect.Float64:
 		fs.Type = "number"
 		fs.Number = &NumberConstraints{}
@@ -140,6 +246,16 @@ func reflectTypeToSchema(t reflect.Type) (FieldSchema, error) {
 	return fs, nil
 }
 
+// unsignedConstraints adds the implicit `minimum: 0` of unsigned integer
+// types; tags can only tighten it.
+func unsignedConstraints(nc *NumberConstraints) *NumberConstraints {
+	if nc.Minimum == nil || *nc.Minimum < 0 {
+		zero := 0.0
+		nc.Minimum = &zero
+	}
+	return nc
+}
+
 // buildFieldSchema maps a reflect.StructField to a FieldSchema by combining
 // the Go type information with the `schema` struct tag.
 func buildFieldSchema(f reflect.StructField, jsonName string) (FieldSchema, error) {
@@ -147,72 +263,161 @@ func buildFieldSchema(f reflect.StructField, jsonName string) (FieldSchema, erro
 	if err != nil {
 		return fs, err
 	}
+	fs, err = applyTag(fs, f.Type, f.Tag.Get("schema"))
+	if err != nil {
+		return fs, err
+	}
 	fs.JSONName = jsonName
+	return fs, nil
+}
+
+// applyTag tightens the base schema of a value of type t with the constraints
+// of a `schema` tag. Constraints only ever get stricter: the larger minimum
+// and the smaller maximum win, enums are intersected, a pattern is checked
+// on top of the base one, and a const, format or required=false that
+// contradicts the base schema is an error. Annotations in the tag replace
+// those of the base schema.
+func applyTag(fs FieldSchema, t reflect.Type, rawTag string) (FieldSchema, error) {
+	var err error
 
-	ft := f.Type
+	ft := t
 	isPtr := ft.Kind() == reflect.Ptr
 	if isPtr {
 		ft = ft.Elem()
 	}
+	if vt, _, ok := wrappedType(ft); ok {
+		ft = vt
+	}
 
-	rawTag := f.Tag.Get("schema")
-	opts := parseTagOptions(rawTag)
+	opts, err := parseTagOptions(rawTag)
+	if err != nil {
+		return fs, err
+	}
+
+	// time.Duration is marshalled as nanoseconds, but tags may use literals
+	// such as "30s" for readability.
+	if ft == durationType {
+		if err := convertDurationOptions(opts); err != nil {
+			return fs, err
+		}
+	}
 
 	// `required` can be set explicitly in the tag; pointers are optional by
 	// default unless required is set.
-	fs.Required = opts["required"] == "true" || (!isPtr && opts["required"] != "false" && rawTagHasKey(rawTag, "required"))
+	required, hasRequired := opts.get("required")
+	if fs.Required && required == "false" {
+		return fs, fmt.Errorf("required=false: type %s is required by its own schema", t)
+	}
+	fs.Required = fs.Required || required == "true" || (!isPtr && hasRequired && required != "false")
 
-	// Parse default value (raw string — applied during Parse[T]).
-	if v, ok := opts["default"]; ok {
+	// Parse default value (raw string — applied during Parse[T]). It is
+	// decoded once here so that defaults that don't fit the type are
+	// reported when the schema is built rather than ignored.
+	if v, ok := opts.get("default"); ok {
+		if _, err := decodeDefault(v, t); err != nil {
+			return fs, fmt.Errorf("default %s does not fit type %s: %w", v, t, err)
+		}
 		fs.Default = &v
 	}
 
 	// Override/augment base schema with tag constraints.
 	switch fs.Type {
 	case "string":
-		sc, err := buildStringConstraints(opts, fs.Required)
+		sc, err := buildStringConstraints(fs.String, opts, fs.Required)
 		if err != nil {
 			return fs, err
 		}
+		// Values must match both patterns, so the tag's is kept aside.
+		if base := fs.String; base != nil && base.Pattern != nil && *sc.Pattern != *base.Pattern {
+			fs.AllOf = append(slices.Clip(fs.AllOf), FieldSchema{Type: "string", String: &StringConstraints{Pattern: sc.Pattern}})
+			sc.Pattern = base.Pattern
+		}
 		fs.String = sc
+		if fs.Time != nil {
+			tc, err := buildTimeConstraints(fs.Time, opts, fs.Required)
+			if err != nil {
+				return fs, err
+			}
+			fs.Time = tc
+		}
 	case "integer", "number":
-		nc, err := buildNumberConstraints(opts, fs.Required)
+		nc, err := buildNumberConstraints(fs.Number, opts, fs.Required)
 		if err != nil {
 			return fs, err
 		}
 		fs.Number = nc
 	case "boolean":
-		bc, err := buildBoolConstraints(opts, fs.Required)
+		bc, err := buildBoolConstraints(fs.Bool, opts, fs.Required)
 		if err != nil {
 			return fs, err
 		}
 		fs.Bool = bc
 	case "array":
-		ac, err := buildArrayConstraints(opts, fs.Required, ft.Elem())
+		ac, err := buildArrayConstraints(fs.Array, opts, fs.Required)
 		if err != nil {
 			return fs, err
 		}
-		// Merge tag-based items rule if present; otherwise keep what reflect found.
-		if ac.Items != nil {
-			fs.Array.Items = ac.Items
-		}
-		// Copy array constraints
-		items := fs.Array.Items
 		fs.Array = ac
-		fs.Array.Items = items
 	case "object":
 		if fs.Map != nil {
-			mc, err := buildMapConstraints(opts, fs.Required)
+			mc, err := buildMapConstraints(fs.Map, opts, fs.Required)
 			if err != nil {
 				return fs, err
 			}
-			mc.Values = fs.Map.Values
 			fs.Map = mc
 		}
 	}
 
+	// Annotations
+	if v, ok := opts.get("title"); ok {
+		fs.Title = v
+	}
+	if v, ok := opts.get("description"); ok {
+		fs.Description = v
+	}
+	if v, ok := opts.list("examples"); ok {
+		examples, err := parseTypedList(v, fs.Type)
+		if err != nil {
+			return fs, fmt.Errorf("examples: %w", err)
+		}
+		fs.Examples = examples
+	}
+	fs.Deprecated = fs.Deprecated || opts.flag("deprecated")
+	fs.ReadOnly = fs.ReadOnly || opts.flag("readOnly")
+	fs.WriteOnly = fs.WriteOnly || opts.flag("writeOnly")
+
+	// Arrays, objects and untyped fields take enum/const as JSON literals.
+	switch fs.Type {
+	case "array", "object", "any":
+		if v, ok := opts.list("enum"); ok {
+			enum, err := parseJSONList(v)
+			if err != nil {
+				return fs, fmt.Errorf("enum: %w", err)
+			}
+			if fs.Enum != nil {
+				enum = slices.DeleteFunc(enum, func(e any) bool {
+					return !slices.ContainsFunc(fs.Enum, func(b any) bool { return reflect.DeepEqual(b, e) })
+				})
+				if len(enum) == 0 {
+					return fs, fmt.Errorf("enum %s shares no value with the enum %s of the type", v, jsonList(fs.Enum))
+				}
+			}
+			fs.Enum = enum
+		}
+		if v, ok := opts.get("const"); ok {
+			c, err := parseJSONLiteral(v)
+			if err != nil {
+				return fs, fmt.Errorf("const: %w", err)
+			}
+			if fs.Const != nil && !reflect.DeepEqual(*fs.Const, c) {
+				return fs, fmt.Errorf("const %s conflicts with the const %s of the type", v, jsonList([]any{*fs.Const}))
+			}
+			fs.Const = &c
+		}
+	}
+
 	// Advanced keywords
-	fs.Nullable = opts["nullable"] == "true"
+	fs.Nullable = fs.Nullable || opts.flag("nullable")
 
 	// Composition (simple one-rule-per-schema for now)
 	if v, ok := opts["not"]; ok {
@@ -227,7 +432,7 @@ func buildFieldSchema(f reflect.StructField, jsonName string) (FieldSchema, erro
 	// e.g. anyOf="minLength=5;pattern=^[0-9]+$"
 	parseComp := func(key string) ([]FieldSchema, error) {
 		if v, ok := opts[key]; ok {
-			schemas := strings.Split(v, ";")
+			schemas := splitTagValue(v, ';')
 			res := make([]FieldSchema, 0, len(schemas))
 			for _, s := range schemas {
 				sub, err := buildSubSchema(s)
@@ -241,215 +446,612 @@ func buildFieldSchema(f reflect.StructField, jsonName string) (FieldSchema, erro
 		return nil, nil
 	}
 
-	if fs.AnyOf, err = parseComp("anyOf"); err != nil {
+	if anyOf, err := parseComp("anyOf"); err != nil {
 		return fs, err
+	} else if anyOf != nil {
+		fs.AnyOf = anyOf
 	}
-	if fs.OneOf, err = parseComp("oneOf"); err != nil {
+	if oneOf, err := parseComp("oneOf"); err != nil {
 		return fs, err
+	} else if oneOf != nil {
+		fs.OneOf = oneOf
 	}
-	if fs.AllOf, err = parseComp("allOf"); err != nil {
+	// allOf only ever adds requirements, so it is appended to the base.
+	allOf, err := parseComp("allOf")
+	if err != nil {
 		return fs, err
 	}
+	if allOf != nil {
+		fs.AllOf = append(slices.Clip(fs.AllOf), allOf...)
+	}
 
 	return fs, nil
 }
 
 // buildSubSchema builds a FieldSchema from a subset of a tag string.
 func buildSubSchema(raw string) (*FieldSchema, error) {
-	opts := parseTagOptions(raw)
+	opts, err := parseTagOptions(raw)
+	if err != nil {
+		return nil, err
+	}
 	// We don't have reflect.StructField here, so we assume a generic "any" type
 	// and apply whatever constraints are in the options.
 	fs := &FieldSchema{Type: "any"}
-	var err error
 
 	// Try building all constraint types; the validator will use whichever is non-nil.
-	if fs.String, err = buildStringConstraints(opts, false); err != nil {
+	// enum/const values only apply to the types they parse as, so that
+	// `const=active` does not fail as a number.
+	if fs.String, err = buildStringConstraints(nil, opts, false); err != nil {
 		return nil, err
 	}
-	if fs.Number, err = buildNumberConstraints(opts, false); err != nil {
+	if fs.Number, err = buildNumberConstraints(nil, typedValueOptions(opts, parseFloat), false); err != nil {
 		return nil, err
 	}
-	if fs.Bool, err = buildBoolConstraints(opts, false); err != nil {
+	if fs.Bool, err = buildBoolConstraints(nil, typedValueOptions(opts, strconv.ParseBool), false); err != nil {
 		return nil, err
 	}
 	// We don't recurse into array/object here for simplicity in tags.
 	return fs, nil
 }
 
+// typedValueOptions returns opts without the enum/const values that parse
+// rejects.
+func typedValueOptions[T any](opts tagOptions, parse func(string) (T, error)) tagOptions {
+	res := make(tagOptions, len(opts))
+	for k, v := range opts {
+		if k == "enum" || k == "const" {
+			valid := true
+			for _, item := range splitTagList(v) {
+				if _, err := parse(item); err != nil {
+					valid = false
+					break
+				}
+			}
+			if !valid {
+				continue
+			}
+		}
+		res[k] = v
+	}
+	return res
+}
+
+// parseFloat parses a float64 tag value. NaN and infinities are rejected:
+// no JSON number can be compared with them.
+func parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return 0, fmt.Errorf("%s is not a finite number", s)
+	}
+	return f, err
+}
+
+// parseJSONLiteral decodes a tag value holding a JSON literal.
+func parseJSONLiteral(s string) (any, error) {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, fmt.Errorf("invalid JSON literal %s: %w", s, err)
+	}
+	return v, nil
+}
+
+// parseJSONList decodes a list of JSON literals.
+func parseJSONList(items []string) ([]any, error) {
+	res := make([]any, 0, len(items))
+	for _, p := range items {
+		v, err := parseJSONLiteral(p)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+// parseTypedValue converts a raw tag value to the JSON value it denotes for a
+// schema of the given type: strings are taken verbatim, numbers and booleans
+// are parsed, and anything else is a JSON literal.
+func parseTypedValue(raw, jsonType string) (any, error) {
+	switch jsonType {
+	case "string":
+		return raw, nil
+	case "integer":
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return n, nil
+		}
+		if n, err := strconv.ParseUint(raw, 10, 64); err == nil {
+			return n, nil
+		}
+		return parseFloat(raw)
+	case "number":
+		return parseFloat(raw)
+	case "boolean":
+		return strconv.ParseBool(raw)
+	}
+	return parseJSONLiteral(raw)
+}
+
+// parseTypedList converts a list of tag values with parseTypedValue.
+func parseTypedList(items []string, jsonType string) ([]any, error) {
+	res := make([]any, 0, len(items))
+	for _, p := range items {
+		v, err := parseTypedValue(p, jsonType)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+// tagOptions maps the keys of a `schema` tag to their raw values. Values keep
+// their quotes and escapes so that lists and sub-schemas can be split
+// further; get and list return them unquoted.
+type tagOptions map[string]string
+
+// get returns the unquoted value of key.
+func (o tagOptions) get(key string) (string, bool) {
+	raw, ok := o[key]
+	if !ok {
+		return "", false
+	}
+	return unquoteTag(raw), true
+}
+
+// pattern returns the value of key with its quotes resolved but its
+// backslashes kept, since they are regexp escapes: `^a\|b$` matches "a|b"
+// and `^C:\\Users$` a single backslash.
+func (o tagOptions) pattern(key string) (string, bool) {
+	raw, ok := o[key]
+	if !ok {
+		return "", false
+	}
+	parts, _ := scanTag(strings.TrimSpace(raw), 0, scanPattern)
+	return strings.Join(parts, ""), true
+}
+
+// flag reports whether the boolean option key is set.
+func (o tagOptions) flag(key string) bool {
+	v, _ := o.get(key)
+	return v == "true"
+}
+
+// list returns the unquoted items of the "|"-separated value of key.
+func (o tagOptions) list(key string) ([]string, bool) {
+	raw, ok := o[key]
+	if !ok {
+		return nil, false
+	}
+	return splitTagList(raw), true
+}
+
 // parseTagOptions parses a `schema` tag value into a key→value map.
 //
 // Tag grammar:
 //
-//	schema:"minLength=2,maxLength=50,pattern=^[a-z]+$,required"
+//	schema:"minLength=2,maxLength=50,pattern='^[a-z]{2,5}$',required"
 //
-// Boolean flags (like `required` and `uniqueItems`) are represented as
-// key→"true" when present.
-func parseTagOptions(tag string) map[string]string {
-	opts := make(map[string]string)
-	if tag == "" {
-		return opts
+// Options are separated by commas and hold either a boolean flag (like
+// `required` and `uniqueItems`, represented as key→"true") or a key=value
+// pair. Lists use "|" and composition sub-schemas use ";". A value that
+// contains any of these delimiters can be written in single quotes (doubled
+// inside them to stand for a literal quote) or have the delimiter escaped
+// with a backslash (`enum=a\|b|c`). Patterns keep their backslashes, which
+// the regexp reads: `pattern=^a\,b$` matches "a,b". Values starting with
+// '[', '{' or '"' are JSON literals and run to their closing bracket or
+// quote.
+func parseTagOptions(tag string) (tagOptions, error) {
+	opts := make(tagOptions)
+	parts, err := scanTag(tag, ',', scanRaw)
+	if err != nil {
+		return nil, fmt.Errorf("malformed tag %q: %w", tag, err)
 	}
-	// We can't just split on "," because pattern values may contain commas.
-	// Strategy: scan for "key=value" pairs; boolean flags have no "=".
-	// We split on commas that are NOT inside a value (values are everything
-	// after "=" until the next unescaped comma that is followed by a key).
-	parts := splitTagParts(tag)
 	for _, part := range parts {
 		part = strings.TrimSpace(part)
 		if part == "" {
 			continue
 		}
-		idx := strings.IndexByte(part, '=')
-		if idx < 0 {
-			// Boolean flag.
-			opts[part] = "true"
+		key, val, hasVal := strings.Cut(part, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("malformed tag %q: missing key before %q", tag, part)
+		}
+		if !isTagKey(key) {
+			return nil, fmt.Errorf("malformed tag %q: invalid key %q (quote or escape values containing ',')", tag, key)
+		}
+		if _, dup := opts[key]; dup {
+			return nil, fmt.Errorf("malformed tag %q: duplicate key %q", tag, key)
+		}
+		if hasVal {
+			opts[key] = strings.TrimSpace(val)
 		} else {
-			key := strings.TrimSpace(part[:idx])
-			val := strings.TrimSpace(part[idx+1:])
-			opts[key] = val
+			opts[key] = "true"
+		}
+	}
+	return opts, nil
+}
+
+// isTagKey reports whether s is a well-formed option key such as
+// `minLength`, `items:pattern` or `dependentRequired:billing_id`.
+func isTagKey(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("_-:.$@", r) {
+			return false
 		}
 	}
-	return opts
+	return true
 }
 
-// splitTagParts splits the raw tag string on commas, but only at positions
-// that are followed by a known keyword — allowing commas inside patterns.
-func splitTagParts(tag string) []string {
-	// Known multi-word keys — everything else is a key name without commas.
-	// We use a simple greedy split and rely on the fact that pattern values
-	// that contain commas are unusual; for robustness we handle it by
-	// treating the first segment that contains "=" specially.
+// tagSpecials are the bytes that a backslash escapes in a tag value other
+// than a pattern. Any other backslash is kept as is.
+const tagSpecials = `,|;='\`
+
+// scanMode says what scanTag resolves in the parts it returns.
+type scanMode int
+
+const (
+	scanRaw     scanMode = iota // nothing, so that parts can be split again
+	scanPattern                 // quotes, keeping the escapes of regexps
+	scanValue                   // quotes and escapes
+)
+
+// scanTag splits s on sep (0 for no splitting) following the tag grammar of
+// parseTagOptions, resolving quotes and escapes in the parts as set by mode.
+func scanTag(s string, sep byte, mode scanMode) ([]string, error) {
 	var parts []string
 	var buf strings.Builder
-	i := 0
-	for i < len(tag) {
-		ch := tag[i]
-		if ch == ',' {
+	prev := byte(0) // last non-space byte outside quotes and literals
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '\\':
+			if i+1 == len(s) {
+				return nil, fmt.Errorf("trailing backslash at offset %d", i)
+			}
+			next := s[i+1]
+			if mode != scanValue || strings.IndexByte(tagSpecials, next) < 0 {
+				buf.WriteByte(ch)
+			}
+			buf.WriteByte(next)
+			i++
+			prev = ch
+			continue
+		case ch == '\'' && isValueStart(prev):
+			end, err := scanQuoted(s, i)
+			if err != nil {
+				return nil, err
+			}
+			if mode != scanRaw {
+				buf.WriteString(strings.ReplaceAll(s[i+1:end], "''", "'"))
+			} else {
+				buf.WriteString(s[i : end+1])
+			}
+			i = end
+			prev = ch
+			continue
+		case (ch == '"' || ch == '[' || ch == '{') && isValueStart(prev):
+			end, err := scanJSONLiteral(s, i)
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteString(s[i:end])
+			i = end - 1
+			prev = s[i]
+			continue
+		case sep != 0 && ch == sep:
 			parts = append(parts, buf.String())
 			buf.Reset()
-		} else {
-			buf.WriteByte(ch)
+			prev = ch
+			continue
+		}
+		buf.WriteByte(ch)
+		if ch != ' ' {
+			prev = ch
 		}
-		i++
 	}
-	if buf.Len() > 0 {
-		parts = append(parts, buf.String())
+	return append(parts, buf.String()), nil
+}
+
+// isValueStart reports whether a value may start after the byte prev.
+func isValueStart(prev byte) bool {
+	switch prev {
+	case 0, '=', '|', ';', ',':
+		return true
 	}
-	return parts
+	return false
 }
 
-// rawTagHasKey returns true if the raw tag string contains the given key as a
-// standalone token (with or without a value).
-func rawTagHasKey(tag, key string) bool {
-	for _, part := range splitTagParts(tag) {
-		part = strings.TrimSpace(part)
-		if part == key || strings.HasPrefix(part, key+"=") {
-			return true
+// scanQuoted returns the index of the quote closing the single-quoted value
+// that starts at s[start]. Only a delimiter may follow it.
+func scanQuoted(s string, start int) (int, error) {
+	for i := start + 1; i < len(s); i++ {
+		if s[i] != '\'' {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '\'' {
+			i++
+			continue
+		}
+		rest := strings.TrimLeft(s[i+1:], " ")
+		if rest != "" && strings.IndexByte(",|;", rest[0]) < 0 {
+			return 0, fmt.Errorf("unexpected %q after quoted value at offset %d", rest[0], len(s)-len(rest))
 		}
+		return i, nil
 	}
-	return false
+	return 0, fmt.Errorf("unterminated quoted value starting at offset %d", start)
+}
+
+// scanJSONLiteral returns the index just past the JSON array, object or
+// string that starts at s[start].
+func scanJSONLiteral(s string, start int) (int, error) {
+	depth := 0
+	inString := false
+	for i := start; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case inString:
+			if ch == '\\' {
+				i++
+			} else if ch == '"' {
+				inString = false
+			}
+		case ch == '"':
+			inString = true
+		case ch == '[' || ch == '{':
+			depth++
+		case ch == ']' || ch == '}':
+			depth--
+		}
+		if depth == 0 && !inString {
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated JSON literal starting at offset %d", start)
+}
+
+// splitTagValue splits a raw option value on sep into trimmed items that
+// keep their quotes and escapes, e.g. the sub-schemas of `anyOf`.
+func splitTagValue(raw string, sep byte) []string {
+	// Values come from parseTagOptions, which already rejected malformed
+	// quoting.
+	parts, _ := scanTag(raw, sep, scanRaw)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// splitTagList splits a raw "|"-separated option value into unquoted items.
+func splitTagList(raw string) []string {
+	parts := splitTagValue(raw, '|')
+	for i, p := range parts {
+		parts[i] = unquoteTag(p)
+	}
+	return parts
+}
+
+// unquoteTag resolves the quotes and escapes of a raw option value.
+func unquoteTag(raw string) string {
+	parts, _ := scanTag(strings.TrimSpace(raw), 0, scanValue)
+	return strings.Join(parts, "")
 }
 
 // ---- per-type constraint builders ----
+//
+// Each builder starts from a copy of the base constraints derived from the
+// Go type (nil for sub-schemas) and tightens them with the tag options.
+
+// tightenMin returns the stricter (larger) of a lower bound and n.
+func tightenMin[N int | float64](cur *N, n N) *N {
+	if cur != nil && *cur > n {
+		return cur
+	}
+	return &n
+}
+
+// tightenMax returns the stricter (smaller) of an upper bound and n.
+func tightenMax[N int | float64](cur *N, n N) *N {
+	if cur != nil && *cur < n {
+		return cur
+	}
+	return &n
+}
+
+// intersectEnum narrows the allowed values of a base enum to those also
+// listed in the tag. Enums that share no value would reject everything, so
+// they are reported instead.
+func intersectEnum[E comparable](base, values []E) ([]E, error) {
+	if len(base) == 0 {
+		return values, nil
+	}
+	var res []E
+	for _, v := range values {
+		if slices.Contains(base, v) {
+			res = append(res, v)
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("enum %v shares no value with the enum %v of the type", values, base)
+	}
+	return res, nil
+}
 
-func buildStringConstraints(opts map[string]string, required bool) (*StringConstraints, error) {
-	sc := &StringConstraints{Required: required}
+func buildStringConstraints(base *StringConstraints, opts tagOptions, required bool) (*StringConstraints, error) {
+	sc := &StringConstraints{}
+	if base != nil {
+		*sc = *base
+	}
+	sc.Required = sc.Required || required
 
-	if v, ok := opts["minLength"]; ok {
+	if v, ok := opts.get("minLength"); ok {
 		n, err := strconv.Atoi(v)
 		if err != nil {
 			return nil, fmt.Errorf("minLength must be an integer: %w", err)
 		}
-		sc.MinLength = &n
+		sc.MinLength = tightenMin(sc.MinLength, n)
 	}
-	if v, ok := opts["maxLength"]; ok {
+	if v, ok := opts.get("maxLength"); ok {
 		n, err := strconv.Atoi(v)
 		if err != nil {
 			return nil, fmt.Errorf("maxLength must be an integer: %w", err)
 		}
-		sc.MaxLength = &n
+		sc.MaxLength = tightenMax(sc.MaxLength, n)
 	}
-	if v, ok := opts["pattern"]; ok {
+	if v, ok := opts.pattern("pattern"); ok {
+		if _, err := regexp.Compile(v); err != nil {
+			return nil, fmt.Errorf("pattern: invalid regular expression: %w", err)
+		}
 		sc.Pattern = &v
 	}
-	if v, ok := opts["format"]; ok {
+	if v, ok := opts.get("format"); ok {
+		if sc.Format != nil && *sc.Format != v {
+			return nil, fmt.Errorf("format %s conflicts with the format %s of the type", v, *sc.Format)
+		}
 		sc.Format = &v
 	}
-	if v, ok := opts["enum"]; ok {
-		sc.Enum = strings.Split(v, "|")
+	if v, ok := opts.list("enum"); ok {
+		enum, err := intersectEnum(sc.Enum, v)
+		if err != nil {
+			return nil, err
+		}
+		sc.Enum = enum
 	}
-	if v, ok := opts["const"]; ok {
+	if v, ok := opts.get("const"); ok {
+		if sc.Const != nil && *sc.Const != v {
+			return nil, fmt.Errorf("const %q conflicts with the const %q of the type", v, *sc.Const)
+		}
 		sc.Const = &v
 	}
 
 	return sc, nil
 }
 
-func buildNumberConstraints(opts map[string]string, required bool) (*NumberConstraints, error) {
-	nc := &NumberConstraints{Required: required}
+func buildNumberConstraints(base *NumberConstraints, opts tagOptions, required bool) (*NumberConstraints, error) {
+	nc := &NumberConstraints{}
+	if base != nil {
+		*nc = *base
+	}
+	nc.Required = nc.Required || required
 
 	parseF := func(key string) (*float64, error) {
-		v, ok := opts[key]
+		v, ok := opts.get(key)
 		if !ok {
 			return nil, nil
 		}
-		f, err := strconv.ParseFloat(v, 64)
+		f, err := parseFloat(v)
 		if err != nil {
 			return nil, fmt.Errorf("%s must be a number: %w", key, err)
 		}
 		return &f, nil
 	}
 
-	var err error
-	if nc.Minimum, err = parseF("minimum"); err != nil {
-		return nil, err
-	}
-	if nc.Maximum, err = parseF("maximum"); err != nil {
-		return nil, err
-	}
-	if nc.ExclusiveMin, err = parseF("exclusiveMinimum"); err != nil {
-		return nil, err
+	for _, b := range []struct {
+		key     string
+		dst     **float64
+		tighten func(*float64, float64) *float64
+	}{
+		{"minimum", &nc.Minimum, tightenMin[float64]},
+		{"maximum", &nc.Maximum, tightenMax[float64]},
+		{"exclusiveMinimum", &nc.ExclusiveMin, tightenMin[float64]},
+		{"exclusiveMaximum", &nc.ExclusiveMax, tightenMax[float64]},
+	} {
+		f, err := parseF(b.key)
+		if err != nil {
+			return nil, err
+		}
+		if f != nil {
+			*b.dst = b.tighten(*b.dst, *f)
+		}
 	}
-	if nc.ExclusiveMax, err = parseF("exclusiveMaximum"); err != nil {
+
+	if f, err := parseF("multipleOf"); err != nil {
 		return nil, err
+	} else if f != nil {
+		nc.MultipleOf = f
 	}
-	if nc.MultipleOf, err = parseF("multipleOf"); err != nil {
+	if f, err := parseF("const"); err != nil {
 		return nil, err
+	} else if f != nil {
+		if nc.Const != nil && *nc.Const != *f {
+			return nil, fmt.Errorf("const %g conflicts with the const %g of the type", *f, *nc.Const)
+		}
+		nc.Const = f
 	}
-	if nc.Const, err = parseF("const"); err != nil {
-		return nil, err
+	if v, ok := opts.list("enum"); ok {
+		var enum []float64
+		for _, item := range v {
+			f, err := parseFloat(item)
+			if err != nil {
+				return nil, fmt.Errorf("enum values must be numbers: %w", err)
+			}
+			enum = append(enum, f)
+		}
+		enum, err := intersectEnum(nc.Enum, enum)
+		if err != nil {
+			return nil, err
+		}
+		nc.Enum = enum
 	}
 
 	return nc, nil
 }
 
-func buildBoolConstraints(opts map[string]string, required bool) (*BoolConstraints, error) {
-	bc := &BoolConstraints{Required: required}
-	if v, ok := opts["const"]; ok {
-		b := v == "true"
+func buildBoolConstraints(base *BoolConstraints, opts tagOptions, required bool) (*BoolConstraints, error) {
+	bc := &BoolConstraints{}
+	if base != nil {
+		*bc = *base
+	}
+	bc.Required = bc.Required || required
+	if v, ok := opts.get("const"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("const must be a boolean: %w", err)
+		}
+		if bc.Const != nil && *bc.Const != b {
+			return nil, fmt.Errorf("const %t conflicts with the const %t of the type", b, *bc.Const)
+		}
 		bc.Const = &b
 	}
+	if v, ok := opts.list("enum"); ok {
+		var enum []bool
+		for _, item := range v {
+			b, err := strconv.ParseBool(item)
+			if err != nil {
+				return nil, fmt.Errorf("enum values must be booleans: %w", err)
+			}
+			enum = append(enum, b)
+		}
+		enum, err := intersectEnum(bc.Enum, enum)
+		if err != nil {
+			return nil, err
+		}
+		bc.Enum = enum
+	}
 	return bc, nil
 }
 
-func buildArrayConstraints(opts map[string]string, required bool, elemType reflect.Type) (*ArrayConstraints, error) {
-	ac := &ArrayConstraints{Required: required}
+func buildArrayConstraints(base *ArrayConstraints, opts tagOptions, required bool) (*ArrayConstraints, error) {
+	ac := &ArrayConstraints{}
+	if base != nil {
+		*ac = *base
+	}
+	ac.Required = ac.Required || required
 
-	if v, ok := opts["minItems"]; ok {
+	if v, ok := opts.get("minItems"); ok {
 		n, err := strconv.Atoi(v)
 		if err != nil {
 			return nil, fmt.Errorf("minItems must be an integer: %w", err)
 		}
-		ac.MinItems = &n
+		ac.MinItems = tightenMin(ac.MinItems, n)
 	}
-	if v, ok := opts["maxItems"]; ok {
+	if v, ok := opts.get("maxItems"); ok {
 		n, err := strconv.Atoi(v)
 		if err != nil {
 			return nil, fmt.Errorf("maxItems must be an integer: %w", err)
 		}
-		ac.MaxItems = &n
+		ac.MaxItems = tightenMax(ac.MaxItems, n)
 	}
-	if opts["uniqueItems"] == "true" {
+	if opts.flag("uniqueItems") {
 		ac.UniqueItems = true
 	}
 
@@ -469,28 +1071,33 @@ func buildArrayConstraints(opts map[string]string, required bool, elemType refle
 		if err != nil {
 			return nil, err
 		}
+		// A tag-based items rule replaces the element schema found by reflect.
 		ac.Items = sub
 	}
 
 	return ac, nil
 }
 
-func buildMapConstraints(opts map[string]string, required bool) (*MapConstraints, error) {
-	mc := &MapConstraints{Required: required}
+func buildMapConstraints(base *MapConstraints, opts tagOptions, required bool) (*MapConstraints, error) {
+	mc := &MapConstraints{}
+	if base != nil {
+		*mc = *base
+	}
+	mc.Required = mc.Required || required
 
-	if v, ok := opts["minProperties"]; ok {
+	if v, ok := opts.get("minProperties"); ok {
 		n, err := strconv.Atoi(v)
 		if err != nil {
 			return nil, fmt.Errorf("minProperties must be an integer: %w", err)
 		}
-		mc.MinProperties = &n
+		mc.MinProperties = tightenMin(mc.MinProperties, n)
 	}
-	if v, ok := opts["maxProperties"]; ok {
+	if v, ok := opts.get("maxProperties"); ok {
 		n, err := strconv.Atoi(v)
 		if err != nil {
 			return nil, fmt.Errorf("maxProperties must be an integer: %w", err)
 		}
-		mc.MaxProperties = &n
+		mc.MaxProperties = tightenMax(mc.MaxProperties, n)
 	}
 
 	return mc, nil
@@ -0,0 +1,132 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Quoted struct {
+	Code  string `json:"code"  schema:"pattern='^[a-z]{2,5}$'"`
+	Digit string `json:"digit" schema:"pattern=^\\d+$"`
+	Sep   string `json:"sep"   schema:"enum=a\\|b|c\\,d|'e;f'"`
+	Note  string `json:"note"  schema:"description='It''s a note, really'"`
+	Alt   string `json:"alt"   schema:"anyOf=pattern='^x;y$';minLength=10"`
+}
+
+func TestTags_QuotedValues(t *testing.T) {
+	assertNoError(t, schema.Validate(Quoted{Code: "abc", Digit: "42", Sep: "a|b", Alt: "x;y"}))
+	assertNoError(t, schema.Validate(Quoted{Code: "ab", Digit: "1", Sep: "c,d", Alt: "long enough string"}))
+	assertNoError(t, schema.Validate(Quoted{Code: "abcde", Digit: "1", Sep: "e;f", Alt: "x;y"}))
+
+	ve := mustValidationErrors(t, schema.Validate(Quoted{Code: "abcdef", Digit: "x", Sep: "a", Alt: "nope"}))
+	assertHasField(t, ve, "code")
+	assertHasField(t, ve, "digit")
+	assertHasField(t, ve, "sep")
+	assertHasField(t, ve, "alt")
+}
+
+func TestTags_QuotedValuesInJSONSchema(t *testing.T) {
+	js, err := schema.ToJSONSchema[Quoted]()
+	assertNoError(t, err)
+	props := js["properties"].(map[string]any)
+	if p := props["code"].(map[string]any)["pattern"]; p != "^[a-z]{2,5}$" {
+		t.Errorf("unexpected pattern: %v", p)
+	}
+	if p := props["digit"].(map[string]any)["pattern"]; p != `^\d+$` {
+		t.Errorf("unexpected pattern: %v", p)
+	}
+	enum, _ := props["sep"].(map[string]any)["enum"].([]string)
+	if len(enum) != 3 || enum[0] != "a|b" || enum[1] != "c,d" || enum[2] != "e;f" {
+		t.Errorf("unexpected enum: %#v", props["sep"].(map[string]any)["enum"])
+	}
+	if d := props["note"].(map[string]any)["description"]; d != "It's a note, really" {
+		t.Errorf("unexpected description: %v", d)
+	}
+}
+
+// RegexEscapes has backslashes that are regexp escapes, not tag escapes.
+type RegexEscapes struct {
+	Path  string `json:"path"  schema:"pattern=^C:\\\\Users$"`
+	Pipe  string `json:"pipe"  schema:"pattern=^a\\|b$"`
+	Comma string `json:"comma" schema:"pattern=^a\\,b$,minLength=3"`
+}
+
+func TestTags_PatternEscapes(t *testing.T) {
+	assertNoError(t, schema.Validate(RegexEscapes{Path: `C:\Users`, Pipe: "a|b", Comma: "a,b"}))
+
+	ve := mustValidationErrors(t, schema.Validate(RegexEscapes{Path: `C:Users`, Pipe: "a", Comma: "a\\,b"}))
+	assertHasField(t, ve, "path")
+	assertHasField(t, ve, "pipe")
+	assertHasField(t, ve, "comma")
+
+	js, err := schema.ToJSONSchema[RegexEscapes]()
+	assertNoError(t, err)
+	if p := js["properties"].(map[string]any)["path"].(map[string]any)["pattern"]; p != `^C:\\Users$` {
+		t.Errorf("unexpected pattern: %v", p)
+	}
+}
+
+type (
+	UnquotedComma struct {
+		V string `schema:"pattern=^[a-z]{2,5}$"`
+	}
+	UnterminatedTag struct {
+		V string `schema:"pattern='^[a-z]+$"`
+	}
+	TrailingSlash struct {
+		V string `schema:"pattern=abc\\"`
+	}
+	DuplicateKey struct {
+		V string `schema:"minLength=1,minLength=2"`
+	}
+	MissingKey struct {
+		V string `schema:"=5"`
+	}
+	AfterQuote struct {
+		V string `schema:"pattern='a'b"`
+	}
+	UnclosedJSON struct {
+		V []int `schema:"default=[1,2"`
+	}
+	BadPattern struct {
+		V string `schema:"pattern=^[a-z+$"`
+	}
+	NaNBound struct {
+		V float64 `schema:"minimum=NaN"`
+	}
+	InfBound struct {
+		V int `schema:"maximum=+Inf"`
+	}
+)
+
+func TestTags_MalformedErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		fn   func() (map[string]any, error)
+		want string
+	}{
+		{"unquoted comma", schema.ToJSONSchema[UnquotedComma], `invalid key "5}$"`},
+		{"unterminated quote", schema.ToJSONSchema[UnterminatedTag], "unterminated quoted value starting at offset 8"},
+		{"trailing backslash", schema.ToJSONSchema[TrailingSlash], "trailing backslash at offset 11"},
+		{"duplicate key", schema.ToJSONSchema[DuplicateKey], `duplicate key "minLength"`},
+		{"missing key", schema.ToJSONSchema[MissingKey], `missing key before "=5"`},
+		{"text after quote", schema.ToJSONSchema[AfterQuote], `unexpected 'b' after quoted value at offset 11`},
+		{"unclosed JSON", schema.ToJSONSchema[UnclosedJSON], "unterminated JSON literal starting at offset 8"},
+		{"invalid pattern", schema.ToJSONSchema[BadPattern], "pattern: invalid regular expression: error parsing regexp: missing closing ]"},
+		{"NaN bound", schema.ToJSONSchema[NaNBound], "NaN is not a finite number"},
+		{"infinite bound", schema.ToJSONSchema[InfBound], "+Inf is not a finite number"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.fn()
+			if err == nil {
+				t.Fatal("expected an error for a malformed tag")
+			}
+			if !strings.Contains(err.Error(), tc.want) || !strings.Contains(err.Error(), `field "V"`) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// durationKeys lists the tag keys whose values may be written as duration
+// literals ("30s", "1h30m") on time.Duration fields.
+var durationKeys = []string{"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf", "const", "default"}
+
+// convertDurationOptions rewrites duration literals in opts to their
+// nanosecond count, which is how encoding/json represents time.Duration.
+// Plain numbers are left untouched.
+func convertDurationOptions(opts tagOptions) error {
+	for _, key := range durationKeys {
+		v, ok := opts.get(key)
+		if !ok {
+			continue
+		}
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("%s must be a duration: %w", key, err)
+		}
+		opts[key] = strconv.FormatInt(int64(d), 10)
+	}
+	return nil
+}
+
+// period is a calendar-aware span of time. Years, months and days are
+// applied with time.AddDate so that "18y" means eighteen calendar years
+// rather than a fixed number of hours.
+type period struct {
+	years, months, days int
+	dur                 time.Duration
+}
+
+// periodUnits maps literal suffixes to their meaning; longer suffixes must be
+// tried before their prefixes ("mo" and "ms" before "m").
+var periodUnits = []struct {
+	suffix string
+	apply  func(p *period, n int64)
+}{
+	{"mo", func(p *period, n int64) { p.months += int(n) }},
+	{"ms", func(p *period, n int64) { p.dur += time.Duration(n) * time.Millisecond }},
+	{"us", func(p *period, n int64) { p.dur += time.Duration(n) * time.Microsecond }},
+	{"ns", func(p *period, n int64) { p.dur += time.Duration(n) }},
+	{"y", func(p *period, n int64) { p.years += int(n) }},
+	{"w", func(p *period, n int64) { p.days += 7 * int(n) }},
+	{"d", func(p *period, n int64) { p.days += int(n) }},
+	{"h", func(p *period, n int64) { p.dur += time.Duration(n) * time.Hour }},
+	{"m", func(p *period, n int64) { p.dur += time.Duration(n) * time.Minute }},
+	{"s", func(p *period, n int64) { p.dur += time.Duration(n) * time.Second }},
+}
+
+// parsePeriod parses a period literal made of one or more integer/unit pairs,
+// e.g. "120y", "1y6mo", "90d" or "1h30m".
+func parsePeriod(s string) (period, error) {
+	var p period
+	rest := strings.TrimSpace(s)
+	if rest == "" {
+		return p, fmt.Errorf("empty period")
+	}
+	for rest != "" {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 {
+			return p, fmt.Errorf("invalid period %q", s)
+		}
+		n, err := strconv.ParseInt(rest[:i], 10, 64)
+		if err != nil {
+			return p, fmt.Errorf("invalid period %q: %w", s, err)
+		}
+		rest = rest[i:]
+		matched := false
+		for _, u := range periodUnits {
+			if strings.HasPrefix(rest, u.suffix) {
+				u.apply(&p, n)
+				rest = rest[len(u.suffix):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return p, fmt.Errorf("invalid period %q: missing or unknown unit", s)
+		}
+	}
+	return p, nil
+}
+
+// before returns the instant that lies p before t.
+func (p period) before(t time.Time) time.Time {
+	return t.AddDate(-p.years, -p.months, -p.days).Add(-p.dur)
+}
+
+// parseTimeBound resolves a temporal bound ("now", an RFC 3339 timestamp or
+// a date) against the given clock reading.
+func parseTimeBound(s string, now time.Time) (time.Time, error) {
+	if s == "now" {
+		return now, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: expected RFC 3339 timestamp, date or \"now\"", s)
+}
+
+func buildTimeConstraints(base *TimeConstraints, opts tagOptions, required bool) (*TimeConstraints, error) {
+	tc := &TimeConstraints{}
+	if base != nil {
+		*tc = *base
+	}
+	tc.Required = tc.Required || required
+
+	for _, b := range []struct {
+		key string
+		dst **string
+	}{{"after", &tc.After}, {"before", &tc.Before}} {
+		if v, ok := opts.get(b.key); ok {
+			if _, err := parseTimeBound(v, time.Time{}); err != nil {
+				return nil, fmt.Errorf("%s: %w", b.key, err)
+			}
+			*b.dst = &v
+		}
+	}
+	for _, a := range []struct {
+		key string
+		dst **string
+	}{{"minAge", &tc.MinAge}, {"maxAge", &tc.MaxAge}} {
+		if v, ok := opts.get(a.key); ok {
+			if _, err := parsePeriod(v); err != nil {
+				return nil, fmt.Errorf("%s: %w", a.key, err)
+			}
+			*a.dst = &v
+		}
+	}
+
+	return tc, nil
+}
+
+func validateTime(v reflect.Value, c *TimeConstraints, path string, o *options) ValidationErrors {
+	var errs ValidationErrors
+	if c == nil || !v.CanInterface() {
+		return errs
+	}
+	t, ok := v.Interface().(time.Time)
+	if !ok || (t.IsZero() && !o.presence) {
+		// Presence is checked by the string constraints.
+		return errs
+	}
+
+	now := o.now()
+	value := t.Format(time.RFC3339Nano)
+
+	if c.After != nil {
+		if bound, err := parseTimeBound(*c.After, now); err == nil && !t.After(bound) {
+			errs = append(errs, ValidationError{
+				Field:   path,
+				Message: fmt.Sprintf("must be after %s", *c.After),
+				Keyword: "after",
+				Value:   value,
+			})
+		}
+	}
+	if c.Before != nil {
+		if bound, err := parseTimeBound(*c.Before, now); err == nil && !t.Before(bound) {
+			errs = append(errs, ValidationError{
+				Field:   path,
+				Message: fmt.Sprintf("must be before %s", *c.Before),
+				Keyword: "before",
+				Value:   value,
+			})
+		}
+	}
+	if c.MinAge != nil {
+		if p, err := parsePeriod(*c.MinAge); err == nil && t.After(p.before(now)) {
+			errs = append(errs, ValidationError{
+				Field:   path,
+				Message: fmt.Sprintf("must be at least %s ago", *c.MinAge),
+				Keyword: "minAge",
+				Value:   value,
+			})
+		}
+	}
+	if c.MaxAge != nil {
+		if p, err := parsePeriod(*c.MaxAge); err == nil && t.Before(p.before(now)) {
+			errs = append(errs, ValidationError{
+				Field:   path,
+				Message: fmt.Sprintf("must be at most %s ago", *c.MaxAge),
+				Keyword: "maxAge",
+				Value:   value,
+			})
+		}
+	}
+
+	return errs
+}
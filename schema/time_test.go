@@ -0,0 +1,111 @@
+package schema_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/twoojoo/goschema/schema"
+)
+
+type Person struct {
+	Birth   time.Time  `json:"birth"   schema:"required,after=1900-01-01,before=now,minAge=18y,maxAge=120y"`
+	Updated *time.Time `json:"updated"`
+}
+
+type Job struct {
+	Timeout time.Duration `json:"timeout" schema:"minimum=1s,maximum=1h,default=30s"`
+	Retry   time.Duration `json:"retry"   schema:"multipleOf=500ms"`
+}
+
+var fixedNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func fixedClock() time.Time { return fixedNow }
+
+func TestTime_ToJSONSchema(t *testing.T) {
+	js, err := schema.ToJSONSchema[Person]()
+	assertNoError(t, err)
+	props := js["properties"].(map[string]any)
+
+	birth := props["birth"].(map[string]any)
+	if birth["type"] != "string" || birth["format"] != "date-time" {
+		t.Errorf("expected string/date-time for time.Time, got %v", birth)
+	}
+	// JSON Schema has no temporal keywords.
+	for _, k := range []string{"after", "before", "minAge", "maxAge", "formatExclusiveMinimum", "formatExclusiveMaximum"} {
+		if _, ok := birth[k]; ok {
+			t.Errorf("unexpected keyword %s in %v", k, birth)
+		}
+	}
+	if _, ok := birth["properties"]; ok {
+		t.Error("time.Time internals must not be reflected as properties")
+	}
+
+	updated := props["updated"].(map[string]any)
+	if updated["format"] != "date-time" {
+		t.Errorf("expected *time.Time to be date-time, got %v", updated)
+	}
+}
+
+func TestTime_Required(t *testing.T) {
+	ve := mustValidationErrors(t, schema.Validate(Person{}, schema.WithClock(fixedClock)))
+	assertHasField(t, ve, "birth")
+}
+
+func TestTime_Constraints(t *testing.T) {
+	valid := Person{Birth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)}
+	assertNoError(t, schema.Validate(valid, schema.WithClock(fixedClock)))
+
+	tests := map[string]time.Time{
+		"before 1900": time.Date(1899, 12, 31, 0, 0, 0, 0, time.UTC),
+		"in future":   fixedNow.Add(time.Hour),
+		"too young":   fixedNow.AddDate(-17, 0, 0),
+		"too old":     fixedNow.AddDate(-121, 0, 0),
+	}
+	for name, birth := range tests {
+		t.Run(name, func(t *testing.T) {
+			ve := mustValidationErrors(t, schema.Validate(Person{Birth: birth}, schema.WithClock(fixedClock)))
+			assertHasField(t, ve, "birth")
+		})
+	}
+}
+
+func TestTime_ParseJSON(t *testing.T) {
+	p, err := schema.ParseJSON[Person]([]byte(`{"birth":"1990-05-17T08:00:00Z"}`), schema.WithClock(fixedClock))
+	assertNoError(t, err)
+	if p.Birth.Year() != 1990 {
+		t.Errorf("unexpected birth: %v", p.Birth)
+	}
+
+	_, err = schema.ParseJSON[Person]([]byte(`{"birth":"2020-05-17T08:00:00Z"}`), schema.WithClock(fixedClock))
+	ve := mustValidationErrors(t, err)
+	assertHasField(t, ve, "birth")
+}
+
+func TestDuration_Literals(t *testing.T) {
+	j, err := schema.ParseJSON[Job]([]byte(`{}`))
+	assertNoError(t, err)
+	if j.Timeout != 30*time.Second {
+		t.Errorf("expected default timeout 30s, got %v", j.Timeout)
+	}
+
+	ve := mustValidationErrors(t, schema.Validate(Job{Timeout: 2 * time.Hour, Retry: 700 * time.Millisecond}))
+	assertHasField(t, ve, "timeout")
+	assertHasField(t, ve, "retry")
+
+	js, err := schema.ToJSONSchema[Job]()
+	assertNoError(t, err)
+	timeout := js["properties"].(map[string]any)["timeout"].(map[string]any)
+	if timeout["type"] != "integer" || timeout["maximum"] != float64(time.Hour) {
+		t.Errorf("expected duration in nanoseconds, got %v", timeout)
+	}
+}
+
+type BadAge struct {
+	Birth time.Time `json:"birth" schema:"maxAge=forever"`
+}
+
+func TestTime_InvalidTag(t *testing.T) {
+	if _, err := schema.ToJSONSchema[BadAge](); err == nil {
+		t.Error("expected error for invalid maxAge period")
+	}
+}
@@ -1,11 +1,16 @@
 package schema
 
 import (
+	"encoding"
+	"encoding/json"
 	"fmt"
 	"math"
 	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 )
 
 // Pre-compiled format regexps — no external dependencies.
@@ -20,9 +25,25 @@ var formatPatterns = map[string]*regexp.Regexp{
 	"ipv6":      regexp.MustCompile(`(?i)^[0-9a-f:]+$`),
 }
 
+// patterns caches the compiled `pattern` regexps by source.
+var patterns sync.Map
+
+// compilePattern compiles the regexp of a `pattern`, once per source.
+func compilePattern(p string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(p); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(p)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(p, re)
+	return re, nil
+}
+
 // validateObject is the core recursive validation engine for structs.
 // path is the dot-separated JSON field path for error messages.
-func validateObject(v reflect.Value, schema *ObjectSchema, path string) ValidationErrors {
+func validateObject(v reflect.Value, schema *ObjectSchema, path string, o *options) ValidationErrors {
 	var errs ValidationErrors
 
 	// Dereference pointers.
@@ -38,21 +59,18 @@ func validateObject(v reflect.Value, schema *ObjectSchema, path string) Validati
 		return errs
 	}
 
+	// The fields of a Go struct carry no presence information.
+	if o.presence {
+		np := *o
+		np.presence = false
+		o = &np
+	}
+
 	// DependentRequired check
 	if schema.DependentRequired != nil {
-		for sourceField, dependents := range schema.DependentRequired {
-			if isPresent(v, schema, sourceField) {
-				for _, dep := range dependents {
-					if !isPresent(v, schema, dep) {
-						errs = append(errs, ValidationError{
-							Field:   path,
-							Message: fmt.Sprintf("field %q is required because %q is present", dep, sourceField),
-							Value:   nil,
-						})
-					}
-				}
-			}
-		}
+		errs = append(errs, checkDependentRequired(schema.DependentRequired, path, func(name string) bool {
+			return isPresent(v, schema, name)
+		})...)
 	}
 
 	t := v.Type()
@@ -74,7 +92,7 @@ func validateObject(v reflect.Value, schema *ObjectSchema, path string) Validati
 
 		fv := v.Field(i)
 		fp := fieldPath(path, jsonName)
-		errs = append(errs, validateField(fv, fs, fp)...)
+		errs = append(errs, validateField(fv, fs, fp, o)...)
 	}
 
 	return errs
@@ -90,9 +108,45 @@ func isPresent(v reflect.Value, schema *ObjectSchema, jsonName string) bool {
 			return !fv.IsZero()
 		}
 	}
+	for i := range t.NumField() {
+		if !promotes(t.Field(i)) {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if isPresent(fv, schema, jsonName) {
+			return true
+		}
+	}
 	return false
 }
 
+// checkDependentRequired reports the dependents of present fields that are
+// not present themselves.
+func checkDependentRequired(deps map[string][]string, path string, present func(jsonName string) bool) ValidationErrors {
+	var errs ValidationErrors
+	for sourceField, dependents := range deps {
+		if present(sourceField) {
+			for _, dep := range dependents {
+				if !present(dep) {
+					errs = append(errs, ValidationError{
+						Field:   path,
+						Message: fmt.Sprintf("field %q is required because %q is present", dep, sourceField),
+						Keyword: "dependentRequired",
+						Value:   nil,
+					})
+				}
+			}
+		}
+	}
+	return errs
+}
+
 // fieldPath builds a dot-separated path.
 func fieldPath(parent, child string) string {
 	if parent == "" {
@@ -110,6 +164,7 @@ func checkNilPointerRequired(schema *ObjectSchema, path string) ValidationErrors
 			errs = append(errs, ValidationError{
 				Field:   fieldPath(path, name),
 				Message: "field is required",
+				Keyword: "required",
 				Value:   nil,
 			})
 		}
@@ -118,9 +173,32 @@ func checkNilPointerRequired(schema *ObjectSchema, path string) ValidationErrors
 }
 
 // validateField validates a single field value against its FieldSchema.
-func validateField(v reflect.Value, fs FieldSchema, path string) ValidationErrors {
+func validateField(v reflect.Value, fs FieldSchema, path string, o *options) ValidationErrors {
 	var errs ValidationErrors
 
+	// Values held in interfaces (`any` fields, elements of []any, ...) are
+	// validated by their dynamic type.
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	// Optional and Nullable fields are checked on their value, if any. A
+	// value that was supplied is checked even when zero.
+	if value, set, null, ok := unwrap(v); ok {
+		switch {
+		case !set && fs.Required:
+			return ValidationErrors{{Field: path, Message: "field is required", Value: nil, Keyword: "required"}}
+		case !set || null:
+			return nil
+		}
+		if !o.presence {
+			np := *o
+			np.presence = true
+			o = &np
+		}
+		return validateField(value, fs, path, o)
+	}
+
 	// Handle pointer fields.
 	isPtr := v.Kind() == reflect.Ptr
 	if isPtr {
@@ -128,10 +206,12 @@ func validateField(v reflect.Value, fs FieldSchema, path string) ValidationError
 			if fs.Nullable {
 				return nil
 			}
-			if fs.Required {
+			// With Presence, a nil pointer is an explicit null.
+			if fs.Required && !o.presence {
 				errs = append(errs, ValidationError{
 					Field:   path,
 					Message: "field is required",
+					Keyword: "required",
 					Value:   nil,
 				})
 			}
@@ -140,14 +220,38 @@ func validateField(v reflect.Value, fs FieldSchema, path string) ValidationError
 		v = v.Elem()
 	}
 
-	// Composition Keywords (skipped if empty and not required)
-	if !(v.IsZero() && !fs.Required) {
+	// Types with custom marshallers are validated on their JSON form; the
+	// original value is kept for constraints on the Go type itself.
+	// With Presence, zero values are checked on their actual JSON form.
+	// Without a constraint, the marshaller isn't run at all. Like
+	// encoding/json, pointer-receiver marshallers are only used on
+	// addressable values; otherwise the JSON form isn't known, and nothing
+	// is checked.
+	goValue := v
+	if v.IsValid() && isMarshaler(v.Type()) {
+		if !hasChecks(fs) || marshaler(v) == nil {
+			return errs
+		}
+		jsonType := fs.Type
+		if o.presence {
+			jsonType = ""
+		}
+		if w, ok := wireValue(v, jsonType); ok {
+			v = w
+		}
+	}
+
+	// Composition Keywords (skipped if empty and not required, unless the
+	// value is known to be present)
+	absent := v.IsZero() && !fs.Required && !o.presence
+	if !absent {
 		if fs.Not != nil {
-			notErrs := validateField(v, *fs.Not, path)
+			notErrs := validateField(v, *fs.Not, path, o)
 			if len(notErrs) == 0 {
 				errs = append(errs, ValidationError{
 					Field:   path,
 					Message: "value must NOT match the 'not' schema",
+					Keyword: "not",
 					Value:   v.Interface(),
 				})
 			}
@@ -155,14 +259,14 @@ func validateField(v reflect.Value, fs FieldSchema, path string) ValidationError
 
 		if len(fs.AllOf) > 0 {
 			for _, sub := range fs.AllOf {
-				errs = append(errs, validateField(v, sub, path)...)
+				errs = append(errs, validateField(v, sub, path, o)...)
 			}
 		}
 
 		if len(fs.AnyOf) > 0 {
 			anyPassed := false
 			for _, sub := range fs.AnyOf {
-				subErrs := validateField(v, sub, path)
+				subErrs := validateField(v, sub, path, o)
 				if len(subErrs) == 0 {
 					anyPassed = true
 					break
@@ -172,6 +276,7 @@ func validateField(v reflect.Value, fs FieldSchema, path string) ValidationError
 				errs = append(errs, ValidationError{
 					Field:   path,
 					Message: "value must match at least one schema in 'anyOf'",
+					Keyword: "anyOf",
 					Value:   v.Interface(),
 				})
 			}
@@ -180,7 +285,7 @@ func validateField(v reflect.Value, fs FieldSchema, path string) ValidationError
 		if len(fs.OneOf) > 0 {
 			passCount := 0
 			for _, sub := range fs.OneOf {
-				subErrs := validateField(v, sub, path)
+				subErrs := validateField(v, sub, path, o)
 				if len(subErrs) == 0 {
 					passCount++
 				}
@@ -189,61 +294,121 @@ func validateField(v reflect.Value, fs FieldSchema, path string) ValidationError
 				errs = append(errs, ValidationError{
 					Field:   path,
 					Message: fmt.Sprintf("value must match exactly one schema in 'oneOf' (matched %d)", passCount),
+					Keyword: "oneOf",
 					Value:   v.Interface(),
 				})
 			}
 		}
 	}
 
+	if (fs.Const != nil || fs.Enum != nil) && !absent {
+		errs = append(errs, validateJSONEnum(v, fs, path)...)
+	}
+
 	switch fs.Type {
 	case "string":
-		errs = append(errs, validateString(v, fs.String, path)...)
+		errs = append(errs, validateTime(goValue, fs.Time, path, o)...)
+		errs = append(errs, validateString(v, fs.String, path, o)...)
 	case "integer", "number":
 		errs = append(errs, validateNumber(v, fs.Number, path)...)
 	case "boolean":
 		errs = append(errs, validateBool(v, fs.Bool, path)...)
 	case "array":
-		errs = append(errs, validateArray(v, fs.Array, path)...)
+		errs = append(errs, validateArray(v, fs.Array, path, o)...)
 	case "object":
 		if fs.Map != nil {
-			errs = append(errs, validateMap(v, fs.Map, path)...)
+			errs = append(errs, validateMap(v, fs.Map, path, o)...)
 		} else if fs.Nested != nil {
-			errs = append(errs, validateObject(v, fs.Nested, path)...)
+			errs = append(errs, validateObject(v, fs.Nested, path, o)...)
 		}
 	case "any":
 		// Dispatch based on value kind for sub-schemas/composition.
 		switch v.Kind() {
 		case reflect.String:
-			errs = append(errs, validateString(v, fs.String, path)...)
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+			errs = append(errs, validateString(v, fs.String, path, o)...)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
 			errs = append(errs, validateNumber(v, fs.Number, path)...)
 		case reflect.Bool:
 			errs = append(errs, validateBool(v, fs.Bool, path)...)
 		case reflect.Slice, reflect.Array:
-			errs = append(errs, validateArray(v, fs.Array, path)...)
+			errs = append(errs, validateArray(v, fs.Array, path, o)...)
 		case reflect.Map:
-			errs = append(errs, validateMap(v, fs.Map, path)...)
+			errs = append(errs, validateMap(v, fs.Map, path, o)...)
 		}
 	}
 
 	return errs
 }
 
-func validateString(v reflect.Value, c *StringConstraints, path string) ValidationErrors {
+// validateJSONEnum checks the JSON form of a value against the JSON-literal
+// enum/const of arrays, objects and untyped fields.
+func validateJSONEnum(v reflect.Value, fs FieldSchema, path string) ValidationErrors {
 	var errs ValidationErrors
-	if c == nil {
+	if !v.CanInterface() {
 		return errs
 	}
+	got, err := toJSONValue(v.Interface())
+	if err != nil {
+		return errs
+	}
+	if fs.Enum != nil && !slices.ContainsFunc(fs.Enum, func(e any) bool { return reflect.DeepEqual(e, got) }) {
+		errs = append(errs, ValidationError{
+			Field:   path,
+			Message: fmt.Sprintf("must be one of %s", jsonList(fs.Enum)),
+			Keyword: "enum",
+			Value:   got,
+		})
+	}
+	if fs.Const != nil && !reflect.DeepEqual(*fs.Const, got) {
+		errs = append(errs, ValidationError{
+			Field:   path,
+			Message: fmt.Sprintf("must equal %s", jsonList([]any{*fs.Const})),
+			Keyword: "const",
+			Value:   got,
+		})
+	}
+	return errs
+}
 
-	s := v.String()
+// toJSONValue round-trips x through encoding/json so that it can be compared
+// with values decoded from JSON literals.
+func toJSONValue(x any) (any, error) {
+	b, err := json.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// jsonList formats values as "|"-separated JSON literals, as in tags.
+func jsonList(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		b, _ := json.Marshal(v)
+		parts[i] = string(b)
+	}
+	return strings.Join(parts, "|")
+}
 
-	if c.Required && s == "" {
-		errs = append(errs, ValidationError{Field: path, Message: "field is required", Value: s})
+func validateString(v reflect.Value, c *StringConstraints, path string, o *options) ValidationErrors {
+	var errs ValidationErrors
+	if c == nil {
 		return errs
 	}
 
-	// For optional fields, skip presence-dependent constraints when empty.
-	if s == "" {
+	s := v.String()
+
+	if s == "" && !o.presence {
+		if c.Required {
+			errs = append(errs, ValidationError{Field: path, Message: "field is required", Value: s, Keyword: "required"})
+		}
+		// For optional fields, skip presence-dependent constraints when empty.
 		return errs
 	}
 
@@ -254,6 +419,7 @@ func validateString(v reflect.Value, c *StringConstraints, path string) Validati
 		errs = append(errs, ValidationError{
 			Field:   path,
 			Message: fmt.Sprintf("must be at least %d characters long (got %d)", *c.MinLength, runeLen),
+			Keyword: "minLength",
 			Value:   s,
 		})
 	}
@@ -261,21 +427,24 @@ func validateString(v reflect.Value, c *StringConstraints, path string) Validati
 		errs = append(errs, ValidationError{
 			Field:   path,
 			Message: fmt.Sprintf("must be at most %d characters long (got %d)", *c.MaxLength, runeLen),
+			Keyword: "maxLength",
 			Value:   s,
 		})
 	}
 	if c.Pattern != nil {
-		re, err := regexp.Compile(*c.Pattern)
+		re, err := compilePattern(*c.Pattern)
 		if err != nil {
 			errs = append(errs, ValidationError{
 				Field:   path,
 				Message: fmt.Sprintf("invalid pattern %q: %v", *c.Pattern, err),
+				Keyword: "pattern",
 				Value:   s,
 			})
 		} else if !re.MatchString(s) {
 			errs = append(errs, ValidationError{
 				Field:   path,
 				Message: fmt.Sprintf("must match pattern %q", *c.Pattern),
+				Keyword: "pattern",
 				Value:   s,
 			})
 		}
@@ -286,12 +455,13 @@ func validateString(v reflect.Value, c *StringConstraints, path string) Validati
 				errs = append(errs, ValidationError{
 					Field:   path,
 					Message: fmt.Sprintf("must be a valid %s", *c.Format),
+					Keyword: "format",
 					Value:   s,
 				})
 			}
 		}
 	}
-	if len(c.Enum) > 0 {
+	if c.Enum != nil {
 		found := false
 		for _, allowed := range c.Enum {
 			if s == allowed {
@@ -303,6 +473,7 @@ func validateString(v reflect.Value, c *StringConstraints, path string) Validati
 			errs = append(errs, ValidationError{
 				Field:   path,
 				Message: fmt.Sprintf("must be one of %v", c.Enum),
+				Keyword: "enum",
 				Value:   s,
 			})
 		}
@@ -311,6 +482,7 @@ func validateString(v reflect.Value, c *StringConstraints, path string) Validati
 		errs = append(errs, ValidationError{
 			Field:   path,
 			Message: fmt.Sprintf("must equal %q", *c.Const),
+			Keyword: "const",
 			Value:   s,
 		})
 	}
@@ -324,81 +496,218 @@ func validateNumber(v reflect.Value, c *NumberConstraints, path string) Validati
 		return errs
 	}
 
-	var n float64
-	switch v.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		n = float64(v.Int())
-	case reflect.Float32, reflect.Float64:
-		n = v.Float()
-	default:
+	n, ok := numberOf(v)
+	if !ok {
 		return errs
 	}
 
-	if c.Minimum != nil && n < *c.Minimum {
+	if c.Minimum != nil && n.cmp(*c.Minimum) < 0 {
 		errs = append(errs, ValidationError{
 			Field:   path,
-			Message: fmt.Sprintf("must be >= %g (got %g)", *c.Minimum, n),
-			Value:   n,
+			Message: fmt.Sprintf("must be >= %g (got %s)", *c.Minimum, n),
+			Keyword: "minimum",
+			Value:   n.value(),
 		})
 	}
-	if c.Maximum != nil && n > *c.Maximum {
+	if c.Maximum != nil && n.cmp(*c.Maximum) > 0 {
 		errs = append(errs, ValidationError{
 			Field:   path,
-			Message: fmt.Sprintf("must be <= %g (got %g)", *c.Maximum, n),
-			Value:   n,
+			Message: fmt.Sprintf("must be <= %g (got %s)", *c.Maximum, n),
+			Keyword: "maximum",
+			Value:   n.value(),
 		})
 	}
-	if c.ExclusiveMin != nil && n <= *c.ExclusiveMin {
+	if c.ExclusiveMin != nil && n.cmp(*c.ExclusiveMin) <= 0 {
 		errs = append(errs, ValidationError{
 			Field:   path,
-			Message: fmt.Sprintf("must be > %g (got %g)", *c.ExclusiveMin, n),
-			Value:   n,
+			Message: fmt.Sprintf("must be > %g (got %s)", *c.ExclusiveMin, n),
+			Keyword: "exclusiveMinimum",
+			Value:   n.value(),
 		})
 	}
-	if c.ExclusiveMax != nil && n >= *c.ExclusiveMax {
+	if c.ExclusiveMax != nil && n.cmp(*c.ExclusiveMax) >= 0 {
 		errs = append(errs, ValidationError{
 			Field:   path,
-			Message: fmt.Sprintf("must be < %g (got %g)", *c.ExclusiveMax, n),
-			Value:   n,
+			Message: fmt.Sprintf("must be < %g (got %s)", *c.ExclusiveMax, n),
+			Keyword: "exclusiveMaximum",
+			Value:   n.value(),
 		})
 	}
-	if c.MultipleOf != nil && *c.MultipleOf != 0 {
-		quotient := n / *c.MultipleOf
-		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
-			errs = append(errs, ValidationError{
-				Field:   path,
-				Message: fmt.Sprintf("must be a multiple of %g (got %g)", *c.MultipleOf, n),
-				Value:   n,
-			})
-		}
+	if c.MultipleOf != nil && *c.MultipleOf != 0 && !n.isMultipleOf(*c.MultipleOf) {
+		errs = append(errs, ValidationError{
+			Field:   path,
+			Message: fmt.Sprintf("must be a multiple of %g (got %s)", *c.MultipleOf, n),
+			Keyword: "multipleOf",
+			Value:   n.value(),
+		})
 	}
-	if c.Const != nil && n != *c.Const {
+	if c.Enum != nil && !slices.ContainsFunc(c.Enum, func(e float64) bool { return n.cmp(e) == 0 }) {
+		errs = append(errs, ValidationError{
+			Field:   path,
+			Message: fmt.Sprintf("must be one of %v", c.Enum),
+			Keyword: "enum",
+			Value:   n.value(),
+		})
+	}
+	if c.Const != nil && n.cmp(*c.Const) != 0 {
 		errs = append(errs, ValidationError{
 			Field:   path,
 			Message: fmt.Sprintf("must equal %g", *c.Const),
-			Value:   n,
+			Keyword: "const",
+			Value:   n.value(),
 		})
 	}
 
 	return errs
 }
 
+// numericValue is a number read from a reflect.Value. Integers keep their
+// exact 64-bit representation so that comparisons against float64 bounds
+// never lose precision (e.g. uint64 values above 2^53).
+type numericValue struct {
+	kind reflect.Kind // reflect.Int64, reflect.Uint64 or reflect.Float64
+	i    int64
+	u    uint64
+	f    float64
+}
+
+// numberOf extracts a numericValue from any integer or floating-point value.
+func numberOf(v reflect.Value) (numericValue, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return numericValue{kind: reflect.Int64, i: v.Int()}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return numericValue{kind: reflect.Uint64, u: v.Uint()}, true
+	case reflect.Float32, reflect.Float64:
+		return numericValue{kind: reflect.Float64, f: v.Float()}, true
+	}
+	return numericValue{}, false
+}
+
+// float returns the number as a float64, rounding large integers.
+func (n numericValue) float() float64 {
+	switch n.kind {
+	case reflect.Int64:
+		return float64(n.i)
+	case reflect.Uint64:
+		return float64(n.u)
+	}
+	return n.f
+}
+
+// value returns the number as the Value of a ValidationError, with
+// NumberValue.
+func (n numericValue) value() any {
+	switch n.kind {
+	case reflect.Int64:
+		return NumberValue(n.i)
+	case reflect.Uint64:
+		return NumberValue(n.u)
+	}
+	return n.f
+}
+
+func (n numericValue) String() string {
+	switch n.kind {
+	case reflect.Int64:
+		return strconv.FormatInt(n.i, 10)
+	case reflect.Uint64:
+		return strconv.FormatUint(n.u, 10)
+	}
+	return strconv.FormatFloat(n.f, 'g', -1, 64)
+}
+
+// cmp compares the number with f, returning -1, 0 or +1. Integers are
+// compared exactly instead of being converted to float64 first.
+func (n numericValue) cmp(f float64) int {
+	switch n.kind {
+	case reflect.Int64:
+		if n.i >= 0 {
+			return cmpUintFloat(uint64(n.i), f)
+		}
+		// Compare magnitudes; -(i+1)+1 avoids overflowing on math.MinInt64.
+		return -cmpUintFloat(uint64(-(n.i+1))+1, -f)
+	case reflect.Uint64:
+		return cmpUintFloat(n.u, f)
+	}
+	switch {
+	case n.f < f:
+		return -1
+	case n.f > f:
+		return 1
+	}
+	return 0
+}
+
+// cmpUintFloat compares u with f exactly.
+func cmpUintFloat(u uint64, f float64) int {
+	switch {
+	case math.IsNaN(f):
+		return 0
+	case f < 0:
+		return 1
+	case f >= 1<<64:
+		return -1
+	}
+	// f is in [0, 2^64): its integer part is exactly representable.
+	t := uint64(f)
+	switch {
+	case u < t:
+		return -1
+	case u > t:
+		return 1
+	case f > math.Trunc(f):
+		return -1
+	}
+	return 0
+}
+
+// isMultipleOf reports whether the number is a multiple of m. Integer values
+// with an integral factor are checked with exact modular arithmetic; anything
+// else uses a ratio-based float comparison that tolerates representation
+// error (0.3 is a multiple of 0.1).
+func (n numericValue) isMultipleOf(m float64) bool {
+	if n.kind != reflect.Float64 && m == math.Trunc(m) && math.Abs(m) < 1<<63 {
+		d := uint64(math.Abs(m))
+		switch n.kind {
+		case reflect.Int64:
+			if n.i < 0 {
+				return (uint64(-(n.i+1))+1)%d == 0
+			}
+			return uint64(n.i)%d == 0
+		case reflect.Uint64:
+			return n.u%d == 0
+		}
+	}
+	quotient := n.float() / m
+	return math.Abs(quotient-math.Round(quotient)) <= 1e-9
+}
+
 func validateBool(v reflect.Value, c *BoolConstraints, path string) ValidationErrors {
 	var errs ValidationErrors
 	if c == nil {
 		return errs
 	}
+	if c.Enum != nil && !slices.Contains(c.Enum, v.Bool()) {
+		errs = append(errs, ValidationError{
+			Field:   path,
+			Message: fmt.Sprintf("must be one of %v", c.Enum),
+			Keyword: "enum",
+			Value:   v.Bool(),
+		})
+	}
 	if c.Const != nil && v.Bool() != *c.Const {
 		errs = append(errs, ValidationError{
 			Field:   path,
 			Message: fmt.Sprintf("must equal %v", *c.Const),
+			Keyword: "const",
 			Value:   v.Bool(),
 		})
 	}
 	return errs
 }
 
-func validateArray(v reflect.Value, c *ArrayConstraints, path string) ValidationErrors {
+func validateArray(v reflect.Value, c *ArrayConstraints, path string, o *options) ValidationErrors {
 	var errs ValidationErrors
 	if c == nil {
 		return errs
@@ -410,10 +719,11 @@ func validateArray(v reflect.Value, c *ArrayConstraints, path string) Validation
 
 	n := v.Len()
 
-	if c.Required && n == 0 {
+	if c.Required && n == 0 && !o.presence {
 		errs = append(errs, ValidationError{
 			Field:   path,
 			Message: "field is required (empty slice)",
+			Keyword: "required",
 			Value:   n,
 		})
 		return errs
@@ -422,6 +732,7 @@ func validateArray(v reflect.Value, c *ArrayConstraints, path string) Validation
 		errs = append(errs, ValidationError{
 			Field:   path,
 			Message: fmt.Sprintf("must have at least %d items (got %d)", *c.MinItems, n),
+			Keyword: "minItems",
 			Value:   n,
 		})
 	}
@@ -429,6 +740,7 @@ func validateArray(v reflect.Value, c *ArrayConstraints, path string) Validation
 		errs = append(errs, ValidationError{
 			Field:   path,
 			Message: fmt.Sprintf("must have at most %d items (got %d)", *c.MaxItems, n),
+			Keyword: "maxItems",
 			Value:   n,
 		})
 	}
@@ -440,6 +752,7 @@ func validateArray(v reflect.Value, c *ArrayConstraints, path string) Validation
 				errs = append(errs, ValidationError{
 					Field:   path,
 					Message: fmt.Sprintf("items must be unique (duplicate: %v)", item),
+					Keyword: "uniqueItems",
 					Value:   item,
 				})
 				break
@@ -452,14 +765,14 @@ func validateArray(v reflect.Value, c *ArrayConstraints, path string) Validation
 	if c.Items != nil {
 		for i := 0; i < n; i++ {
 			itemPath := fmt.Sprintf("%s[%d]", path, i)
-			errs = append(errs, validateField(v.Index(i), *c.Items, itemPath)...)
+			errs = append(errs, validateField(v.Index(i), *c.Items, itemPath, o)...)
 		}
 	}
 
 	return errs
 }
 
-func validateMap(v reflect.Value, c *MapConstraints, path string) ValidationErrors {
+func validateMap(v reflect.Value, c *MapConstraints, path string, o *options) ValidationErrors {
 	var errs ValidationErrors
 	if c == nil {
 		return errs
@@ -471,10 +784,11 @@ func validateMap(v reflect.Value, c *MapConstraints, path string) ValidationErro
 
 	n := v.Len()
 
-	if c.Required && n == 0 {
+	if c.Required && n == 0 && !o.presence {
 		errs = append(errs, ValidationError{
 			Field:   path,
 			Message: "field is required (empty map)",
+			Keyword: "required",
 			Value:   n,
 		})
 		return errs
@@ -483,6 +797,7 @@ func validateMap(v reflect.Value, c *MapConstraints, path string) ValidationErro
 		errs = append(errs, ValidationError{
 			Field:   path,
 			Message: fmt.Sprintf("must have at least %d properties (got %d)", *c.MinProperties, n),
+			Keyword: "minProperties",
 			Value:   n,
 		})
 	}
@@ -490,6 +805,7 @@ func validateMap(v reflect.Value, c *MapConstraints, path string) ValidationErro
 		errs = append(errs, ValidationError{
 			Field:   path,
 			Message: fmt.Sprintf("must have at most %d properties (got %d)", *c.MaxProperties, n),
+			Keyword: "maxProperties",
 			Value:   n,
 		})
 	}
@@ -499,7 +815,7 @@ func validateMap(v reflect.Value, c *MapConstraints, path string) ValidationErro
 		for _, key := range v.MapKeys() {
 			val := v.MapIndex(key)
 			subPath := fieldPath(path, key.String())
-			errs = append(errs, validateField(val, *c.Values, subPath)...)
+			errs = append(errs, validateField(val, *c.Values, subPath, o)...)
 		}
 	}
 
@@ -508,6 +824,8 @@ func validateMap(v reflect.Value, c *MapConstraints, path string) ValidationErro
 
 // applyFieldDefaults is the recursive entry-point for default-value filling.
 func applyFieldDefaults(v reflect.Value, fs FieldSchema) {
+	// Nil pointers are left alone: they stand for a missing or null value,
+	// which a default must not replace.
 	for v.Kind() == reflect.Ptr {
 		if v.IsNil() {
 			return
@@ -515,24 +833,20 @@ func applyFieldDefaults(v reflect.Value, fs FieldSchema) {
 		v = v.Elem()
 	}
 
-	// Apply immediate default if value is zero.
+	// Apply immediate default if value is zero. Defaults are decoded afresh
+	// each time so that filled-in slices and maps are never shared between
+	// values.
 	if fs.Default != nil && v.CanSet() && v.IsZero() {
-		raw := *fs.Default
-		switch v.Kind() {
-		case reflect.String:
-			v.SetString(raw)
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
-				v.SetInt(n)
-			}
-		case reflect.Float32, reflect.Float64:
-			if f, err := strconv.ParseFloat(raw, 64); err == nil {
-				v.SetFloat(f)
-			}
-		case reflect.Bool:
-			v.SetBool(raw == "true")
+		if d, err := decodeDefault(*fs.Default, v.Type()); err == nil {
+			v.Set(d)
 		}
 	}
+	if value, set, null, ok := unwrap(v); ok {
+		if !set || null {
+			return
+		}
+		v = value
+	}
 
 	// Recurse based on type.
 	switch fs.Type {
@@ -553,6 +867,72 @@ func applyFieldDefaults(v reflect.Value, fs FieldSchema) {
 	}
 }
 
+// decodeDefault parses a raw `default=` value into a new value of type t.
+// Strings, numbers and booleans use the plain tag syntax (`default=en`,
+// `default=30`); slices, arrays, maps, structs and untyped fields take a
+// JSON literal (`default=["a","b"]`, `default={"retries":3}`). Types
+// implementing encoding.TextUnmarshaler parse the raw text themselves.
+func decodeDefault(raw string, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	if vt, _, ok := wrappedType(t); ok {
+		// Optional and Nullable defaults use the syntax of their value.
+		value, err := decodeDefault(raw, vt)
+		if err != nil {
+			return v, err
+		}
+		v.Field(0).Set(value)
+		v.FieldByName("Set").SetBool(true)
+		return v, nil
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+		return v, err
+	}
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return v, err
+		}
+		v.SetBool(b)
+	case reflect.Ptr:
+		elem, err := decodeDefault(raw, t.Elem())
+		if err != nil {
+			return v, err
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(elem)
+		v.Set(p)
+	default:
+		dec := json.NewDecoder(strings.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(v.Addr().Interface()); err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
+
 // applyObjectDefaults walks a settable struct value and sets zero-value fields to
 // their declared default (from `schema:"default=..."`) before validation runs.
 // It must be called with reflect.ValueOf(&v).Elem() so fields are settable.
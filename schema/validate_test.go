@@ -254,6 +254,26 @@ func TestParseJSON_TypeMismatch(t *testing.T) {
 	}
 }
 
+func TestParseJSON_AllProblems(t *testing.T) {
+	data := []byte(`{"id":"t1","name":"","members":[{"name":1},{"name":"bob","secret":false}],"byRole":{"lead":{"name":""}},"token":[]}`)
+	for name, parse := range map[string]func([]byte) error{
+		"ParseJSON": func(b []byte) error { _, err := schema.ParseJSON[Team](b); return err },
+		"Compiled":  func(b []byte) error { _, err := schema.MustCompile[Team]().ParseJSON(b); return err },
+	} {
+		ve := mustValidationErrors(t, parse(data))
+		// Supplied readOnly fields, type mismatches and constraint
+		// violations come back together.
+		for _, f := range []string{"id", "name", "members[0].name", "members[1].secret", "byRole.lead.name", "token"} {
+			if !ve.Has(f) {
+				t.Errorf("%s: expected an error for %s, got %v", name, f, ve)
+			}
+		}
+		if len(ve) != 6 {
+			t.Errorf("%s: expected 6 errors, got %v", name, ve)
+		}
+	}
+}
+
 func TestParseJSON_SyntaxError(t *testing.T) {
 	// 1. Truncated JSON
 	data := []byte(`{"name": "Alice", "email": "invalid-json"`) // missing closing brace